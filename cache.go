@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/pflag"
+	"io"
+)
+
+const cacheUsage = "usage: cache flush [--dry-run] | warm"
+
+// runCache implements the cache subcommand.
+func runCache(build func() *CLI, args []string) error {
+	if len(args) == 0 {
+		return errors.New(cacheUsage)
+	}
+	switch args[0] {
+	case "flush":
+		return cacheFlush(build, args[1:])
+	case "warm":
+		return cacheWarm(build, args[1:])
+	default:
+		return fmt.Errorf("unknown cache command %q, %s", args[0], cacheUsage)
+	}
+}
+
+// cacheFlush deletes the coin entries of the cache, the coins are read from the database until cached again.
+func cacheFlush(build func() *CLI, args []string) error {
+	flags := pflag.NewFlagSet("cache flush", pflag.ContinueOnError)
+	dryRun := dryRunFlag(flags)
+	output := outputFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w, %s", err, cacheUsage)
+	}
+
+	ctx := context.Background()
+	c := build().cache
+	res := struct {
+		Entries int64 `json:"entries"`
+		DryRun  bool  `json:"dryRun"`
+	}{DryRun: *dryRun}
+	var err error
+	if *dryRun {
+		res.Entries, err = c.Count(ctx)
+	} else {
+		res.Entries, err = c.Flush(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return printOutput(*output, res, func(w io.Writer) {
+		if res.DryRun {
+			_, _ = fmt.Fprintf(w, "would delete %d cache entries\n", res.Entries)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "deleted %d cache entries\n", res.Entries)
+	})
+}
+
+// cacheWarm preloads the cache as on startup, within the limits of cache.warmup.
+func cacheWarm(build func() *CLI, args []string) error {
+	flags := pflag.NewFlagSet("cache warm", pflag.ContinueOnError)
+	output := outputFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w, %s", err, cacheUsage)
+	}
+
+	stats, err := build().warmer.WarmUp(context.Background())
+	if err != nil {
+		return err
+	}
+	res := struct {
+		Loaded     int   `json:"loaded"`
+		Total      int   `json:"total"`
+		DurationMs int64 `json:"durationMs"`
+	}{stats.Loaded, stats.Total, stats.Duration.Milliseconds()}
+	return printOutput(*output, res, func(w io.Writer) {
+		_, _ = fmt.Fprintf(w, "loaded %d of %d coins in %s\n", stats.Loaded, stats.Total, stats.Duration)
+	})
+}
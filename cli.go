@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/internal/repository/cache"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/miles0wu/meme-coin-api/internal/web"
+	"github.com/spf13/pflag"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// CLI holds what the admin commands run on, the same services as the server.
+type CLI struct {
+	coins service.CoinService
+	// repo is waited for before exiting, it writes the cache in the background
+	repo *repository.CachedCoinRepository
+	// cache is the redis cache behind the circuit breaker of the repository
+	cache     *cache.RedisCoinCache
+	warmer    service.CacheWarmer
+	refresher *service.HotScoreRefresher
+}
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// outputFlag adds the --output flag of a command printing results.
+func outputFlag(flags *pflag.FlagSet) *string {
+	return flags.StringP("output", "o", outputTable, "output format, table or json")
+}
+
+// dryRunFlag adds the --dry-run flag of a destructive command.
+func dryRunFlag(flags *pflag.FlagSet) *bool {
+	return flags.Bool("dry-run", false, "report what would change without changing it")
+}
+
+// printOutput writes v as indented json, or as the table written by table.
+func printOutput(format string, v any, table func(w io.Writer)) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputTable:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// coinVo returns the coin as the api shows it, so that exports and api responses can be used alike.
+func coinVo(c domain.Coin) web.CoinVo {
+	reactions := make(map[string]int64, len(c.Reactions))
+	for r, n := range c.Reactions {
+		reactions[string(r)] = n
+	}
+	return web.CoinVo{
+		Id:              c.PublicId,
+		Name:            c.Name,
+		Description:     c.Description,
+		CreatedAt:       c.CreatedAt.Format(time.DateTime),
+		UpdatedAt:       c.UpdatedAt.Format(time.DateTime),
+		PopularityScore: c.PopularityScore,
+		HotScore:        c.HotScore,
+		Reactions:       reactions,
+	}
+}
+
+func printCoins(format string, coins []domain.Coin) error {
+	vos := make([]web.CoinVo, 0, len(coins))
+	for _, c := range coins {
+		vos = append(vos, coinVo(c))
+	}
+	return printOutput(format, vos, func(w io.Writer) {
+		_, _ = fmt.Fprintln(w, "ID\tNAME\tPOPULARITY\tHOT SCORE\tCREATED AT")
+		for _, c := range vos {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%s\n", c.Id, c.Name, c.PopularityScore, c.HotScore, c.CreatedAt)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/miles0wu/meme-coin-api/internal/web"
+	"github.com/spf13/pflag"
+	"io"
+	"os"
+)
+
+const coinsUsage = "usage: coins list [--sort hot|popular|recent] [--limit n] | get <id> | delete <id>... [--dry-run]" +
+	" | import [--file path] [--dry-run] | export [--file path] [--sort hot|popular|recent] [--limit n]"
+
+// coinResult is the outcome of a change to a coin, printed by delete and import.
+type coinResult struct {
+	Id     string `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// runCoins implements the coins subcommand, the changes go through the coin service
+// and are audited and invalidated in the cache like those of the api.
+func runCoins(build func() *CLI, args []string) error {
+	if len(args) == 0 {
+		return errors.New(coinsUsage)
+	}
+	switch args[0] {
+	case "list":
+		return coinsList(build, args[1:])
+	case "get":
+		return coinsGet(build, args[1:])
+	case "delete":
+		return coinsDelete(build, args[1:])
+	case "import":
+		return coinsImport(build, args[1:])
+	case "export":
+		return coinsExport(build, args[1:])
+	default:
+		return fmt.Errorf("unknown coins command %q, %s", args[0], coinsUsage)
+	}
+}
+
+func coinsList(build func() *CLI, args []string) error {
+	flags := pflag.NewFlagSet("coins list", pflag.ContinueOnError)
+	sort := flags.String("sort", string(domain.CoinSortHot), "order of the coins, hot, popular or recent")
+	limit := flags.Int("limit", 20, "number of coins listed")
+	output := outputFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w, %s", err, coinsUsage)
+	}
+
+	coins, err := build().coins.List(context.Background(), domain.CoinSort(*sort), *limit)
+	if err != nil {
+		return err
+	}
+	return printCoins(*output, coins)
+}
+
+func coinsGet(build func() *CLI, args []string) error {
+	flags := pflag.NewFlagSet("coins get", pflag.ContinueOnError)
+	output := outputFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w, %s", err, coinsUsage)
+	}
+	if flags.NArg() != 1 {
+		return errors.New(coinsUsage)
+	}
+
+	coin, err := build().coins.GetByPublicId(context.Background(), flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printCoins(*output, []domain.Coin{coin})
+}
+
+func coinsDelete(build func() *CLI, args []string) error {
+	flags := pflag.NewFlagSet("coins delete", pflag.ContinueOnError)
+	dryRun := dryRunFlag(flags)
+	output := outputFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w, %s", err, coinsUsage)
+	}
+	if flags.NArg() == 0 {
+		return errors.New(coinsUsage)
+	}
+
+	ctx := context.Background()
+	cli := build()
+	results := make([]coinResult, 0, flags.NArg())
+	var deleted []string
+	for _, id := range flags.Args() {
+		coin, err := cli.coins.GetByPublicId(ctx, id)
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			results = append(results, coinResult{Id: id, Status: "not found"})
+			continue
+		case err != nil:
+			return err
+		case *dryRun:
+			results = append(results, coinResult{Id: id, Name: coin.Name, Status: "would delete"})
+			continue
+		}
+		if err = cli.coins.DeleteByPublicId(ctx, id); err != nil {
+			return err
+		}
+		deleted = append(deleted, id)
+		results = append(results, coinResult{Id: id, Name: coin.Name, Status: "deleted"})
+	}
+	// the repository invalidates the cache in the background, and the reads above may still be caching
+	// the coins, wait for both and invalidate again before the process exits
+	cli.repo.Wait()
+	for _, id := range deleted {
+		if err := cli.cache.Del(ctx, id); err != nil {
+			_ = printResults(*output, results)
+			return fmt.Errorf("deleted coin %s still cached until it expires: %w", id, err)
+		}
+	}
+	return printResults(*output, results)
+}
+
+// coinsImport creates the coins of a json array, such as an export. Only their names and descriptions are kept,
+// the coins whose names already exist or that are invalid are skipped, any other failure stops the import.
+func coinsImport(build func() *CLI, args []string) error {
+	flags := pflag.NewFlagSet("coins import", pflag.ContinueOnError)
+	file := flags.String("file", "-", "json file to import, - for stdin")
+	dryRun := dryRunFlag(flags)
+	output := outputFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w, %s", err, coinsUsage)
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	var vos []web.CoinVo
+	if err := json.NewDecoder(in).Decode(&vos); err != nil {
+		return fmt.Errorf("decode %s: %w", *file, err)
+	}
+
+	ctx := context.Background()
+	var svc service.CoinService
+	if !*dryRun {
+		svc = build().coins
+	}
+	results := make([]coinResult, 0, len(vos))
+	for _, vo := range vos {
+		coin := domain.Coin{Name: vo.Name, Description: vo.Description}
+		// a dry run does not connect, names already taken are only found by the import
+		if *dryRun {
+			res := coinResult{Name: coin.Name, Status: "would create"}
+			if err := service.ValidateCreate(coin); err != nil {
+				res.Status, res.Error = "invalid", err.Error()
+			}
+			results = append(results, res)
+			continue
+		}
+		created, err := svc.Create(ctx, coin)
+		var verr *service.ValidationError
+		switch {
+		case errors.Is(err, service.ErrDuplicateName):
+			results = append(results, coinResult{Name: coin.Name, Status: "skipped", Error: "name already exists"})
+		case errors.As(err, &verr):
+			results = append(results, coinResult{Name: coin.Name, Status: "invalid", Error: err.Error()})
+		case err != nil:
+			// report what was created before the failure
+			_ = printResults(*output, results)
+			return err
+		default:
+			results = append(results, coinResult{Id: created.PublicId, Name: created.Name, Status: "created"})
+		}
+	}
+	return printResults(*output, results)
+}
+
+// coinsExport writes the first limit coins as a json array, which coins import reads back.
+func coinsExport(build func() *CLI, args []string) error {
+	flags := pflag.NewFlagSet("coins export", pflag.ContinueOnError)
+	file := flags.String("file", "-", "json file to write, - for stdout")
+	sort := flags.String("sort", string(domain.CoinSortRecent), "order of the coins, hot, popular or recent")
+	limit := flags.Int("limit", 10000, "maximum number of coins exported, a warning tells when more are left out")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w, %s", err, coinsUsage)
+	}
+
+	if *limit <= 0 {
+		return fmt.Errorf("invalid limit %d, %s", *limit, coinsUsage)
+	}
+
+	coins, err := listUpTo(build().coins, domain.CoinSort(*sort), *limit,
+		"warning: exported the first %d coins only, raise --limit to export them all\n")
+	if err != nil {
+		return err
+	}
+	vos := make([]web.CoinVo, 0, len(coins))
+	for _, c := range coins {
+		vos = append(vos, coinVo(c))
+	}
+	out := os.Stdout
+	if *file != "-" {
+		if out, err = os.Create(*file); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(vos); err != nil {
+		return err
+	}
+	if out != os.Stdout {
+		return out.Close()
+	}
+	return nil
+}
+
+// listUpTo returns the first limit coins in the order of sort, and prints warning, formatted with the limit,
+// to stderr when the limit left some coins out.
+func listUpTo(svc service.CoinService, sort domain.CoinSort, limit int, warning string) ([]domain.Coin, error) {
+	// one more coin tells whether the limit left some out
+	coins, err := svc.List(context.Background(), sort, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(coins) > limit {
+		coins = coins[:limit]
+		_, _ = fmt.Fprintf(os.Stderr, warning, limit)
+	}
+	return coins, nil
+}
+
+func printResults(format string, results []coinResult) error {
+	return printOutput(format, results, func(w io.Writer) {
+		_, _ = fmt.Fprintln(w, "ID\tNAME\tSTATUS\tERROR")
+		for _, r := range results {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Id, r.Name, r.Status, r.Error)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	svcmocks "github.com/miles0wu/meme-coin-api/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// captureOutput runs fn and returns what it wrote to stdout and stderr.
+func captureOutput(t *testing.T, fn func() error) (string, string) {
+	read := func(f **os.File) func() string {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		orig := *f
+		*f = w
+		done := make(chan string)
+		go func() {
+			bs, _ := io.ReadAll(r)
+			done <- string(bs)
+		}()
+		return func() string {
+			*f = orig
+			_ = w.Close()
+			return <-done
+		}
+	}
+	stdout, stderr := read(&os.Stdout), read(&os.Stderr)
+	require.NoError(t, fn())
+	return stdout(), stderr()
+}
+
+func TestCoinsLimit(t *testing.T) {
+	coins := []domain.Coin{{PublicId: "1", Name: "doge"}, {PublicId: "2", Name: "pepe"}, {PublicId: "3", Name: "shib"}}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) service.CoinService
+		args []string
+
+		wantStderr string
+	}{
+		{
+			name: "list stops at the limit silently",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				svc := svcmocks.NewMockCoinService(ctrl)
+				svc.EXPECT().List(gomock.Any(), domain.CoinSortHot, 2).Return(coins[:2], nil)
+				return svc
+			},
+			args: []string{"list", "--limit", "2"},
+		},
+		{
+			name: "export warns at the limit",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				svc := svcmocks.NewMockCoinService(ctrl)
+				svc.EXPECT().List(gomock.Any(), domain.CoinSortRecent, 3).Return(coins, nil)
+				return svc
+			},
+			args:       []string{"export", "--limit", "2", "--file", filepath.Join(t.TempDir(), "coins.json")},
+			wantStderr: "warning: exported the first 2 coins only, raise --limit to export them all\n",
+		},
+		{
+			name: "export of every coin",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				svc := svcmocks.NewMockCoinService(ctrl)
+				svc.EXPECT().List(gomock.Any(), domain.CoinSortRecent, 4).Return(coins, nil)
+				return svc
+			},
+			args: []string{"export", "--limit", "3", "--file", filepath.Join(t.TempDir(), "coins.json")},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cli := &CLI{coins: tc.mock(ctrl)}
+
+			stdout, stderr := captureOutput(t, func() error {
+				return runCoins(func() *CLI { return cli }, tc.args)
+			})
+			assert.Equal(t, tc.wantStderr, stderr)
+			assert.NotContains(t, stdout, "exported")
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/ioc"
+	"github.com/miles0wu/meme-coin-api/pkg/configx"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+const configUsage = "usage: config print [--redact]"
+
+// runConfig implements the config subcommand.
+func runConfig(cfg *ioc.Config, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+	flags := pflag.NewFlagSet("config print", pflag.ContinueOnError)
+	redact := flags.Bool("redact", false, "mask the secrets")
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w, %s", err, configUsage)
+	}
+
+	// the effective config, with the defaults and the environment overrides applied
+	node, err := configx.Encode(cfg, *redact)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err = enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// CoinAudit records a change of a coin, Before is nil on create and After on delete.
+type CoinAudit struct {
+	Id        int64
+	CoinId    int64
+	Action    AuditAction
+	Before    *Coin
+	After     *Coin
+	CreatedAt time.Time
+}
@@ -3,10 +3,46 @@ package domain
 import "time"
 
 type Coin struct {
+	// Id is internal, PublicId is the opaque id exposed by the api.
 	Id              int64
+	PublicId        string
 	Name            string
 	Description     string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	PopularityScore uint32
+	// HotScore is the popularity decayed over time, as of the last poke or refresh of the coin.
+	HotScore float64
+	// Reactions counts the reactions to the coin by type, the types without reactions are left out.
+	Reactions map[ReactionType]int64
 }
+
+// ReactionType is a reaction of a client to a coin, a client adds each type at most once per coin.
+type ReactionType string
+
+const (
+	// ReactionPoke is the reaction counted by the popularity and hot scores.
+	ReactionPoke   ReactionType = "poke"
+	ReactionRocket ReactionType = "rocket"
+	ReactionSkull  ReactionType = "skull"
+	ReactionFire   ReactionType = "fire"
+	ReactionHeart  ReactionType = "heart"
+)
+
+func (r ReactionType) Valid() bool {
+	switch r {
+	case ReactionPoke, ReactionRocket, ReactionSkull, ReactionFire, ReactionHeart:
+		return true
+	default:
+		return false
+	}
+}
+
+// CoinSort is the order of a list of coins.
+type CoinSort string
+
+const (
+	CoinSortHot     CoinSort = "hot"
+	CoinSortPopular CoinSort = "popular"
+	CoinSortRecent  CoinSort = "recent"
+)
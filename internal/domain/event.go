@@ -0,0 +1,16 @@
+package domain
+
+type CoinEventType string
+
+const (
+	CoinEventCreated CoinEventType = "coin.created"
+	CoinEventUpdated CoinEventType = "coin.updated"
+	CoinEventDeleted CoinEventType = "coin.deleted"
+)
+
+// CoinEvent notifies other services of a change, it is delivered through the outbox.
+type CoinEvent struct {
+	Type CoinEventType
+	// Coin is the state after the change, or before it for a deletion.
+	Coin Coin
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+type RevisionAction string
+
+const (
+	RevisionActionCreate RevisionAction = "create"
+	RevisionActionUpdate RevisionAction = "update"
+	RevisionActionPoke   RevisionAction = "poke"
+	RevisionActionUnpoke RevisionAction = "unpoke"
+	RevisionActionDelete RevisionAction = "delete"
+	// RevisionActionSnapshot is the state of a coin when its history began.
+	RevisionActionSnapshot RevisionAction = "snapshot"
+)
+
+// CoinRevision is a state of a coin in its history, a delete revision keeps the last state of the coin.
+type CoinRevision struct {
+	Revision int64
+	Action   RevisionAction
+	Coin     Coin
+	// ValidFrom is when the coin took this state, it holds until the next revision.
+	ValidFrom time.Time
+	// Changes are the fields changed since the previous revision.
+	Changes []FieldChange
+}
+
+type FieldChange struct {
+	Field string
+	From  any
+	To    any
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// ScoreInterval is the width of the buckets of a score history.
+type ScoreInterval string
+
+const (
+	ScoreIntervalMinute ScoreInterval = "minute"
+	ScoreIntervalHour   ScoreInterval = "hour"
+	ScoreIntervalDay    ScoreInterval = "day"
+)
+
+// Duration returns the width of the buckets, or 0 for an unknown interval.
+func (i ScoreInterval) Duration() time.Duration {
+	switch i {
+	case ScoreIntervalMinute:
+		return time.Minute
+	case ScoreIntervalHour:
+		return time.Hour
+	case ScoreIntervalDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// ScoreBucket counts the pokes of a coin from Start for the duration of its interval.
+type ScoreBucket struct {
+	Start time.Time
+	Pokes int64
+}
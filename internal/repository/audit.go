@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+)
+
+//go:generate mockgen -source=./audit.go -package=repomocks -destination=./mocks/audit.mock.go CoinAuditRepository
+type CoinAuditRepository interface {
+	Record(ctx context.Context, a domain.CoinAudit) error
+}
+
+type coinAuditRepository struct {
+	dao dao.CoinAuditDAO
+}
+
+func NewCoinAuditRepository(dao dao.CoinAuditDAO) CoinAuditRepository {
+	return &coinAuditRepository{
+		dao: dao,
+	}
+}
+
+func (repo *coinAuditRepository) Record(ctx context.Context, a domain.CoinAudit) error {
+	oldValue, err := marshalSnapshot(a.Before)
+	if err != nil {
+		return err
+	}
+	newValue, err := marshalSnapshot(a.After)
+	if err != nil {
+		return err
+	}
+	return repo.dao.Insert(ctx, dao.CoinAudit{
+		CoinId:   a.CoinId,
+		Action:   string(a.Action),
+		OldValue: oldValue,
+		NewValue: newValue,
+	})
+}
+
+// coinSnapshot is the json form of a coin stored in audits and events.
+type coinSnapshot struct {
+	Id              int64  `json:"id"`
+	PublicId        string `json:"publicId"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	CreatedAt       int64  `json:"createdAt"`
+	UpdatedAt       int64  `json:"updatedAt"`
+	PopularityScore uint32 `json:"popularityScore"`
+}
+
+func newCoinSnapshot(c domain.Coin) coinSnapshot {
+	return coinSnapshot{
+		Id:              c.Id,
+		PublicId:        c.PublicId,
+		Name:            c.Name,
+		Description:     c.Description,
+		CreatedAt:       c.CreatedAt.UnixMilli(),
+		UpdatedAt:       c.UpdatedAt.UnixMilli(),
+		PopularityScore: c.PopularityScore,
+	}
+}
+
+func marshalSnapshot(c *domain.Coin) (sql.NullString, error) {
+	if c == nil {
+		return sql.NullString{}, nil
+	}
+	bs, err := json.Marshal(newCoinSnapshot(*c))
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(bs), Valid: true}, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	daomocks "github.com/miles0wu/meme-coin-api/internal/repository/dao/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestCoinAuditRepository_Record(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) dao.CoinAuditDAO
+
+		audit domain.CoinAudit
+
+		wantErr error
+	}{
+		{
+			name: "record update",
+			mock: func(ctrl *gomock.Controller) dao.CoinAuditDAO {
+				auditDAO := daomocks.NewMockCoinAuditDAO(ctrl)
+				auditDAO.EXPECT().Insert(gomock.Any(), dao.CoinAudit{
+					CoinId: 1,
+					Action: "update",
+					OldValue: sql.NullString{
+						String: `{"id":1,"publicId":"01ARYZ6S410000000000000001","name":"doge","description":"old","createdAt":1700000000000,"updatedAt":1700000000000,"popularityScore":2}`,
+						Valid:  true,
+					},
+					NewValue: sql.NullString{
+						String: `{"id":1,"publicId":"01ARYZ6S410000000000000001","name":"doge","description":"new","createdAt":1700000000000,"updatedAt":1700000000000,"popularityScore":2}`,
+						Valid:  true,
+					},
+				}).Return(nil)
+				return auditDAO
+			},
+			audit: domain.CoinAudit{
+				CoinId: 1,
+				Action: domain.AuditActionUpdate,
+				Before: &domain.Coin{Id: 1, PublicId: "01ARYZ6S410000000000000001", Name: "doge", Description: "old", CreatedAt: now, UpdatedAt: now, PopularityScore: 2},
+				After:  &domain.Coin{Id: 1, PublicId: "01ARYZ6S410000000000000001", Name: "doge", Description: "new", CreatedAt: now, UpdatedAt: now, PopularityScore: 2},
+			},
+		},
+		{
+			name: "record delete without new value",
+			mock: func(ctrl *gomock.Controller) dao.CoinAuditDAO {
+				auditDAO := daomocks.NewMockCoinAuditDAO(ctrl)
+				auditDAO.EXPECT().Insert(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, a dao.CoinAudit) error {
+						assert.True(t, a.OldValue.Valid)
+						assert.False(t, a.NewValue.Valid)
+						return errors.New("mock db error")
+					})
+				return auditDAO
+			},
+			audit: domain.CoinAudit{
+				CoinId: 1,
+				Action: domain.AuditActionDelete,
+				Before: &domain.Coin{Id: 1, Name: "doge"},
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewCoinAuditRepository(tc.mock(ctrl))
+			err := repo.Record(context.Background(), tc.audit)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/pkg/breaker"
+	"github.com/miles0wu/meme-coin-api/pkg/health"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"sync"
+	"time"
+)
+
+var ErrCacheUnavailable = breaker.ErrOpen
+
+// maxPendingInvalidations bounds the ids remembered while the breaker is open,
+// anything beyond it is only protected by the cache expiration.
+const maxPendingInvalidations = 10000
+
+// BreakerCoinCache guards a CoinCache with a circuit breaker so that callers
+// fail fast with ErrCacheUnavailable instead of waiting on a dead Redis.
+//
+// Invalidations rejected while the breaker is open are remembered: those ids
+// are served as misses until they are rewritten, and are deleted from the
+// underlying cache once it is reachable again.
+type BreakerCoinCache struct {
+	cache   CoinCache
+	breaker *breaker.Breaker
+	l       logger.Logger
+
+	mu       sync.Mutex
+	pending  map[string]struct{}
+	flushing bool
+}
+
+func NewBreakerCoinCache(cache CoinCache, b *breaker.Breaker, l logger.Logger) *BreakerCoinCache {
+	return &BreakerCoinCache{
+		cache:   cache,
+		breaker: b,
+		l:       l,
+		pending: make(map[string]struct{}),
+	}
+}
+
+func (c *BreakerCoinCache) Set(ctx context.Context, coin domain.Coin) error {
+	if err := c.breaker.Allow(); err != nil {
+		return err
+	}
+	err := c.cache.Set(ctx, coin)
+	c.done(err)
+	if err == nil {
+		c.resolve(coin.PublicId)
+	}
+	return err
+}
+
+func (c *BreakerCoinCache) SetMulti(ctx context.Context, coins []domain.Coin) error {
+	if err := c.breaker.Allow(); err != nil {
+		return err
+	}
+	err := c.cache.SetMulti(ctx, coins)
+	c.done(err)
+	if err == nil {
+		for _, coin := range coins {
+			c.resolve(coin.PublicId)
+		}
+	}
+	return err
+}
+
+func (c *BreakerCoinCache) Get(ctx context.Context, publicId string) (domain.Coin, error) {
+	if c.isPending(publicId) {
+		return domain.Coin{}, ErrKeyNotExist
+	}
+	if err := c.breaker.Allow(); err != nil {
+		return domain.Coin{}, err
+	}
+	coin, err := c.cache.Get(ctx, publicId)
+	c.done(err)
+	return coin, err
+}
+
+func (c *BreakerCoinCache) Del(ctx context.Context, publicId string) error {
+	if err := c.breaker.Allow(); err != nil {
+		c.remember(publicId)
+		return err
+	}
+	err := c.cache.Del(ctx, publicId)
+	c.done(err)
+	if err != nil {
+		c.remember(publicId)
+		return err
+	}
+	c.resolve(publicId)
+	return nil
+}
+
+// Name implements health.Checker.
+func (c *BreakerCoinCache) Name() string {
+	return "redis"
+}
+
+// Check implements health.Checker. An open breaker degrades the service
+// rather than taking it down, reads are still served from the database.
+func (c *BreakerCoinCache) Check(ctx context.Context) health.Report {
+	state := c.breaker.State()
+	c.mu.Lock()
+	pending := len(c.pending)
+	c.mu.Unlock()
+
+	status := health.StatusUp
+	if state != breaker.StateClosed {
+		status = health.StatusDegraded
+	}
+	return health.Report{
+		Status: status,
+		Detail: map[string]any{
+			"breaker":              state.String(),
+			"consecutiveFailures":  c.breaker.Failures(),
+			"pendingInvalidations": pending,
+		},
+	}
+}
+
+func (c *BreakerCoinCache) done(err error) {
+	// a miss is a healthy answer from redis
+	success := err == nil || errors.Is(err, ErrKeyNotExist)
+	c.breaker.Done(success)
+	if success {
+		c.flushPending()
+	}
+}
+
+func (c *BreakerCoinCache) isPending(publicId string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.pending[publicId]
+	return ok
+}
+
+func (c *BreakerCoinCache) remember(publicId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) >= maxPendingInvalidations {
+		c.l.Warn("too many pending coin cache invalidations, dropping",
+			logger.String("coin_public_id", publicId))
+		return
+	}
+	c.pending[publicId] = struct{}{}
+}
+
+func (c *BreakerCoinCache) resolve(publicId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, publicId)
+}
+
+// flushPending replays the remembered invalidations in the background,
+// at most one flush runs at a time.
+func (c *BreakerCoinCache) flushPending() {
+	c.mu.Lock()
+	if c.flushing || len(c.pending) == 0 {
+		c.mu.Unlock()
+		return
+	}
+	c.flushing = true
+	ids := make([]string, 0, len(c.pending))
+	for id := range c.pending {
+		ids = append(ids, id)
+	}
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			c.flushing = false
+			c.mu.Unlock()
+		}()
+		for _, id := range ids {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			err := c.Del(ctx, id)
+			cancel()
+			if err != nil {
+				c.l.Warn("failed to replay pending coin cache invalidation",
+					logger.String("coin_public_id", id),
+					logger.Error(err))
+				return
+			}
+		}
+		c.l.Info("replayed pending coin cache invalidations",
+			logger.Int("count", len(ids)))
+	}()
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository/cache/redismocks"
+	"github.com/miles0wu/meme-coin-api/pkg/breaker"
+	"github.com/miles0wu/meme-coin-api/pkg/health"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestBreakerCoinCache_Get(t *testing.T) {
+	coin := domain.Coin{
+		Id:       1,
+		PublicId: publicId1,
+	}
+	keyFunc := func(publicId string) string {
+		return fmt.Sprintf("coin:%s", publicId)
+	}
+	connErr := errors.New("redis conn error")
+
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) redis.Cmdable
+
+		// calls is the number of Get issued before checking the result
+		calls int
+
+		wantErr    error
+		wantStatus health.Status
+	}{
+		{
+			name: "hit keeps breaker closed",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				bs, err := json.Marshal(coin)
+				assert.NoError(t, err)
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Get(gomock.Any(), keyFunc(publicId1)).
+					Return(redis.NewStringResult(string(bs), nil)).Times(3)
+				return cmd
+			},
+			calls:      3,
+			wantStatus: health.StatusUp,
+		},
+		{
+			name: "misses do not trip breaker",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Get(gomock.Any(), keyFunc(publicId1)).
+					Return(redis.NewStringResult("", redis.Nil)).Times(3)
+				return cmd
+			},
+			calls:      3,
+			wantErr:    redis.Nil,
+			wantStatus: health.StatusUp,
+		},
+		{
+			name: "errors below threshold reach redis",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Get(gomock.Any(), keyFunc(publicId1)).
+					Return(redis.NewStringResult("", connErr)).Times(2)
+				return cmd
+			},
+			calls:      2,
+			wantErr:    connErr,
+			wantStatus: health.StatusUp,
+		},
+		{
+			name: "open breaker bypasses redis",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				// the fourth call must never reach redis
+				cmd.EXPECT().Get(gomock.Any(), keyFunc(publicId1)).
+					Return(redis.NewStringResult("", connErr)).Times(3)
+				return cmd
+			},
+			calls:      4,
+			wantErr:    ErrCacheUnavailable,
+			wantStatus: health.StatusDegraded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			b := breaker.NewBreaker(3, time.Minute)
+			c := NewBreakerCoinCache(NewRedisCoinCache(tc.mock(ctrl), prometheus.NewRegistry()), b, logger.NewNopLogger())
+
+			var err error
+			for i := 0; i < tc.calls; i++ {
+				_, err = c.Get(context.Background(), publicId1)
+			}
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantStatus, c.Check(context.Background()).Status)
+		})
+	}
+}
+
+func TestBreakerCoinCache_Recovery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	b := breaker.NewBreaker(1, time.Minute, breaker.WithClock(func() time.Time {
+		return now
+	}))
+	cmd := redismocks.NewMockCmdable(ctrl)
+	c := NewBreakerCoinCache(NewRedisCoinCache(cmd, prometheus.NewRegistry()), b, logger.NewNopLogger())
+
+	// trip the breaker
+	cmd.EXPECT().Get(gomock.Any(), "coin:"+publicId1).
+		Return(redis.NewStringResult("", errors.New("redis conn error")))
+	_, err := c.Get(context.Background(), publicId1)
+	assert.Error(t, err)
+	assert.Equal(t, breaker.StateOpen, b.State())
+
+	// invalidations while open are remembered and the id is served as a miss
+	err = c.Del(context.Background(), publicId2)
+	assert.Equal(t, ErrCacheUnavailable, err)
+	_, err = c.Get(context.Background(), publicId2)
+	assert.Equal(t, ErrKeyNotExist, err)
+
+	// the half-open probe succeeds, closes the breaker and replays the invalidation
+	now = now.Add(time.Minute)
+	replayed := make(chan struct{})
+	cmd.EXPECT().Get(gomock.Any(), "coin:"+publicId1).
+		Return(redis.NewStringResult("", redis.Nil))
+	cmd.EXPECT().Del(gomock.Any(), "coin:"+publicId2).
+		DoAndReturn(func(ctx context.Context, keys ...string) *redis.IntCmd {
+			close(replayed)
+			return redis.NewIntResult(1, nil)
+		})
+	_, err = c.Get(context.Background(), publicId1)
+	assert.Equal(t, redis.Nil, err)
+	assert.Equal(t, breaker.StateClosed, b.State())
+
+	select {
+	case <-replayed:
+	case <-time.After(time.Second):
+		t.Fatal("pending invalidation was not replayed")
+	}
+	assert.Eventually(t, func() bool {
+		return !c.isPending(publicId2)
+	}, time.Second, 10*time.Millisecond)
+}
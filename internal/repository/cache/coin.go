@@ -3,9 +3,13 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,24 +18,56 @@ var ErrKeyNotExist = redis.Nil
 //go:generate mockgen -source=./coin.go -package=cachemocks -destination=./mocks/coin.mock.go CoinCache
 type CoinCache interface {
 	Set(ctx context.Context, c domain.Coin) error
-	Get(ctx context.Context, id int64) (domain.Coin, error)
-	Del(ctx context.Context, id int64) error
+	// Get and Del take the public id of the coin, the key of its cache entry.
+	Get(ctx context.Context, publicId string) (domain.Coin, error)
+	Del(ctx context.Context, publicId string) error
+	// SetMulti writes all coins in a single round trip.
+	SetMulti(ctx context.Context, coins []domain.Coin) error
 }
 
 type RedisCoinCache struct {
-	client     redis.Cmdable
-	expiration time.Duration
+	client redis.Cmdable
+	// expiration is the time.Duration of the entries, it may change at runtime
+	expiration atomic.Int64
+	// ops counts the operations by result, hit, miss or error for a get, ok or error otherwise.
+	ops *prometheus.CounterVec
 }
 
-func NewRedisCoinCache(client redis.Cmdable) CoinCache {
-	return &RedisCoinCache{
-		client:     client,
-		expiration: time.Minute * 15,
+func NewRedisCoinCache(client redis.Cmdable, reg prometheus.Registerer) *RedisCoinCache {
+	ops := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "meme_coin",
+		Subsystem: "cache",
+		Name:      "operations_total",
+		Help:      "Number of coin cache operations by result.",
+	}, []string{"operation", "result"})
+	reg.MustRegister(ops)
+	c := &RedisCoinCache{
+		client: client,
+		ops:    ops,
 	}
+	c.SetExpiration(time.Minute * 15)
+	return c
 }
 
-func (c *RedisCoinCache) key(id int64) string {
-	return fmt.Sprintf("coin:%d", id)
+// SetExpiration changes the expiration of the entries written from now on.
+func (c *RedisCoinCache) SetExpiration(d time.Duration) {
+	c.expiration.Store(int64(d))
+}
+
+// count counts an operation, as okResult when it did not fail.
+func (c *RedisCoinCache) count(op, okResult string, err error) {
+	result := okResult
+	switch {
+	case errors.Is(err, ErrKeyNotExist):
+		result = "miss"
+	case err != nil:
+		result = "error"
+	}
+	c.ops.WithLabelValues(op, result).Inc()
+}
+
+func (c *RedisCoinCache) key(publicId string) string {
+	return fmt.Sprintf("coin:%s", publicId)
 }
 
 func (c *RedisCoinCache) Set(ctx context.Context, coin domain.Coin) error {
@@ -39,22 +75,110 @@ func (c *RedisCoinCache) Set(ctx context.Context, coin domain.Coin) error {
 	if err != nil {
 		return err
 	}
-	return c.client.Set(ctx, c.key(coin.Id), bs, c.expiration).Err()
+	err = c.client.Set(ctx, c.key(coin.PublicId), bs, time.Duration(c.expiration.Load())).Err()
+	c.count("set", "ok", err)
+	return err
 }
 
-func (c *RedisCoinCache) Get(ctx context.Context, id int64) (domain.Coin, error) {
-	val, err := c.client.Get(ctx, c.key(id)).Bytes()
-	if err != nil {
-		return domain.Coin{}, err
+func (c *RedisCoinCache) Get(ctx context.Context, publicId string) (coin domain.Coin, err error) {
+	val, err := c.client.Get(ctx, c.key(publicId)).Bytes()
+	if err == nil {
+		err = json.Unmarshal(val, &coin)
 	}
-	var coin domain.Coin
-	err = json.Unmarshal(val, &coin)
+	c.count("get", "hit", err)
 	if err != nil {
 		return domain.Coin{}, err
 	}
 	return coin, nil
 }
 
-func (c *RedisCoinCache) Del(ctx context.Context, id int64) error {
-	return c.client.Del(ctx, c.key(id)).Err()
+func (c *RedisCoinCache) Del(ctx context.Context, publicId string) error {
+	err := c.client.Del(ctx, c.key(publicId)).Err()
+	c.count("del", "ok", err)
+	return err
+}
+
+func (c *RedisCoinCache) SetMulti(ctx context.Context, coins []domain.Coin) error {
+	if len(coins) == 0 {
+		return nil
+	}
+	pipe := c.client.Pipeline()
+	for _, coin := range coins {
+		bs, err := json.Marshal(coin)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, c.key(coin.PublicId), bs, time.Duration(c.expiration.Load()))
+	}
+	_, err := pipe.Exec(ctx)
+	c.count("set_multi", "ok", err)
+	return err
+}
+
+// scanCount is the number of keys a SCAN is hinted to return per round trip.
+const scanCount = 500
+
+// Count returns the number of coin entries in the cache.
+func (c *RedisCoinCache) Count(ctx context.Context) (int64, error) {
+	var n int64
+	err := c.scan(ctx, func(_ redis.Cmdable, keys []string) error {
+		n += int64(len(keys))
+		return nil
+	})
+	return n, err
+}
+
+// Flush deletes every coin entry and returns how many were deleted, the other keys of the database are kept.
+func (c *RedisCoinCache) Flush(ctx context.Context) (int64, error) {
+	var n int64
+	err := c.scan(ctx, func(node redis.Cmdable, keys []string) error {
+		// one DEL per key, the keys of a batch may hash to different cluster slots
+		pipe := node.Pipeline()
+		dels := make([]*redis.IntCmd, 0, len(keys))
+		for _, key := range keys {
+			dels = append(dels, pipe.Del(ctx, key))
+		}
+		_, err := pipe.Exec(ctx)
+		for _, del := range dels {
+			n += del.Val()
+		}
+		return err
+	})
+	c.count("flush", "ok", err)
+	return n, err
+}
+
+// scan calls fn with each batch of coin keys and the node holding them,
+// every master is scanned in cluster mode.
+func (c *RedisCoinCache) scan(ctx context.Context, fn func(node redis.Cmdable, keys []string) error) error {
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		var mu sync.Mutex
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return scanNode(ctx, master, func(node redis.Cmdable, keys []string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				return fn(node, keys)
+			})
+		})
+	}
+	return scanNode(ctx, c.client, fn)
+}
+
+func scanNode(ctx context.Context, node redis.Cmdable, fn func(node redis.Cmdable, keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := node.Scan(ctx, cursor, "coin:*", scanCount).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err = fn(node, keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
 }
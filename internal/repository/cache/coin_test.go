@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"github.com/miles0wu/meme-coin-api/internal/domain"
 	"github.com/miles0wu/meme-coin-api/internal/repository/cache/redismocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -14,13 +16,19 @@ import (
 	"time"
 )
 
+const (
+	publicId1 = "01ARYZ6S410000000000000001"
+	publicId2 = "01ARYZ6S410000000000000002"
+)
+
 func TestRedisCoinCache_Set(t *testing.T) {
 	coin := domain.Coin{
-		Id: 1,
+		Id:       1,
+		PublicId: publicId1,
 	}
 
-	keyFunc := func(id int64) string {
-		return fmt.Sprintf("coin:%d", id)
+	keyFunc := func(publicId string) string {
+		return fmt.Sprintf("coin:%s", publicId)
 	}
 	testCases := []struct {
 		name string
@@ -28,6 +36,8 @@ func TestRedisCoinCache_Set(t *testing.T) {
 
 		ctx  context.Context
 		coin domain.Coin
+		// expiration replaces the default one when set
+		expiration time.Duration
 
 		wantErr error
 	}{
@@ -38,7 +48,7 @@ func TestRedisCoinCache_Set(t *testing.T) {
 				assert.NoError(t, err)
 				cmd := redismocks.NewMockCmdable(ctrl)
 				mockRes := redis.NewStatusResult("OK", nil)
-				cmd.EXPECT().Set(gomock.Any(), keyFunc(coin.Id), bs, 15*time.Minute).Return(mockRes)
+				cmd.EXPECT().Set(gomock.Any(), keyFunc(coin.PublicId), bs, 15*time.Minute).Return(mockRes)
 				return cmd
 			},
 			ctx:  context.Background(),
@@ -51,13 +61,27 @@ func TestRedisCoinCache_Set(t *testing.T) {
 				assert.NoError(t, err)
 				cmd := redismocks.NewMockCmdable(ctrl)
 				mockRes := redis.NewStatusResult("", errors.New("redis conn error"))
-				cmd.EXPECT().Set(gomock.Any(), keyFunc(coin.Id), bs, 15*time.Minute).Return(mockRes)
+				cmd.EXPECT().Set(gomock.Any(), keyFunc(coin.PublicId), bs, 15*time.Minute).Return(mockRes)
 				return cmd
 			},
 			ctx:     context.Background(),
 			coin:    coin,
 			wantErr: errors.New("redis conn error"),
 		},
+		{
+			name: "changed expiration",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				bs, err := json.Marshal(coin)
+				assert.NoError(t, err)
+				cmd := redismocks.NewMockCmdable(ctrl)
+				mockRes := redis.NewStatusResult("OK", nil)
+				cmd.EXPECT().Set(gomock.Any(), keyFunc(coin.PublicId), bs, 5*time.Minute).Return(mockRes)
+				return cmd
+			},
+			ctx:        context.Background(),
+			coin:       coin,
+			expiration: 5 * time.Minute,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -66,7 +90,10 @@ func TestRedisCoinCache_Set(t *testing.T) {
 			defer ctrl.Finish()
 
 			cmd := tc.mock(ctrl)
-			cache := NewRedisCoinCache(cmd)
+			cache := NewRedisCoinCache(cmd, prometheus.NewRegistry())
+			if tc.expiration > 0 {
+				cache.SetExpiration(tc.expiration)
+			}
 
 			err := cache.Set(tc.ctx, tc.coin)
 			assert.Equal(t, tc.wantErr, err)
@@ -76,20 +103,22 @@ func TestRedisCoinCache_Set(t *testing.T) {
 
 func TestRedisCoinCache_Get(t *testing.T) {
 	coin := domain.Coin{
-		Id: 1,
+		Id:       1,
+		PublicId: publicId1,
 	}
 
-	keyFunc := func(id int64) string {
-		return fmt.Sprintf("coin:%d", id)
+	keyFunc := func(publicId string) string {
+		return fmt.Sprintf("coin:%s", publicId)
 	}
 	testCases := []struct {
 		name string
 		mock func(*gomock.Controller) redis.Cmdable
 
-		ctx context.Context
-		id  int64
+		ctx      context.Context
+		publicId string
 
-		wantErr error
+		wantErr    error
+		wantResult string
 	}{
 		{
 			name: "get success",
@@ -98,35 +127,38 @@ func TestRedisCoinCache_Get(t *testing.T) {
 				assert.NoError(t, err)
 				cmd := redismocks.NewMockCmdable(ctrl)
 				mockRes := redis.NewStringResult(string(bs), nil)
-				cmd.EXPECT().Get(gomock.Any(), keyFunc(1)).Return(mockRes)
+				cmd.EXPECT().Get(gomock.Any(), keyFunc(publicId1)).Return(mockRes)
 				return cmd
 			},
-			ctx: context.Background(),
-			id:  1,
+			ctx:        context.Background(),
+			publicId:   publicId1,
+			wantResult: "hit",
 		},
 		{
 			name: "key not found",
 			mock: func(ctrl *gomock.Controller) redis.Cmdable {
 				cmd := redismocks.NewMockCmdable(ctrl)
 				mockRes := redis.NewStringResult("", redis.Nil)
-				cmd.EXPECT().Get(gomock.Any(), keyFunc(2)).Return(mockRes)
+				cmd.EXPECT().Get(gomock.Any(), keyFunc(publicId2)).Return(mockRes)
 				return cmd
 			},
-			ctx:     context.Background(),
-			id:      2,
-			wantErr: redis.Nil,
+			ctx:        context.Background(),
+			publicId:   publicId2,
+			wantErr:    redis.Nil,
+			wantResult: "miss",
 		},
 		{
 			name: "redis conn error",
 			mock: func(ctrl *gomock.Controller) redis.Cmdable {
 				cmd := redismocks.NewMockCmdable(ctrl)
 				mockRes := redis.NewStringResult("", errors.New("redis conn error"))
-				cmd.EXPECT().Get(gomock.Any(), keyFunc(coin.Id)).Return(mockRes)
+				cmd.EXPECT().Get(gomock.Any(), keyFunc(coin.PublicId)).Return(mockRes)
 				return cmd
 			},
-			ctx:     context.Background(),
-			id:      1,
-			wantErr: errors.New("redis conn error"),
+			ctx:        context.Background(),
+			publicId:   publicId1,
+			wantErr:    errors.New("redis conn error"),
+			wantResult: "error",
 		},
 	}
 
@@ -136,24 +168,26 @@ func TestRedisCoinCache_Get(t *testing.T) {
 			defer ctrl.Finish()
 
 			cmd := tc.mock(ctrl)
-			cache := NewRedisCoinCache(cmd)
+			cache := NewRedisCoinCache(cmd, prometheus.NewRegistry())
 
-			_, err := cache.Get(tc.ctx, tc.id)
+			_, err := cache.Get(tc.ctx, tc.publicId)
 			assert.Equal(t, tc.wantErr, err)
+			ops := cache.ops
+			assert.Equal(t, float64(1), testutil.ToFloat64(ops.WithLabelValues("get", tc.wantResult)))
 		})
 	}
 }
 
 func TestRedisCoinCache_Del(t *testing.T) {
-	keyFunc := func(id int64) string {
-		return fmt.Sprintf("coin:%d", id)
+	keyFunc := func(publicId string) string {
+		return fmt.Sprintf("coin:%s", publicId)
 	}
 	testCases := []struct {
 		name string
 		mock func(*gomock.Controller) redis.Cmdable
 
-		ctx context.Context
-		id  int64
+		ctx      context.Context
+		publicId string
 
 		wantErr error
 	}{
@@ -162,34 +196,104 @@ func TestRedisCoinCache_Del(t *testing.T) {
 			mock: func(ctrl *gomock.Controller) redis.Cmdable {
 				cmd := redismocks.NewMockCmdable(ctrl)
 				mockRes := redis.NewIntResult(1, nil)
-				cmd.EXPECT().Del(gomock.Any(), keyFunc(1)).Return(mockRes)
+				cmd.EXPECT().Del(gomock.Any(), keyFunc(publicId1)).Return(mockRes)
 				return cmd
 			},
-			ctx: context.Background(),
-			id:  1,
+			ctx:      context.Background(),
+			publicId: publicId1,
 		},
 		{
 			name: "key not found",
 			mock: func(ctrl *gomock.Controller) redis.Cmdable {
 				cmd := redismocks.NewMockCmdable(ctrl)
 				mockRes := redis.NewIntResult(0, nil)
-				cmd.EXPECT().Del(gomock.Any(), keyFunc(2)).Return(mockRes)
+				cmd.EXPECT().Del(gomock.Any(), keyFunc(publicId2)).Return(mockRes)
 				return cmd
 			},
-			ctx:     context.Background(),
-			id:      2,
-			wantErr: nil,
+			ctx:      context.Background(),
+			publicId: publicId2,
+			wantErr:  nil,
 		},
 		{
 			name: "redis conn error",
 			mock: func(ctrl *gomock.Controller) redis.Cmdable {
 				cmd := redismocks.NewMockCmdable(ctrl)
 				mockRes := redis.NewIntResult(0, errors.New("redis conn error"))
-				cmd.EXPECT().Del(gomock.Any(), keyFunc(1)).Return(mockRes)
+				cmd.EXPECT().Del(gomock.Any(), keyFunc(publicId1)).Return(mockRes)
 				return cmd
 			},
-			ctx:     context.Background(),
-			id:      1,
+			ctx:      context.Background(),
+			publicId: publicId1,
+			wantErr:  errors.New("redis conn error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cmd := tc.mock(ctrl)
+			cache := NewRedisCoinCache(cmd, prometheus.NewRegistry())
+
+			err := cache.Del(tc.ctx, tc.publicId)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestRedisCoinCache_SetMulti(t *testing.T) {
+	coins := []domain.Coin{
+		{Id: 1, PublicId: publicId1},
+		{Id: 2, PublicId: publicId2},
+	}
+
+	keyFunc := func(publicId string) string {
+		return fmt.Sprintf("coin:%s", publicId)
+	}
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) redis.Cmdable
+
+		coins []domain.Coin
+
+		wantErr error
+	}{
+		{
+			name: "set multi success",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				pipe := redismocks.NewMockPipeliner(ctrl)
+				cmd.EXPECT().Pipeline().Return(pipe)
+				for _, coin := range coins {
+					bs, err := json.Marshal(coin)
+					assert.NoError(t, err)
+					pipe.EXPECT().Set(gomock.Any(), keyFunc(coin.PublicId), bs, 15*time.Minute).
+						Return(redis.NewStatusResult("OK", nil))
+				}
+				pipe.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+				return cmd
+			},
+			coins: coins,
+		},
+		{
+			name: "empty coins",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				return redismocks.NewMockCmdable(ctrl)
+			},
+		},
+		{
+			name: "redis conn error",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				pipe := redismocks.NewMockPipeliner(ctrl)
+				cmd.EXPECT().Pipeline().Return(pipe)
+				pipe.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), 15*time.Minute).
+					Return(redis.NewStatusResult("", nil)).Times(len(coins))
+				pipe.EXPECT().Exec(gomock.Any()).Return(nil, errors.New("redis conn error"))
+				return cmd
+			},
+			coins:   coins,
 			wantErr: errors.New("redis conn error"),
 		},
 	}
@@ -200,10 +304,120 @@ func TestRedisCoinCache_Del(t *testing.T) {
 			defer ctrl.Finish()
 
 			cmd := tc.mock(ctrl)
-			cache := NewRedisCoinCache(cmd)
+			cache := NewRedisCoinCache(cmd, prometheus.NewRegistry())
+
+			err := cache.SetMulti(context.Background(), tc.coins)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestRedisCoinCache_Count(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) redis.Cmdable
+
+		wantCount int64
+		wantErr   error
+	}{
+		{
+			name: "count across cursors",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Scan(gomock.Any(), uint64(0), "coin:*", int64(500)).
+					Return(redis.NewScanCmdResult([]string{"coin:" + publicId1}, 7, nil))
+				cmd.EXPECT().Scan(gomock.Any(), uint64(7), "coin:*", int64(500)).
+					Return(redis.NewScanCmdResult([]string{"coin:" + publicId2}, 0, nil))
+				return cmd
+			},
+			wantCount: 2,
+		},
+		{
+			name: "redis conn error",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Scan(gomock.Any(), uint64(0), "coin:*", int64(500)).
+					Return(redis.NewScanCmdResult(nil, 0, errors.New("redis conn error")))
+				return cmd
+			},
+			wantErr: errors.New("redis conn error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cache := NewRedisCoinCache(tc.mock(ctrl), prometheus.NewRegistry())
+
+			n, err := cache.Count(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCount, n)
+		})
+	}
+}
+
+func TestRedisCoinCache_Flush(t *testing.T) {
+	keys := []string{"coin:" + publicId1, "coin:" + publicId2}
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) redis.Cmdable
+
+		wantCount int64
+		wantErr   error
+	}{
+		{
+			name: "flush success",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				pipe := redismocks.NewMockPipeliner(ctrl)
+				cmd.EXPECT().Scan(gomock.Any(), uint64(0), "coin:*", int64(500)).
+					Return(redis.NewScanCmdResult(keys, 0, nil))
+				cmd.EXPECT().Pipeline().Return(pipe)
+				pipe.EXPECT().Del(gomock.Any(), keys[0]).Return(redis.NewIntResult(1, nil))
+				// expired between the scan and the delete
+				pipe.EXPECT().Del(gomock.Any(), keys[1]).Return(redis.NewIntResult(0, nil))
+				pipe.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+				return cmd
+			},
+			wantCount: 1,
+		},
+		{
+			name: "no entries",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				cmd.EXPECT().Scan(gomock.Any(), uint64(0), "coin:*", int64(500)).
+					Return(redis.NewScanCmdResult(nil, 0, nil))
+				return cmd
+			},
+		},
+		{
+			name: "redis conn error",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				pipe := redismocks.NewMockPipeliner(ctrl)
+				cmd.EXPECT().Scan(gomock.Any(), uint64(0), "coin:*", int64(500)).
+					Return(redis.NewScanCmdResult(keys, 0, nil))
+				cmd.EXPECT().Pipeline().Return(pipe)
+				pipe.EXPECT().Del(gomock.Any(), gomock.Any()).Return(redis.NewIntResult(0, nil)).Times(len(keys))
+				pipe.EXPECT().Exec(gomock.Any()).Return(nil, errors.New("redis conn error"))
+				return cmd
+			},
+			wantErr: errors.New("redis conn error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cache := NewRedisCoinCache(tc.mock(ctrl), prometheus.NewRegistry())
 
-			err := cache.Del(tc.ctx, tc.id)
+			n, err := cache.Flush(context.Background())
 			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCount, n)
 		})
 	}
 }
@@ -42,32 +42,32 @@ func (m *MockCoinCache) EXPECT() *MockCoinCacheMockRecorder {
 }
 
 // Del mocks base method.
-func (m *MockCoinCache) Del(ctx context.Context, id int64) error {
+func (m *MockCoinCache) Del(ctx context.Context, publicId string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Del", ctx, id)
+	ret := m.ctrl.Call(m, "Del", ctx, publicId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Del indicates an expected call of Del.
-func (mr *MockCoinCacheMockRecorder) Del(ctx, id any) *gomock.Call {
+func (mr *MockCoinCacheMockRecorder) Del(ctx, publicId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockCoinCache)(nil).Del), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockCoinCache)(nil).Del), ctx, publicId)
 }
 
 // Get mocks base method.
-func (m *MockCoinCache) Get(ctx context.Context, id int64) (domain.Coin, error) {
+func (m *MockCoinCache) Get(ctx context.Context, publicId string) (domain.Coin, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret := m.ctrl.Call(m, "Get", ctx, publicId)
 	ret0, _ := ret[0].(domain.Coin)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockCoinCacheMockRecorder) Get(ctx, id any) *gomock.Call {
+func (mr *MockCoinCacheMockRecorder) Get(ctx, publicId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCoinCache)(nil).Get), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCoinCache)(nil).Get), ctx, publicId)
 }
 
 // Set mocks base method.
@@ -83,3 +83,17 @@ func (mr *MockCoinCacheMockRecorder) Set(ctx, c any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCoinCache)(nil).Set), ctx, c)
 }
+
+// SetMulti mocks base method.
+func (m *MockCoinCache) SetMulti(ctx context.Context, coins []domain.Coin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMulti", ctx, coins)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMulti indicates an expected call of SetMulti.
+func (mr *MockCoinCacheMockRecorder) SetMulti(ctx, coins any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMulti", reflect.TypeOf((*MockCoinCache)(nil).SetMulti), ctx, coins)
+}
@@ -1,3 +1,4 @@
 package redismocks
 
 //go:generate mockgen -package=redismocks -destination=./cmd.mock.go github.com/redis/go-redis/v9 Cmdable
+//go:generate mockgen -package=redismocks -destination=./pipeliner.mock.go github.com/redis/go-redis/v9 Pipeliner
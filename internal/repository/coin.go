@@ -3,42 +3,71 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/miles0wu/meme-coin-api/internal/domain"
 	"github.com/miles0wu/meme-coin-api/internal/repository/cache"
 	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
 	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
 	"time"
 )
 
 var (
-	ErrDuplicateName = dao.ErrDuplicateName
-	ErrNotFound      = dao.ErrRecordNotFound
+	ErrDuplicateName     = dao.ErrDuplicateName
+	ErrNotFound          = dao.ErrRecordNotFound
+	ErrDuplicateReaction = dao.ErrDuplicateReaction
 )
 
 //go:generate mockgen -source=./coin.go -package=repomocks -destination=./mocks/coin.mock.go CoinRepository
 type CoinRepository interface {
 	Create(ctx context.Context, coin domain.Coin) (domain.Coin, error)
+	// Update and Delete need both the id and the public id of the coin, read it first.
 	Update(ctx context.Context, coin domain.Coin) error
-	FindById(ctx context.Context, id int64) (domain.Coin, error)
-	DeleteById(ctx context.Context, id int64) error
-	IncrPopularityScore(ctx context.Context, id int64) error
+	FindByPublicId(ctx context.Context, publicId string) (domain.Coin, error)
+	// FindByPublicIdForUpdate reads the coin bypassing the cache and locks it, call it in a transaction.
+	// Unlike the other reads, it leaves the reactions of the coin out.
+	FindByPublicIdForUpdate(ctx context.Context, publicId string) (domain.Coin, error)
+	Delete(ctx context.Context, coin domain.Coin) error
+	AddReaction(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) error
+	RemoveReaction(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) error
+	FindTopByPopularity(ctx context.Context, limit int) ([]domain.Coin, error)
+	FindTopByHotScore(ctx context.Context, limit int) ([]domain.Coin, error)
+	FindRecent(ctx context.Context, limit int) ([]domain.Coin, error)
+	// Preload writes coins into the cache without touching the database.
+	Preload(ctx context.Context, coins []domain.Coin) error
+	// FindRevisions returns the first limit revisions of the coin following the revision after.
+	FindRevisions(ctx context.Context, publicId string, after int64, limit int) ([]domain.CoinRevision, error)
+	FindRevisionAsOf(ctx context.Context, publicId string, at time.Time) (domain.CoinRevision, error)
+	// RefreshHotScores decays the hot score of every coin to now and returns the number of coins updated.
+	// The cached coins keep their hot score until they expire.
+	RefreshHotScores(ctx context.Context, now time.Time, batchSize int) (int64, error)
 }
 
 type CachedCoinRepository struct {
-	dao   dao.CoinDAO
-	cache cache.CoinCache
-	l     logger.Logger
+	dao    dao.CoinDAO
+	cache  cache.CoinCache
+	l      logger.Logger
+	tracer trace.Tracer
+	// background tracks the cache writes outliving the calls, see Wait
+	background sync.WaitGroup
 }
 
-func NewCachedCoinRepository(dao dao.CoinDAO, cache cache.CoinCache, l logger.Logger) CoinRepository {
+func NewCachedCoinRepository(dao dao.CoinDAO, cache cache.CoinCache, l logger.Logger) *CachedCoinRepository {
 	return &CachedCoinRepository{
-		dao:   dao,
-		cache: cache,
-		l:     l,
+		dao:    dao,
+		cache:  cache,
+		l:      l,
+		tracer: otel.Tracer("github.com/miles0wu/meme-coin-api/internal/repository"),
 	}
 }
 
-func (repo *CachedCoinRepository) Create(ctx context.Context, coin domain.Coin) (domain.Coin, error) {
+func (repo *CachedCoinRepository) Create(ctx context.Context, coin domain.Coin) (res domain.Coin, err error) {
+	ctx, span := repo.start(ctx, "Create")
+	defer func() { endSpan(span, err) }()
 	dc, err := repo.dao.Insert(ctx, repo.toEntity(coin))
 	if err != nil {
 		return domain.Coin{}, err
@@ -46,92 +75,248 @@ func (repo *CachedCoinRepository) Create(ctx context.Context, coin domain.Coin)
 	return repo.toDomain(dc), nil
 }
 
-func (repo *CachedCoinRepository) Update(ctx context.Context, coin domain.Coin) error {
-	err := repo.dao.UpdateById(ctx, repo.toEntity(coin))
+func (repo *CachedCoinRepository) Update(ctx context.Context, coin domain.Coin) (err error) {
+	ctx, span := repo.start(ctx, "Update", attribute.String("coin.public_id", coin.PublicId))
+	defer func() { endSpan(span, err) }()
+	err = repo.dao.UpdateById(ctx, repo.toEntity(coin))
 	if err != nil {
 		return err
 	}
-	go func() {
-		newCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
-		defer cancel()
-		er := repo.cache.Del(newCtx, coin.Id)
-		if er != nil {
-			repo.l.Error("failed to delete coin cache after update coin",
-				logger.Int64("coin_id", coin.Id),
-				logger.Error(err))
-		}
-	}()
+	repo.delCache(ctx, coin.PublicId, "failed to delete coin cache after update coin")
 	return nil
 }
 
-func (repo *CachedCoinRepository) FindById(ctx context.Context, id int64) (domain.Coin, error) {
+func (repo *CachedCoinRepository) FindByPublicId(ctx context.Context, publicId string) (res domain.Coin, err error) {
+	ctx, span := repo.start(ctx, "FindByPublicId", attribute.String("coin.public_id", publicId))
+	defer func() { endSpan(span, err) }()
 	// get coin from cache, return domain object if hit
-	coin, err := repo.cache.Get(ctx, id)
+	coin, err := repo.cache.Get(ctx, publicId)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
 	if err == nil {
 		return coin, nil
 	}
 
 	// get coin from db
-	entity, err := repo.dao.FindById(ctx, id)
+	entity, err := repo.dao.FindByPublicId(ctx, publicId)
 	if err != nil {
 		return domain.Coin{}, err
 	}
 
 	// set coin cache
-	coin = repo.toDomain(entity)
-	go func() {
-		newCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	coins := []domain.Coin{repo.toDomain(entity)}
+	if err = repo.withReactions(ctx, coins); err != nil {
+		return domain.Coin{}, err
+	}
+	coin = coins[0]
+	repo.goBackground(func() {
+		newCtx, span := repo.startBackground(ctx, "CachedCoinRepository.setCache")
+		defer span.End()
+		newCtx, cancel := context.WithTimeout(newCtx, 100*time.Millisecond)
 		defer cancel()
 		er := repo.cache.Set(newCtx, coin)
-		if er != nil {
-			repo.l.Error("failed to set coin cache after get coin from db",
-				logger.Int64("coin_id", coin.Id),
-				logger.Error(err))
+		if er != nil && !errors.Is(er, cache.ErrCacheUnavailable) {
+			logger.FromContext(ctx, repo.l).Error("failed to set coin cache after get coin from db",
+				logger.String("coin_public_id", coin.PublicId),
+				logger.Error(er))
 		}
-	}()
+	})
 
 	return coin, nil
 }
 
-func (repo *CachedCoinRepository) DeleteById(ctx context.Context, id int64) error {
-	err := repo.dao.DeleteById(ctx, id)
+// FindByPublicIdForUpdate reads the coin from the database and locks it until the transaction of ctx ends,
+// it bypasses the cache which must not be filled with uncommitted data.
+func (repo *CachedCoinRepository) FindByPublicIdForUpdate(ctx context.Context, publicId string) (res domain.Coin, err error) {
+	ctx, span := repo.start(ctx, "FindByPublicIdForUpdate", attribute.String("coin.public_id", publicId))
+	defer func() { endSpan(span, err) }()
+	entity, err := repo.dao.FindByPublicIdForUpdate(ctx, publicId)
+	if err != nil {
+		return domain.Coin{}, err
+	}
+	return repo.toDomain(entity), nil
+}
+
+func (repo *CachedCoinRepository) Delete(ctx context.Context, coin domain.Coin) (err error) {
+	ctx, span := repo.start(ctx, "Delete", attribute.String("coin.public_id", coin.PublicId))
+	defer func() { endSpan(span, err) }()
+	err = repo.dao.DeleteById(ctx, coin.Id)
 	if err != nil {
 		return err
 	}
-	go func() {
-		newCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
-		defer cancel()
-		er := repo.cache.Del(newCtx, id)
-		if er != nil {
-			repo.l.Error("failed to delete coin cache after delete coin",
-				logger.Int64("coin_id", id),
-				logger.Error(err))
-		}
-	}()
+	repo.delCache(ctx, coin.PublicId, "failed to delete coin cache after delete coin")
 	return err
 }
 
-func (repo *CachedCoinRepository) IncrPopularityScore(ctx context.Context, id int64) error {
-	err := repo.dao.IncrPopularityScore(ctx, id)
+func (repo *CachedCoinRepository) AddReaction(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) (err error) {
+	ctx, span := repo.start(ctx, "AddReaction", attribute.String("coin.public_id", publicId),
+		attribute.String("coin.reaction", string(reaction)))
+	defer func() { endSpan(span, err) }()
+	id, err := repo.resolveId(ctx, publicId)
+	if err != nil {
+		return err
+	}
+	err = repo.dao.AddReaction(ctx, id, actor, string(reaction))
 	if err != nil {
 		return err
 	}
+	repo.delCache(ctx, publicId, "failed to delete coin cache after add reaction")
+	return nil
+}
+
+func (repo *CachedCoinRepository) RemoveReaction(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) (err error) {
+	ctx, span := repo.start(ctx, "RemoveReaction", attribute.String("coin.public_id", publicId),
+		attribute.String("coin.reaction", string(reaction)))
+	defer func() { endSpan(span, err) }()
+	id, err := repo.resolveId(ctx, publicId)
+	if err != nil {
+		return err
+	}
+	err = repo.dao.RemoveReaction(ctx, id, actor, string(reaction))
+	if err != nil {
+		return err
+	}
+	repo.delCache(ctx, publicId, "failed to delete coin cache after remove reaction")
+	return nil
+}
+
+// resolveId returns the id of the coin of publicId. Unlike FindByPublicId it does not fill the cache,
+// the write that follows would race with the fill and could leave stale data behind.
+func (repo *CachedCoinRepository) resolveId(ctx context.Context, publicId string) (int64, error) {
+	coin, err := repo.cache.Get(ctx, publicId)
+	if err == nil {
+		return coin.Id, nil
+	}
+	entity, err := repo.dao.FindByPublicId(ctx, publicId)
+	if err != nil {
+		return 0, err
+	}
+	return entity.Id, nil
+}
+
+func (repo *CachedCoinRepository) FindTopByPopularity(ctx context.Context, limit int) (res []domain.Coin, err error) {
+	ctx, span := repo.start(ctx, "FindTopByPopularity", attribute.Int("coin.limit", limit))
+	defer func() { endSpan(span, err) }()
+	entities, err := repo.dao.FindTopByPopularity(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.toDomainsWithReactions(ctx, entities)
+}
+
+func (repo *CachedCoinRepository) FindTopByHotScore(ctx context.Context, limit int) (res []domain.Coin, err error) {
+	ctx, span := repo.start(ctx, "FindTopByHotScore", attribute.Int("coin.limit", limit))
+	defer func() { endSpan(span, err) }()
+	entities, err := repo.dao.FindTopByHotScore(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.toDomainsWithReactions(ctx, entities)
+}
+
+func (repo *CachedCoinRepository) FindRecent(ctx context.Context, limit int) (res []domain.Coin, err error) {
+	ctx, span := repo.start(ctx, "FindRecent", attribute.Int("coin.limit", limit))
+	defer func() { endSpan(span, err) }()
+	entities, err := repo.dao.FindRecent(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.toDomainsWithReactions(ctx, entities)
+}
+
+func (repo *CachedCoinRepository) Preload(ctx context.Context, coins []domain.Coin) (err error) {
+	ctx, span := repo.start(ctx, "Preload", attribute.Int("coin.count", len(coins)))
+	defer func() { endSpan(span, err) }()
+	return repo.cache.SetMulti(ctx, coins)
+}
+
+func (repo *CachedCoinRepository) FindRevisions(ctx context.Context, publicId string, after int64, limit int) (res []domain.CoinRevision, err error) {
+	ctx, span := repo.start(ctx, "FindRevisions", attribute.String("coin.public_id", publicId))
+	defer func() { endSpan(span, err) }()
+	entities, err := repo.dao.FindRevisions(ctx, publicId, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	res = make([]domain.CoinRevision, 0, len(entities))
+	for _, e := range entities {
+		res = append(res, repo.toDomainRevision(e))
+	}
+	return res, nil
+}
+
+func (repo *CachedCoinRepository) FindRevisionAsOf(ctx context.Context, publicId string, at time.Time) (res domain.CoinRevision, err error) {
+	ctx, span := repo.start(ctx, "FindRevisionAsOf", attribute.String("coin.public_id", publicId))
+	defer func() { endSpan(span, err) }()
+	entity, err := repo.dao.FindRevisionAsOf(ctx, publicId, at.UnixMilli())
+	if err != nil {
+		return domain.CoinRevision{}, err
+	}
+	return repo.toDomainRevision(entity), nil
+}
+
+func (repo *CachedCoinRepository) RefreshHotScores(ctx context.Context, now time.Time, batchSize int) (n int64, err error) {
+	ctx, span := repo.start(ctx, "RefreshHotScores")
+	defer func() { endSpan(span, err) }()
+	return repo.dao.RefreshHotScores(ctx, now.UnixMilli(), batchSize)
+}
+
+// delCache invalidates the cached coin once the transaction of ctx, if any, commits.
+func (repo *CachedCoinRepository) delCache(ctx context.Context, publicId string, errMsg string) {
+	dao.AfterCommit(ctx, func() {
+		repo.goBackground(func() {
+			newCtx, span := repo.startBackground(ctx, "CachedCoinRepository.delCache")
+			defer span.End()
+			newCtx, cancel := context.WithTimeout(newCtx, 100*time.Millisecond)
+			defer cancel()
+			er := repo.cache.Del(newCtx, publicId)
+			if er != nil && !errors.Is(er, cache.ErrCacheUnavailable) {
+				logger.FromContext(ctx, repo.l).Error(errMsg,
+					logger.String("coin_public_id", publicId),
+					logger.Error(er))
+			}
+		})
+	})
+}
+
+// goBackground runs fn without holding up the caller, Wait waits for it.
+func (repo *CachedCoinRepository) goBackground(fn func()) {
+	repo.background.Add(1)
 	go func() {
-		newCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
-		defer cancel()
-		er := repo.cache.Del(newCtx, id)
-		if er != nil {
-			repo.l.Error("failed to delete coin cache after increase popularity score",
-				logger.Int64("coin_id", id),
-				logger.Error(err))
-		}
+		defer repo.background.Done()
+		fn()
 	}()
-	return nil
+}
+
+// Wait blocks until the cache writes started in the background are done,
+// a process exiting right after a change calls it so that the change is not left cached.
+func (repo *CachedCoinRepository) Wait() {
+	repo.background.Wait()
+}
+
+// start starts the span of a call of the repository, the spans of the cache and the database nest in it.
+func (repo *CachedCoinRepository) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return repo.tracer.Start(ctx, "CachedCoinRepository."+name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, recording err unless it is a not found, which is an answer rather than a failure.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startBackground starts the root span of work outliving the request of ctx,
+// linked to the span of the request rather than nested in it.
+func (repo *CachedCoinRepository) startBackground(ctx context.Context, name string) (context.Context, trace.Span) {
+	return repo.tracer.Start(context.Background(), name,
+		trace.WithNewRoot(), trace.WithLinks(trace.LinkFromContext(ctx)))
 }
 
 func (repo *CachedCoinRepository) toEntity(c domain.Coin) dao.Coin {
 	return dao.Coin{
 		Id:          c.Id,
+		PublicId:    sql.NullString{String: c.PublicId, Valid: c.PublicId != ""},
 		Name:        c.Name,
 		Description: sql.NullString{String: c.Description, Valid: c.Description != ""},
 	}
@@ -140,10 +325,65 @@ func (repo *CachedCoinRepository) toEntity(c domain.Coin) dao.Coin {
 func (repo *CachedCoinRepository) toDomain(c dao.Coin) domain.Coin {
 	return domain.Coin{
 		Id:              c.Id,
+		PublicId:        c.PublicId.String,
 		Name:            c.Name,
 		Description:     c.Description.String,
 		CreatedAt:       time.UnixMilli(c.CreatedAt),
 		UpdatedAt:       time.UnixMilli(c.UpdatedAt),
 		PopularityScore: c.PopularityScore,
+		HotScore:        c.HotScore,
+	}
+}
+
+func (repo *CachedCoinRepository) toDomainsWithReactions(ctx context.Context, entities []dao.Coin) ([]domain.Coin, error) {
+	res := make([]domain.Coin, 0, len(entities))
+	for _, e := range entities {
+		res = append(res, repo.toDomain(e))
+	}
+	if err := repo.withReactions(ctx, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// withReactions fills in the reaction counts of the coins with a single query.
+func (repo *CachedCoinRepository) withReactions(ctx context.Context, coins []domain.Coin) error {
+	if len(coins) == 0 {
+		return nil
+	}
+	idx := make(map[int64]int, len(coins))
+	ids := make([]int64, 0, len(coins))
+	for i, c := range coins {
+		idx[c.Id] = i
+		ids = append(ids, c.Id)
+	}
+	counts, err := repo.dao.FindReactionCounts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, cnt := range counts {
+		c := &coins[idx[cnt.CoinId]]
+		if c.Reactions == nil {
+			c.Reactions = make(map[domain.ReactionType]int64)
+		}
+		c.Reactions[domain.ReactionType(cnt.Reaction)] = cnt.Total
+	}
+	return nil
+}
+
+func (repo *CachedCoinRepository) toDomainRevision(r dao.CoinRevision) domain.CoinRevision {
+	return domain.CoinRevision{
+		Revision: r.Revision,
+		Action:   domain.RevisionAction(r.Action),
+		Coin: domain.Coin{
+			Id:              r.CoinId,
+			PublicId:        r.PublicId.String,
+			Name:            r.Name,
+			Description:     r.Description.String,
+			CreatedAt:       time.UnixMilli(r.CreatedAt),
+			UpdatedAt:       time.UnixMilli(r.UpdatedAt),
+			PopularityScore: r.PopularityScore,
+		},
+		ValidFrom: time.UnixMilli(r.ValidFrom),
 	}
 }
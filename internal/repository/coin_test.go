@@ -11,11 +11,18 @@ import (
 	daomocks "github.com/miles0wu/meme-coin-api/internal/repository/dao/mocks"
 	"github.com/miles0wu/meme-coin-api/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/mock/gomock"
 	"testing"
 	"time"
 )
 
+const publicId = "01ARYZ6S410000000000000001"
+
 func TestCachedCoinRepository_Create(t *testing.T) {
 	nowMs := time.Now().UnixMilli()
 	now := time.UnixMilli(nowMs)
@@ -34,10 +41,12 @@ func TestCachedCoinRepository_Create(t *testing.T) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
 				coinDAO.EXPECT().Insert(gomock.Any(), dao.Coin{
+					PublicId:    sql.NullString{String: publicId, Valid: true},
 					Name:        "test",
 					Description: sql.NullString{String: "test description", Valid: true},
 				}).Return(dao.Coin{
 					Id:              1,
+					PublicId:        sql.NullString{String: publicId, Valid: true},
 					Name:            "test",
 					Description:     sql.NullString{String: "test description", Valid: true},
 					CreatedAt:       nowMs,
@@ -47,11 +56,13 @@ func TestCachedCoinRepository_Create(t *testing.T) {
 				return coinDAO, coinCache
 			},
 			coin: domain.Coin{
+				PublicId:    publicId,
 				Name:        "test",
 				Description: "test description",
 			},
 			wantRet: domain.Coin{
 				Id:              1,
+				PublicId:        publicId,
 				Name:            "test",
 				Description:     "test description",
 				CreatedAt:       now,
@@ -130,14 +141,16 @@ func TestCachedCoinRepository_Update(t *testing.T) {
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
 				coinDAO.EXPECT().UpdateById(gomock.Any(), dao.Coin{
 					Id:          1,
+					PublicId:    sql.NullString{String: publicId, Valid: true},
 					Name:        "test",
 					Description: sql.NullString{String: "new test description", Valid: true},
 				}).Return(nil)
-				coinCache.EXPECT().Del(gomock.Any(), int64(1)).Return(nil)
+				coinCache.EXPECT().Del(gomock.Any(), publicId).Return(nil)
 				return coinDAO, coinCache
 			},
 			coin: domain.Coin{
 				Id:              1,
+				PublicId:        publicId,
 				Name:            "test",
 				Description:     "new test description",
 				CreatedAt:       now,
@@ -153,14 +166,16 @@ func TestCachedCoinRepository_Update(t *testing.T) {
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
 				coinDAO.EXPECT().UpdateById(gomock.Any(), dao.Coin{
 					Id:          1,
+					PublicId:    sql.NullString{String: publicId, Valid: true},
 					Name:        "test",
 					Description: sql.NullString{String: "new test description", Valid: true},
 				}).Return(nil)
-				coinCache.EXPECT().Del(gomock.Any(), int64(1)).Return(errors.New("redis conn error"))
+				coinCache.EXPECT().Del(gomock.Any(), publicId).Return(errors.New("redis conn error"))
 				return coinDAO, coinCache
 			},
 			coin: domain.Coin{
 				Id:              1,
+				PublicId:        publicId,
 				Name:            "test",
 				Description:     "new test description",
 				CreatedAt:       now,
@@ -176,6 +191,7 @@ func TestCachedCoinRepository_Update(t *testing.T) {
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
 				coinDAO.EXPECT().UpdateById(gomock.Any(), dao.Coin{
 					Id:          1,
+					PublicId:    sql.NullString{String: publicId, Valid: true},
 					Name:        "test",
 					Description: sql.NullString{String: "new test description", Valid: true},
 				}).Return(errors.New("mock db error"))
@@ -183,6 +199,7 @@ func TestCachedCoinRepository_Update(t *testing.T) {
 			},
 			coin: domain.Coin{
 				Id:              1,
+				PublicId:        publicId,
 				Name:            "test",
 				Description:     "new test description",
 				CreatedAt:       now,
@@ -200,20 +217,20 @@ func TestCachedCoinRepository_Update(t *testing.T) {
 			coinDAO, coinCache := tc.mock(ctrl)
 			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
 			err := repo.Update(context.Background(), tc.coin)
-			time.Sleep(time.Millisecond * 300)
+			repo.Wait()
 			assert.Equal(t, tc.wantErr, err)
 		})
 	}
 }
 
-func TestCachedCoinRepository_FindById(t *testing.T) {
+func TestCachedCoinRepository_FindByPublicId(t *testing.T) {
 	nowMs := time.Now().UnixMilli()
 	now := time.UnixMilli(nowMs)
 	testCases := []struct {
 		name string
 		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
 
-		id int64
+		publicId string
 
 		wantRet domain.Coin
 		wantErr error
@@ -223,8 +240,9 @@ func TestCachedCoinRepository_FindById(t *testing.T) {
 			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
-				coinCache.EXPECT().Get(gomock.Any(), int64(1)).Return(domain.Coin{
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{
 					Id:              1,
+					PublicId:        publicId,
 					Name:            "test",
 					Description:     "new test description",
 					CreatedAt:       now,
@@ -233,9 +251,10 @@ func TestCachedCoinRepository_FindById(t *testing.T) {
 				}, nil)
 				return coinDAO, coinCache
 			},
-			id: 1,
+			publicId: publicId,
 			wantRet: domain.Coin{
 				Id:              1,
+				PublicId:        publicId,
 				Name:            "test",
 				Description:     "new test description",
 				CreatedAt:       now,
@@ -248,59 +267,80 @@ func TestCachedCoinRepository_FindById(t *testing.T) {
 			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
-				coinCache.EXPECT().Get(gomock.Any(), int64(1)).Return(domain.Coin{}, cache.ErrKeyNotExist)
-				coinDAO.EXPECT().FindById(gomock.Any(), int64(1)).Return(dao.Coin{
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{}, cache.ErrKeyNotExist)
+				coinDAO.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(dao.Coin{
 					Id:              1,
+					PublicId:        sql.NullString{String: publicId, Valid: true},
 					Name:            "test",
 					Description:     sql.NullString{String: "new test description", Valid: true},
 					CreatedAt:       nowMs,
 					UpdatedAt:       nowMs,
 					PopularityScore: 0,
 				}, nil)
+				coinDAO.EXPECT().FindReactionCounts(gomock.Any(), []int64{1}).Return([]dao.CoinReactionCount{
+					{CoinId: 1, Reaction: "rocket", Total: 2},
+				}, nil)
 				coinCache.EXPECT().Set(gomock.Any(), domain.Coin{
 					Id:              1,
+					PublicId:        publicId,
 					Name:            "test",
 					Description:     "new test description",
 					CreatedAt:       now,
 					UpdatedAt:       now,
 					PopularityScore: 0,
+					Reactions:       map[domain.ReactionType]int64{domain.ReactionRocket: 2},
 				}).Return(nil)
 				return coinDAO, coinCache
 			},
-			id: 1,
+			publicId: publicId,
 			wantRet: domain.Coin{
 				Id:              1,
+				PublicId:        publicId,
 				Name:            "test",
 				Description:     "new test description",
 				CreatedAt:       now,
 				UpdatedAt:       now,
 				PopularityScore: 0,
+				Reactions:       map[domain.ReactionType]int64{domain.ReactionRocket: 2},
 			},
 			wantErr: nil,
 		},
+		{
+			name: "cache miss and reaction counts error",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{}, cache.ErrKeyNotExist)
+				coinDAO.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(dao.Coin{Id: 1}, nil)
+				coinDAO.EXPECT().FindReactionCounts(gomock.Any(), []int64{1}).Return(nil, errors.New("mock db error"))
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  errors.New("mock db error"),
+		},
 		{
 			name: "cache miss and db not found",
 			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
-				coinCache.EXPECT().Get(gomock.Any(), int64(1)).Return(domain.Coin{}, cache.ErrKeyNotExist)
-				coinDAO.EXPECT().FindById(gomock.Any(), int64(1)).Return(dao.Coin{}, dao.ErrRecordNotFound)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{}, cache.ErrKeyNotExist)
+				coinDAO.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(dao.Coin{}, dao.ErrRecordNotFound)
 				return coinDAO, coinCache
 			},
-			id:      1,
-			wantErr: dao.ErrRecordNotFound,
+			publicId: publicId,
+			wantErr:  dao.ErrRecordNotFound,
 		},
 		{
 			name: "cache miss and db error",
 			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
-				coinCache.EXPECT().Get(gomock.Any(), int64(1)).Return(domain.Coin{}, cache.ErrKeyNotExist)
-				coinDAO.EXPECT().FindById(gomock.Any(), int64(1)).Return(dao.Coin{}, errors.New("mock db error"))
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{}, cache.ErrKeyNotExist)
+				coinDAO.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(dao.Coin{}, errors.New("mock db error"))
 				return coinDAO, coinCache
 			},
-			id:      1,
-			wantErr: errors.New("mock db error"),
+			publicId: publicId,
+			wantErr:  errors.New("mock db error"),
 		},
 	}
 
@@ -310,8 +350,8 @@ func TestCachedCoinRepository_FindById(t *testing.T) {
 			defer ctrl.Finish()
 			coinDAO, coinCache := tc.mock(ctrl)
 			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
-			ret, err := repo.FindById(context.Background(), tc.id)
-			time.Sleep(time.Millisecond * 300)
+			ret, err := repo.FindByPublicId(context.Background(), tc.publicId)
+			repo.Wait()
 			assert.Equal(t, tc.wantErr, err)
 			if err != nil {
 				return
@@ -321,12 +361,137 @@ func TestCachedCoinRepository_FindById(t *testing.T) {
 	}
 }
 
-func TestCachedCoinRepository_DeleteById(t *testing.T) {
+func TestCachedCoinRepository_FindByPublicIdSpans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	coinDAO := daomocks.NewMockCoinDAO(ctrl)
+	coinCache := cachemocks.NewMockCoinCache(ctrl)
+	coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{}, cache.ErrKeyNotExist)
+	coinDAO.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(dao.Coin{Id: 1}, nil)
+	coinDAO.EXPECT().FindReactionCounts(gomock.Any(), []int64{1}).Return(nil, nil)
+	coinCache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+	repo.tracer = tp.Tracer("test")
+
+	_, err := repo.FindByPublicId(context.Background(), publicId)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(recorder.Ended()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	find, set := spans["CachedCoinRepository.FindByPublicId"], spans["CachedCoinRepository.setCache"]
+	require.NotNil(t, find)
+	require.NotNil(t, set)
+	assert.Contains(t, find.Attributes(), attribute.Bool("cache.hit", false))
+	// the cache is filled after the response, in a trace of its own linked to the request
+	assert.NotEqual(t, find.SpanContext().TraceID(), set.SpanContext().TraceID())
+	require.Len(t, set.Links(), 1)
+	assert.Equal(t, find.SpanContext(), set.Links()[0].SpanContext)
+}
+
+func TestCachedCoinRepository_Spans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	coinDAO := daomocks.NewMockCoinDAO(ctrl)
+	coinCache := cachemocks.NewMockCoinCache(ctrl)
+	coinDAO.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(errors.New("mock db error"))
+	coinDAO.EXPECT().FindRecent(gomock.Any(), 10).Return(nil, nil)
+	coinDAO.EXPECT().FindReactionCounts(gomock.Any(), []int64{}).Return(nil, nil).AnyTimes()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+	repo.tracer = tp.Tracer("test")
+
+	err := repo.Delete(context.Background(), domain.Coin{Id: 1, PublicId: publicId})
+	assert.Equal(t, errors.New("mock db error"), err)
+	_, err = repo.FindRecent(context.Background(), 10)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "CachedCoinRepository.Delete", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("coin.public_id", publicId))
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "CachedCoinRepository.FindRecent", spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), attribute.Int("coin.limit", 10))
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestCachedCoinRepository_FindByPublicIdForUpdate(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	now := time.UnixMilli(nowMs)
 	testCases := []struct {
 		name string
 		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
 
-		id int64
+		publicId string
+
+		wantRet domain.Coin
+		wantErr error
+	}{
+		{
+			name: "find success without touching the cache",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(dao.Coin{
+					Id:        1,
+					PublicId:  sql.NullString{String: publicId, Valid: true},
+					Name:      "test",
+					CreatedAt: nowMs,
+					UpdatedAt: nowMs,
+				}, nil)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantRet: domain.Coin{
+				Id:        1,
+				PublicId:  publicId,
+				Name:      "test",
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+		},
+		{
+			name: "public id not found",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(dao.Coin{}, dao.ErrRecordNotFound)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinDAO, coinCache := tc.mock(ctrl)
+			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+			ret, err := repo.FindByPublicIdForUpdate(context.Background(), tc.publicId)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestCachedCoinRepository_Delete(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
+
+		coin domain.Coin
 
 		wantErr error
 	}{
@@ -336,10 +501,10 @@ func TestCachedCoinRepository_DeleteById(t *testing.T) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
 				coinDAO.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(nil)
-				coinCache.EXPECT().Del(gomock.Any(), int64(1)).Return(nil)
+				coinCache.EXPECT().Del(gomock.Any(), publicId).Return(nil)
 				return coinDAO, coinCache
 			},
-			id:      1,
+			coin:    domain.Coin{Id: 1, PublicId: publicId},
 			wantErr: nil,
 		},
 		{
@@ -348,10 +513,10 @@ func TestCachedCoinRepository_DeleteById(t *testing.T) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
 				coinDAO.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(nil)
-				coinCache.EXPECT().Del(gomock.Any(), int64(1)).Return(errors.New("redis conn error"))
+				coinCache.EXPECT().Del(gomock.Any(), publicId).Return(errors.New("redis conn error"))
 				return coinDAO, coinCache
 			},
-			id:      1,
+			coin:    domain.Coin{Id: 1, PublicId: publicId},
 			wantErr: nil,
 		},
 		{
@@ -362,7 +527,7 @@ func TestCachedCoinRepository_DeleteById(t *testing.T) {
 				coinDAO.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(errors.New("mock db error"))
 				return coinDAO, coinCache
 			},
-			id:      1,
+			coin:    domain.Coin{Id: 1, PublicId: publicId},
 			wantErr: errors.New("mock db error"),
 		},
 	}
@@ -373,54 +538,379 @@ func TestCachedCoinRepository_DeleteById(t *testing.T) {
 			defer ctrl.Finish()
 			coinDAO, coinCache := tc.mock(ctrl)
 			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
-			err := repo.DeleteById(context.Background(), tc.id)
-			time.Sleep(time.Millisecond * 300)
+			err := repo.Delete(context.Background(), tc.coin)
+			repo.Wait()
 			assert.Equal(t, tc.wantErr, err)
 		})
 	}
 }
 
-func TestCachedCoinRepository_IncrPopularityScore(t *testing.T) {
+func TestCachedCoinRepository_AddReaction(t *testing.T) {
 	testCases := []struct {
 		name string
 		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
 
-		id int64
+		publicId string
 
 		wantErr error
 	}{
 		{
-			name: "incr success and delete cache success",
+			name: "id from cache, add success and delete cache success",
 			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
-				coinDAO.EXPECT().IncrPopularityScore(gomock.Any(), int64(1)).Return(nil)
-				coinCache.EXPECT().Del(gomock.Any(), int64(1)).Return(nil)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{Id: 1, PublicId: publicId}, nil)
+				coinDAO.EXPECT().AddReaction(gomock.Any(), int64(1), "fp:1", "poke").Return(nil)
+				coinCache.EXPECT().Del(gomock.Any(), publicId).Return(nil)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  nil,
+		},
+		{
+			name: "id from db without filling the cache",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{}, cache.ErrKeyNotExist)
+				coinDAO.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(dao.Coin{
+					Id:       1,
+					PublicId: sql.NullString{String: publicId, Valid: true},
+				}, nil)
+				coinDAO.EXPECT().AddReaction(gomock.Any(), int64(1), "fp:1", "poke").Return(nil)
+				coinCache.EXPECT().Del(gomock.Any(), publicId).Return(nil)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  nil,
+		},
+		{
+			name: "public id not found",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{}, cache.ErrKeyNotExist)
+				coinDAO.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(dao.Coin{}, dao.ErrRecordNotFound)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  dao.ErrRecordNotFound,
+		},
+		{
+			name: "coin deleted meanwhile",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{Id: 1, PublicId: publicId}, nil)
+				coinDAO.EXPECT().AddReaction(gomock.Any(), int64(1), "fp:1", "poke").Return(dao.ErrRecordNotFound)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  dao.ErrRecordNotFound,
+		},
+		{
+			name: "duplicate reaction",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{Id: 1, PublicId: publicId}, nil)
+				coinDAO.EXPECT().AddReaction(gomock.Any(), int64(1), "fp:1", "poke").Return(dao.ErrDuplicateReaction)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  ErrDuplicateReaction,
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{Id: 1, PublicId: publicId}, nil)
+				coinDAO.EXPECT().AddReaction(gomock.Any(), int64(1), "fp:1", "poke").Return(errors.New("mock db error"))
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinDAO, coinCache := tc.mock(ctrl)
+			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+			err := repo.AddReaction(context.Background(), tc.publicId, "fp:1", domain.ReactionPoke)
+			repo.Wait()
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCachedCoinRepository_RemoveReaction(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
+
+		publicId string
+
+		wantErr error
+	}{
+		{
+			name: "remove success and delete cache success",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{Id: 1, PublicId: publicId}, nil)
+				coinDAO.EXPECT().RemoveReaction(gomock.Any(), int64(1), "fp:1", "rocket").Return(nil)
+				coinCache.EXPECT().Del(gomock.Any(), publicId).Return(nil)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+		},
+		{
+			name: "public id not found",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{}, cache.ErrKeyNotExist)
+				coinDAO.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(dao.Coin{}, dao.ErrRecordNotFound)
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  dao.ErrRecordNotFound,
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().Get(gomock.Any(), publicId).Return(domain.Coin{Id: 1, PublicId: publicId}, nil)
+				coinDAO.EXPECT().RemoveReaction(gomock.Any(), int64(1), "fp:1", "rocket").Return(errors.New("mock db error"))
+				return coinDAO, coinCache
+			},
+			publicId: publicId,
+			wantErr:  errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinDAO, coinCache := tc.mock(ctrl)
+			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+			err := repo.RemoveReaction(context.Background(), tc.publicId, "fp:1", domain.ReactionRocket)
+			repo.Wait()
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCachedCoinRepository_FindTopByPopularity(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	now := time.UnixMilli(nowMs)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
+
+		limit int
+
+		wantRet []domain.Coin
+		wantErr error
+	}{
+		{
+			name: "find success",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindTopByPopularity(gomock.Any(), 10).Return([]dao.Coin{
+					{Id: 1, Name: "test", CreatedAt: nowMs, UpdatedAt: nowMs, PopularityScore: 3},
+				}, nil)
+				coinDAO.EXPECT().FindReactionCounts(gomock.Any(), []int64{1}).Return(nil, nil)
+				return coinDAO, coinCache
+			},
+			limit: 10,
+			wantRet: []domain.Coin{
+				{Id: 1, Name: "test", CreatedAt: now, UpdatedAt: now, PopularityScore: 3},
+			},
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindTopByPopularity(gomock.Any(), 10).Return(nil, errors.New("mock db error"))
+				return coinDAO, coinCache
+			},
+			limit:   10,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinDAO, coinCache := tc.mock(ctrl)
+			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+			ret, err := repo.FindTopByPopularity(context.Background(), tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestCachedCoinRepository_FindTopByHotScore(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	now := time.UnixMilli(nowMs)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
+
+		limit int
+
+		wantRet []domain.Coin
+		wantErr error
+	}{
+		{
+			name: "find success",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindTopByHotScore(gomock.Any(), 10).Return([]dao.Coin{
+					{Id: 1, Name: "test", CreatedAt: nowMs, UpdatedAt: nowMs, PopularityScore: 3, HotScore: 2.5, HotScoreAt: nowMs},
+				}, nil)
+				coinDAO.EXPECT().FindReactionCounts(gomock.Any(), []int64{1}).Return(nil, nil)
+				return coinDAO, coinCache
+			},
+			limit: 10,
+			wantRet: []domain.Coin{
+				{Id: 1, Name: "test", CreatedAt: now, UpdatedAt: now, PopularityScore: 3, HotScore: 2.5},
+			},
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindTopByHotScore(gomock.Any(), 10).Return(nil, errors.New("mock db error"))
+				return coinDAO, coinCache
+			},
+			limit:   10,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinDAO, coinCache := tc.mock(ctrl)
+			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+			ret, err := repo.FindTopByHotScore(context.Background(), tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestCachedCoinRepository_Preload(t *testing.T) {
+	coins := []domain.Coin{{Id: 1}, {Id: 2}}
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
+
+		wantErr error
+	}{
+		{
+			name: "preload success",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinCache.EXPECT().SetMulti(gomock.Any(), coins).Return(nil)
 				return coinDAO, coinCache
 			},
-			id:      1,
-			wantErr: nil,
 		},
 		{
-			name: "0 row affected",
+			name: "cache error",
 			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
-				coinDAO.EXPECT().IncrPopularityScore(gomock.Any(), int64(1)).Return(dao.ErrRecordNotFound)
+				coinCache.EXPECT().SetMulti(gomock.Any(), coins).Return(errors.New("redis conn error"))
 				return coinDAO, coinCache
 			},
-			id:      1,
-			wantErr: dao.ErrRecordNotFound,
+			wantErr: errors.New("redis conn error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinDAO, coinCache := tc.mock(ctrl)
+			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+			err := repo.Preload(context.Background(), coins)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCachedCoinRepository_FindRevisions(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	now := time.UnixMilli(nowMs)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
+
+		wantRet []domain.CoinRevision
+		wantErr error
+	}{
+		{
+			name: "find success",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindRevisions(gomock.Any(), publicId, int64(1), 10).Return([]dao.CoinRevision{
+					{
+						Id:              7,
+						CoinId:          1,
+						PublicId:        sql.NullString{String: publicId, Valid: true},
+						Revision:        2,
+						Action:          dao.RevisionActionPoke,
+						Name:            "test",
+						CreatedAt:       nowMs,
+						UpdatedAt:       nowMs,
+						PopularityScore: 1,
+						ValidFrom:       nowMs,
+					},
+				}, nil)
+				return coinDAO, coinCache
+			},
+			wantRet: []domain.CoinRevision{
+				{
+					Revision: 2,
+					Action:   domain.RevisionActionPoke,
+					Coin: domain.Coin{
+						Id:              1,
+						PublicId:        publicId,
+						Name:            "test",
+						CreatedAt:       now,
+						UpdatedAt:       now,
+						PopularityScore: 1,
+					},
+					ValidFrom: now,
+				},
+			},
 		},
 		{
 			name: "db error",
 			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
 				coinDAO := daomocks.NewMockCoinDAO(ctrl)
 				coinCache := cachemocks.NewMockCoinCache(ctrl)
-				coinDAO.EXPECT().IncrPopularityScore(gomock.Any(), int64(1)).Return(errors.New("mock db error"))
+				coinDAO.EXPECT().FindRevisions(gomock.Any(), publicId, int64(1), 10).Return(nil, errors.New("mock db error"))
 				return coinDAO, coinCache
 			},
-			id:      1,
 			wantErr: errors.New("mock db error"),
 		},
 	}
@@ -431,9 +921,77 @@ func TestCachedCoinRepository_IncrPopularityScore(t *testing.T) {
 			defer ctrl.Finish()
 			coinDAO, coinCache := tc.mock(ctrl)
 			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
-			err := repo.IncrPopularityScore(context.Background(), tc.id)
-			time.Sleep(time.Millisecond * 300)
+			ret, err := repo.FindRevisions(context.Background(), publicId, 1, 10)
 			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestCachedCoinRepository_FindRevisionAsOf(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	now := time.UnixMilli(nowMs)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (dao.CoinDAO, cache.CoinCache)
+
+		wantRet domain.CoinRevision
+		wantErr error
+	}{
+		{
+			name: "find success",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindRevisionAsOf(gomock.Any(), publicId, nowMs).Return(dao.CoinRevision{
+					Id:          7,
+					CoinId:      1,
+					PublicId:    sql.NullString{String: publicId, Valid: true},
+					Revision:    1,
+					Action:      dao.RevisionActionCreate,
+					Name:        "test",
+					Description: sql.NullString{String: "test description", Valid: true},
+					CreatedAt:   nowMs,
+					UpdatedAt:   nowMs,
+					ValidFrom:   nowMs,
+				}, nil)
+				return coinDAO, coinCache
+			},
+			wantRet: domain.CoinRevision{
+				Revision: 1,
+				Action:   domain.RevisionActionCreate,
+				Coin: domain.Coin{
+					Id:          1,
+					PublicId:    publicId,
+					Name:        "test",
+					Description: "test description",
+					CreatedAt:   now,
+					UpdatedAt:   now,
+				},
+				ValidFrom: now,
+			},
+		},
+		{
+			name: "not found",
+			mock: func(ctrl *gomock.Controller) (dao.CoinDAO, cache.CoinCache) {
+				coinDAO := daomocks.NewMockCoinDAO(ctrl)
+				coinCache := cachemocks.NewMockCoinCache(ctrl)
+				coinDAO.EXPECT().FindRevisionAsOf(gomock.Any(), publicId, nowMs).Return(dao.CoinRevision{}, dao.ErrRecordNotFound)
+				return coinDAO, coinCache
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinDAO, coinCache := tc.mock(ctrl)
+			repo := NewCachedCoinRepository(coinDAO, coinCache, logger.NewNopLogger())
+			ret, err := repo.FindRevisionAsOf(context.Background(), publicId, now)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
 		})
 	}
 }
@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"gorm.io/gorm"
+	"time"
+)
+
+//go:generate mockgen -source=./audit.go -package=daomocks -destination=./mocks/audit.mock.go CoinAuditDAO
+type CoinAuditDAO interface {
+	Insert(ctx context.Context, a CoinAudit) error
+}
+
+type GormCoinAuditDAO struct {
+	db *gorm.DB
+}
+
+func NewGormCoinAuditDAO(db *gorm.DB) CoinAuditDAO {
+	return &GormCoinAuditDAO{
+		db: db,
+	}
+}
+
+func (dao *GormCoinAuditDAO) Insert(ctx context.Context, a CoinAudit) error {
+	a.CreatedAt = time.Now().UnixMilli()
+	db, _ := dbFromContext(ctx, dao.db)
+	return db.Create(&a).Error
+}
+
+// CoinAudit is a change made to a coin, OldValue and NewValue are json snapshots.
+type CoinAudit struct {
+	Id        int64  `gorm:"primaryKey;autoIncrement"`
+	CoinId    int64  `gorm:"not null;index:idx_coin_audits_coin_id,priority:1"`
+	Action    string `gorm:"type:varchar(32);not null"`
+	OldValue  sql.NullString
+	NewValue  sql.NullString
+	CreatedAt int64 `gorm:"not null"`
+}
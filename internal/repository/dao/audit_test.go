@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGormCoinAuditDAO_Insert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		audit CoinAudit
+
+		wantErr error
+	}{
+		{
+			name: "insert success",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("INSERT INTO `coin_audits` .*").
+					WithArgs(1, "update", `{"id":1}`, `{"id":1}`, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				return db
+			},
+			audit: CoinAudit{
+				CoinId:   1,
+				Action:   "update",
+				OldValue: sql.NullString{String: `{"id":1}`, Valid: true},
+				NewValue: sql.NullString{String: `{"id":1}`, Valid: true},
+			},
+		},
+		{
+			name: "insert failed",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("INSERT INTO `coin_audits` .*").
+					WillReturnError(errors.New("mock db error"))
+				return db
+			},
+			audit:   CoinAudit{CoinId: 1, Action: "delete"},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGormCoinAuditDAO(db)
+			err = dao.Insert(context.Background(), tc.audit)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
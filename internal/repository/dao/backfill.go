@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+// BackfillPublicIds gives a public id to the coins of db created before public ids existed,
+// batch by batch, and returns how many it gave. newId receives the creation time of the coin
+// so the public ids keep sorting by creation. Instances running it together do not overwrite
+// each other.
+func BackfillPublicIds(ctx context.Context, db *gorm.DB, newId func(createdAt time.Time) string, batch int) (int, error) {
+	total := 0
+	for {
+		var coins []Coin
+		err := db.WithContext(ctx).Select("id", "created_at").
+			Where("public_id IS NULL").Order("id").Limit(batch).Find(&coins).Error
+		if err != nil {
+			return total, err
+		}
+		for _, c := range coins {
+			publicId := newId(time.UnixMilli(c.CreatedAt))
+			res := db.WithContext(ctx).Model(&Coin{}).
+				Where("id = ? AND public_id IS NULL", c.Id).
+				UpdateColumn("public_id", publicId)
+			if res.Error != nil {
+				return total, res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			// the history recorded before the backfill is found by the public id as well
+			err = db.WithContext(ctx).Model(&CoinRevision{}).
+				Where("coin_id = ? AND public_id IS NULL", c.Id).
+				UpdateColumn("public_id", publicId).Error
+			if err != nil {
+				return total, err
+			}
+			total++
+		}
+		if len(coins) < batch {
+			return total, nil
+		}
+	}
+}
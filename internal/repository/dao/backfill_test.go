@@ -0,0 +1,47 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBackfillPublicIds(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, "primary")
+	// coins created before public ids existed
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, db.Create(&Coin{Id: int64(i), Name: fmt.Sprintf("coin-%d", i), CreatedAt: int64(i)}).Error)
+	}
+	require.NoError(t, db.Create(&CoinRevision{CoinId: 1, Revision: 1, Action: RevisionActionSnapshot, Name: "coin-1"}).Error)
+	require.NoError(t, db.Create(&Coin{
+		Id:       6,
+		Name:     "coin-6",
+		PublicId: sql.NullString{String: "kept", Valid: true},
+	}).Error)
+
+	newId := func(createdAt time.Time) string {
+		return fmt.Sprintf("id-%d", createdAt.UnixMilli())
+	}
+	cnt, err := BackfillPublicIds(ctx, db, newId, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, cnt)
+
+	var coins []Coin
+	require.NoError(t, db.Order("id").Find(&coins).Error)
+	for _, c := range coins[:5] {
+		assert.Equal(t, fmt.Sprintf("id-%d", c.CreatedAt), c.PublicId.String)
+	}
+	assert.Equal(t, "kept", coins[5].PublicId.String)
+	var rev CoinRevision
+	require.NoError(t, db.Where("coin_id = ?", 1).First(&rev).Error)
+	assert.Equal(t, coins[0].PublicId, rev.PublicId)
+
+	cnt, err = BackfillPublicIds(ctx, db, newId, 2)
+	require.NoError(t, err)
+	assert.Zero(t, cnt)
+}
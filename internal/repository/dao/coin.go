@@ -4,14 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"github.com/go-sql-driver/mysql"
+	"github.com/miles0wu/meme-coin-api/pkg/hotscore"
 	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/miles0wu/meme-coin-api/pkg/readreplica"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
 var (
-	ErrDuplicateName  = errors.New("duplicate name")
+	ErrDuplicateName = errors.New("duplicate name")
+	// ErrDuplicateId is returned when the id of a new coin is taken, the id generators of two instances share a node.
+	ErrDuplicateId    = errors.New("duplicate id")
 	ErrRecordNotFound = gorm.ErrRecordNotFound
 )
 
@@ -20,72 +28,266 @@ type CoinDAO interface {
 	Insert(ctx context.Context, c Coin) (Coin, error)
 	UpdateById(ctx context.Context, entity Coin) error
 	FindById(ctx context.Context, uid int64) (Coin, error)
+	// FindByIdForUpdate locks the row until the transaction of ctx ends, see Transactor.
+	FindByIdForUpdate(ctx context.Context, id int64) (Coin, error)
+	FindByPublicId(ctx context.Context, publicId string) (Coin, error)
+	// FindByPublicIdForUpdate locks the row until the transaction of ctx ends, see Transactor.
+	FindByPublicIdForUpdate(ctx context.Context, publicId string) (Coin, error)
 	DeleteById(ctx context.Context, uid int64) error
-	IncrPopularityScore(ctx context.Context, id int64) error
+	// AddReaction adds the reaction of the actor to the coin, a poke also raises the scores of the coin.
+	AddReaction(ctx context.Context, id int64, actor, reaction string) error
+	// RemoveReaction withdraws the reaction of the actor from the coin, and the poke from its scores.
+	RemoveReaction(ctx context.Context, id int64, actor, reaction string) error
+	// FindReactionCounts returns the non-zero numbers of reactions of each type to the coins.
+	FindReactionCounts(ctx context.Context, ids []int64) ([]CoinReactionCount, error)
+	FindTopByPopularity(ctx context.Context, limit int) ([]Coin, error)
+	FindTopByHotScore(ctx context.Context, limit int) ([]Coin, error)
+	FindRecent(ctx context.Context, limit int) ([]Coin, error)
+	// FindRevisions returns the first limit revisions of the coin following the revision after,
+	// the revisions of a deleted coin are kept.
+	FindRevisions(ctx context.Context, publicId string, after int64, limit int) ([]CoinRevision, error)
+	// FindRevisionAsOf returns the revision of the coin valid at the unix milli at.
+	FindRevisionAsOf(ctx context.Context, publicId string, at int64) (CoinRevision, error)
+	// RefreshHotScores decays the hot score of every coin to the unix milli now, batchSize coins at a time,
+	// and returns the number of coins updated.
+	RefreshHotScores(ctx context.Context, now int64, batchSize int) (int64, error)
+}
+
+// IdGenerator issues coin ids unique across all shards, see pkg/snowflake.
+type IdGenerator interface {
+	NextId() int64
 }
 
 type GormCoinDAO struct {
-	db *gorm.DB
-	l  logger.Logger
+	db  *gorm.DB
+	ids IdGenerator
+	hot hotscore.Model
+	l   logger.Logger
 }
 
-func NewGormCoinDAO(db *gorm.DB, l logger.Logger) CoinDAO {
+func NewGormCoinDAO(db *gorm.DB, ids IdGenerator, hot hotscore.Model, l logger.Logger) CoinDAO {
 	return &GormCoinDAO{
-		db: db,
-		l:  l,
+		db:  db,
+		ids: ids,
+		hot: hot,
+		l:   l,
 	}
 }
 
+// Insert generates the id of c unless it is set already.
+// The writes of GormCoinDAO record a revision of the coin in the same transaction.
 func (dao *GormCoinDAO) Insert(ctx context.Context, c Coin) (Coin, error) {
+	if c.Id == 0 {
+		c.Id = dao.ids.NextId()
+	}
 	now := time.Now().UnixMilli()
 	c.CreatedAt = now
 	c.UpdatedAt = now
-	err := dao.db.WithContext(ctx).Create(&c).Error
-	if me, ok := err.(*mysql.MySQLError); ok {
-		const duplicateErr uint16 = 1062
-		if me.Number == duplicateErr {
-			return Coin{}, ErrDuplicateName
+	c.HotScoreAt = now
+	err := inTx(ctx, func(ctx context.Context) error {
+		if err := translateError(dao.writer(ctx).Create(&c).Error); err != nil {
+			return err
 		}
+		return dao.recordRevision(ctx, c.Id, RevisionActionCreate, now)
+	})
+	if err != nil {
+		return Coin{}, err
 	}
-
-	return c, err
+	return c, nil
 }
 
 func (dao *GormCoinDAO) UpdateById(ctx context.Context, entity Coin) error {
-	return dao.db.WithContext(ctx).Model(&entity).Where("id = ?", entity.Id).
-		Updates(map[string]any{
-			"updated_at":  time.Now().UnixMilli(),
-			"description": entity.Description,
-		}).Error
+	now := time.Now().UnixMilli()
+	return inTx(ctx, func(ctx context.Context) error {
+		err := dao.writer(ctx).Model(&entity).Where("id = ?", entity.Id).
+			Updates(map[string]any{
+				"updated_at":  now,
+				"description": entity.Description,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return dao.recordRevision(ctx, entity.Id, RevisionActionUpdate, now)
+	})
 }
 
 func (dao *GormCoinDAO) FindById(ctx context.Context, id int64) (Coin, error) {
 	var res Coin
-	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
-	return res, err
+	err := dao.reader(ctx).Where("id = ?", id).First(&res).Error
+	return res, translateError(err)
+}
+
+func (dao *GormCoinDAO) FindByIdForUpdate(ctx context.Context, id int64) (Coin, error) {
+	var res Coin
+	err := dao.writer(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&res).Error
+	return res, translateError(err)
 }
 
+func (dao *GormCoinDAO) FindByPublicId(ctx context.Context, publicId string) (Coin, error) {
+	var res Coin
+	err := dao.reader(ctx).Where("public_id = ?", publicId).First(&res).Error
+	return res, translateError(err)
+}
+
+func (dao *GormCoinDAO) FindByPublicIdForUpdate(ctx context.Context, publicId string) (Coin, error) {
+	var res Coin
+	err := dao.writer(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("public_id = ?", publicId).First(&res).Error
+	return res, translateError(err)
+}
+
+// DeleteById records the last state of the coin as its delete revision before deleting it
+// along with its reactions.
 func (dao *GormCoinDAO) DeleteById(ctx context.Context, id int64) error {
-	return dao.db.WithContext(ctx).Where("id = ?", id).Delete(&Coin{}).Error
+	return inTx(ctx, func(ctx context.Context) error {
+		err := dao.recordRevision(ctx, id, RevisionActionDelete, time.Now().UnixMilli())
+		if err != nil {
+			return err
+		}
+		if err = dao.writer(ctx).Where("id = ?", id).Delete(&Coin{}).Error; err != nil {
+			return err
+		}
+		return dao.deleteReactions(ctx, id)
+	})
+}
+
+func (dao *GormCoinDAO) FindTopByPopularity(ctx context.Context, limit int) ([]Coin, error) {
+	var res []Coin
+	err := dao.reader(ctx).
+		Order("popularity_score DESC").Order("id DESC").
+		Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GormCoinDAO) FindTopByHotScore(ctx context.Context, limit int) ([]Coin, error) {
+	var res []Coin
+	err := dao.reader(ctx).
+		Order("hot_score DESC").Order("id DESC").
+		Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GormCoinDAO) FindRecent(ctx context.Context, limit int) ([]Coin, error) {
+	var res []Coin
+	err := dao.reader(ctx).
+		Order("created_at DESC").Order("id DESC").
+		Limit(limit).Find(&res).Error
+	return res, err
 }
 
-func (dao *GormCoinDAO) IncrPopularityScore(ctx context.Context, id int64) error {
-	res := dao.db.WithContext(ctx).Model(&Coin{}).Where("id = ?", id).
-		Updates(map[string]any{
-			"popularity_score": gorm.Expr("popularity_score + ?", 1),
-			"updated_at":       time.Now().UnixMilli(),
+// The refresh skips the coins whose score changed by less than hotScoreMinChange, their score
+// catches up once the change adds up, and zeroes the scores below minHotScore.
+const (
+	hotScoreMinChange = 0.01
+	minHotScore       = 1e-6
+)
+
+// RefreshHotScores reads the primary and skips the coins poked since they were read,
+// their hot score is already up to date. The refresh is not a change of the coin, updated_at is left as is.
+// Only the coins with a hot score are read, and each batch is written by a single statement.
+func (dao *GormCoinDAO) RefreshHotScores(ctx context.Context, now int64, batchSize int) (int64, error) {
+	db := dao.db.WithContext(ctx).Clauses(dbresolver.Write).Session(&gorm.Session{})
+	var updated int64
+	var lastId int64
+	for {
+		var batch []Coin
+		err := db.Select("id", "popularity_score", "created_at", "hot_score", "hot_score_at").
+			Where("id > ? AND hot_score > 0", lastId).Order("id").Limit(batchSize).Find(&batch).Error
+		if err != nil {
+			return updated, err
+		}
+		scores := make(map[int64]float64, len(batch))
+		for _, c := range batch {
+			if c.HotScoreAt >= now {
+				continue
+			}
+			score := dao.hot.Decay(c.hotItem(), time.UnixMilli(now))
+			if score < minHotScore {
+				score = 0
+			} else if math.Abs(score-c.HotScore) < hotScoreMinChange*c.HotScore {
+				continue
+			}
+			scores[c.Id] = score
+		}
+		n, err := dao.updateHotScores(db, batch, scores, now)
+		updated += n
+		if err != nil {
+			return updated, err
+		}
+		if len(batch) < batchSize {
+			return updated, nil
+		}
+		lastId = batch[len(batch)-1].Id
+	}
+}
+
+// updateHotScores writes the scores of the coins of batch found in scores, unless the coin was poked since it was read.
+func (dao *GormCoinDAO) updateHotScores(db *gorm.DB, batch []Coin, scores map[int64]float64, now int64) (int64, error) {
+	if len(scores) == 0 {
+		return 0, nil
+	}
+	// the scores are inlined, postgres would type the parameters of the CASE as text
+	var cases strings.Builder
+	cases.WriteString("CASE id")
+	conds := make([]string, 0, len(scores))
+	args := make([]any, 0, 2*len(scores))
+	for _, c := range batch {
+		score, ok := scores[c.Id]
+		if !ok {
+			continue
+		}
+		cases.WriteString(" WHEN " + strconv.FormatInt(c.Id, 10) + " THEN " + strconv.FormatFloat(score, 'g', -1, 64))
+		conds = append(conds, "(id = ? AND hot_score_at = ?)")
+		args = append(args, c.Id, c.HotScoreAt)
+	}
+	cases.WriteString(" END")
+	res := db.Model(&Coin{}).Where(strings.Join(conds, " OR "), args...).
+		UpdateColumns(map[string]any{
+			"hot_score":    gorm.Expr(cases.String()),
+			"hot_score_at": now,
 		})
-	if res.Error == nil && res.RowsAffected == 0 {
-		return ErrRecordNotFound
+	return res.RowsAffected, res.Error
+}
+
+// reader returns the db for a read, it goes to a replica unless it runs in a transaction
+// or the request already wrote and must observe its own writes on the primary.
+func (dao *GormCoinDAO) reader(ctx context.Context) *gorm.DB {
+	db, inTx := dbFromContext(ctx, dao.db)
+	if !inTx && readreplica.UsePrimary(ctx) {
+		return db.Clauses(dbresolver.Write)
 	}
-	return res.Error
+	return db
 }
 
+// writer returns the db for a write and pins the following reads of the request to the primary.
+// The write is recorded up front since a failed one may still have been committed.
+func (dao *GormCoinDAO) writer(ctx context.Context) *gorm.DB {
+	readreplica.MarkWritten(ctx)
+	db, _ := dbFromContext(ctx, dao.db)
+	return db
+}
+
+// Coin maps the coins table, its schema is owned by the migrations
+// and the tags only document it.
 type Coin struct {
-	Id              int64          `gorm:"primaryKey,autoIncrement"`
-	Name            string         `gorm:"unique,type:varchar(255)"`
-	Description     sql.NullString `gorm:"type=varchar(128)"`
-	CreatedAt       int64
+	Id              int64          `gorm:"primaryKey;autoIncrement:false"`
+	PublicId        sql.NullString `gorm:"type:char(26);uniqueIndex:uniq_coins_public_id"`
+	Name            string         `gorm:"type:varchar(255);not null;uniqueIndex:uniq_coins_name"`
+	Description     sql.NullString `gorm:"type:varchar(128)"`
+	CreatedAt       int64          `gorm:"index:idx_coins_created_at"`
 	UpdatedAt       int64
-	PopularityScore uint32 `gorm:"default:0"`
+	PopularityScore uint32 `gorm:"not null;default:0;index:idx_coins_popularity_score"`
+	// HotScore is the popularity decayed to the unix milli HotScoreAt, see pkg/hotscore.
+	HotScore   float64 `gorm:"not null;default:0;index:idx_coins_hot_score"`
+	HotScoreAt int64   `gorm:"not null;default:0"`
+}
+
+func (c Coin) hotItem() hotscore.Item {
+	return hotscore.Item{
+		Score:     c.HotScore,
+		ScoredAt:  time.UnixMilli(c.HotScoreAt),
+		CreatedAt: time.UnixMilli(c.CreatedAt),
+		Pokes:     int64(c.PopularityScore),
+	}
 }
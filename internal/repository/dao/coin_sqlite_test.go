@@ -0,0 +1,394 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao/migrations"
+	"github.com/miles0wu/meme-coin-api/pkg/hotscore"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/miles0wu/meme-coin-api/pkg/migrator"
+	"github.com/miles0wu/meme-coin-api/pkg/readreplica"
+	"github.com/miles0wu/meme-coin-api/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+	"math"
+	"testing"
+	"time"
+)
+
+// newSQLiteDB opens an in-memory database migrated with the sqlite migrations,
+// so the DAO runs against a real engine and its constraints.
+func newSQLiteDB(t *testing.T, name string) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s_%s?mode=memory&cache=shared", t.Name(), name)
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = sqlDB.Close()
+	})
+
+	fsys, err := migrations.For(db.Dialector.Name())
+	require.NoError(t, err)
+	ms, err := migrator.Load(fsys)
+	require.NoError(t, err)
+	_, err = migrator.New(db, ms, logger.NewNopLogger()).Up(context.Background())
+	require.NoError(t, err)
+	return db
+}
+
+func newIdGenerator(t *testing.T) IdGenerator {
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	return node
+}
+
+func newHotScoreModel() hotscore.Model {
+	return hotscore.NewExponential(24 * time.Hour)
+}
+
+// seedCoins stores doge with id 1 and pepe with id 2, pepe being the most popular.
+func seedCoins(t *testing.T, db *gorm.DB) {
+	require.NoError(t, db.Create(&[]Coin{
+		{Id: 1, Name: "doge", Description: sql.NullString{String: "much wow", Valid: true}, CreatedAt: 1, PopularityScore: 1},
+		{Id: 2, Name: "pepe", CreatedAt: 2, PopularityScore: 2},
+	}).Error)
+}
+
+func TestGormCoinDAO_SQLiteInsert(t *testing.T) {
+	testCases := []struct {
+		name string
+		coin Coin
+
+		wantErr error
+	}{
+		{
+			name: "insert success",
+			coin: Coin{
+				Name:        "shib",
+				Description: sql.NullString{String: "test description", Valid: true},
+			},
+		},
+		{
+			name:    "insert failed - duplicate name",
+			coin:    Coin{Name: "doge"},
+			wantErr: ErrDuplicateName,
+		},
+		{
+			name:    "insert failed - duplicate id",
+			coin:    Coin{Id: 1, Name: "shib"},
+			wantErr: ErrDuplicateId,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newSQLiteDB(t, "primary")
+			seedCoins(t, db)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+			c, err := dao.Insert(ctx, tc.coin)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			found, err := dao.FindById(ctx, c.Id)
+			require.NoError(t, err)
+			assert.Equal(t, c, found)
+		})
+	}
+}
+
+func TestGormCoinDAO_SQLiteUpdateById(t *testing.T) {
+	testCases := []struct {
+		name string
+		coin Coin
+
+		wantDesc string
+	}{
+		{
+			name:     "update success",
+			coin:     Coin{Id: 1, Description: sql.NullString{String: "to the moon", Valid: true}},
+			wantDesc: "to the moon",
+		},
+		{
+			name:     "update but no affected",
+			coin:     Coin{Id: 404, Description: sql.NullString{String: "to the moon", Valid: true}},
+			wantDesc: "much wow",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newSQLiteDB(t, "primary")
+			seedCoins(t, db)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+			require.NoError(t, dao.UpdateById(ctx, tc.coin))
+			found, err := dao.FindById(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantDesc, found.Description.String)
+		})
+	}
+}
+
+func TestGormCoinDAO_SQLiteFindById(t *testing.T) {
+	testCases := []struct {
+		name string
+		id   int64
+
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			id:       1,
+			wantName: "doge",
+		},
+		{
+			name:    "id not found",
+			id:      404,
+			wantErr: ErrRecordNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newSQLiteDB(t, "primary")
+			seedCoins(t, db)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+			found, err := dao.FindById(context.Background(), tc.id)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantName, found.Name)
+		})
+	}
+}
+
+func TestGormCoinDAO_SQLiteDeleteById(t *testing.T) {
+	testCases := []struct {
+		name string
+		id   int64
+
+		wantIds []int64
+	}{
+		{
+			name:    "delete success",
+			id:      1,
+			wantIds: []int64{2},
+		},
+		{
+			name:    "delete but no affected",
+			id:      404,
+			wantIds: []int64{1, 2},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newSQLiteDB(t, "primary")
+			seedCoins(t, db)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			require.NoError(t, dao.AddReaction(ctx, 1, "fp:1", ReactionPoke))
+
+			require.NoError(t, dao.DeleteById(ctx, tc.id))
+			var ids []int64
+			require.NoError(t, db.Model(&Coin{}).Order("id").Pluck("id", &ids).Error)
+			assert.Equal(t, tc.wantIds, ids)
+			// the reactions are deleted along with the coin
+			counts, err := dao.FindReactionCounts(ctx, []int64{tc.id})
+			require.NoError(t, err)
+			assert.Empty(t, counts)
+		})
+	}
+}
+
+func TestGormCoinDAO_SQLiteAddReaction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		before   func(t *testing.T, dao CoinDAO, db *gorm.DB)
+		id       int64
+		reaction string
+
+		wantScore uint32
+		wantErr   error
+	}{
+		{
+			name:      "poke success",
+			id:        1,
+			reaction:  ReactionPoke,
+			wantScore: 2,
+		},
+		{
+			name: "popularity score saturated",
+			before: func(t *testing.T, dao CoinDAO, db *gorm.DB) {
+				require.NoError(t, db.Model(&Coin{}).Where("id = ?", 1).
+					Update("popularity_score", uint32(math.MaxUint32)).Error)
+			},
+			id:        1,
+			reaction:  ReactionPoke,
+			wantScore: math.MaxUint32,
+		},
+		{
+			name:      "other reactions leave the scores alone",
+			id:        1,
+			reaction:  "rocket",
+			wantScore: 1,
+		},
+		{
+			name: "duplicate reaction",
+			before: func(t *testing.T, dao CoinDAO, db *gorm.DB) {
+				require.NoError(t, dao.AddReaction(context.Background(), 1, "fp:1", ReactionPoke))
+			},
+			id:        1,
+			reaction:  ReactionPoke,
+			wantScore: 2,
+			wantErr:   ErrDuplicateReaction,
+		},
+		{
+			name:     "coin not found",
+			id:       404,
+			reaction: ReactionPoke,
+			wantErr:  ErrRecordNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newSQLiteDB(t, "primary")
+			seedCoins(t, db)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			if tc.before != nil {
+				tc.before(t, dao, db)
+			}
+
+			err := dao.AddReaction(ctx, tc.id, "fp:1", tc.reaction)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantScore == 0 {
+				return
+			}
+			found, err := dao.FindById(ctx, tc.id)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantScore, found.PopularityScore)
+		})
+	}
+}
+
+func TestGormCoinDAO_SQLiteFindTopAndRecent(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, "primary")
+	dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+	recent, err := dao.FindRecent(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, recent)
+
+	seedCoins(t, db)
+	top, err := dao.FindTopByPopularity(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, "pepe", top[0].Name)
+
+	recent, err = dao.FindRecent(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, []string{"pepe", "doge"}, []string{recent[0].Name, recent[1].Name})
+}
+
+func TestGormCoinDAO_HotScore(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, "primary")
+	dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+	doge, err := dao.Insert(ctx, Coin{Name: "doge"})
+	require.NoError(t, err)
+	pepe, err := dao.Insert(ctx, Coin{Name: "pepe"})
+	require.NoError(t, err)
+	shib, err := dao.Insert(ctx, Coin{Name: "shib"})
+	require.NoError(t, err)
+	// doge was poked three times a day ago, pepe twice just now
+	dayAgo := time.Now().Add(-24 * time.Hour).UnixMilli()
+	require.NoError(t, db.Model(&Coin{}).Where("id = ?", doge.Id).
+		Updates(map[string]any{"popularity_score": 3, "hot_score": 3, "hot_score_at": dayAgo}).Error)
+	require.NoError(t, dao.AddReaction(ctx, pepe.Id, "fp:1", ReactionPoke))
+	require.NoError(t, dao.AddReaction(ctx, pepe.Id, "fp:2", ReactionPoke))
+
+	found, err := dao.FindById(ctx, pepe.Id)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), found.PopularityScore)
+	assert.InDelta(t, 2, found.HotScore, 1e-3)
+
+	top, err := dao.FindTopByPopularity(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, doge.Id, top[0].Id)
+	// the hot score holds until the refresh, doge still ranks first
+	hot, err := dao.FindTopByHotScore(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, hot, 3)
+	assert.Equal(t, []int64{doge.Id, pepe.Id, shib.Id}, []int64{hot[0].Id, hot[1].Id, hot[2].Id})
+
+	// doge decays by half, pepe barely and is skipped until its change adds up, shib never poked is not read
+	n, err := dao.RefreshHotScores(ctx, time.Now().Add(time.Second).UnixMilli(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	hot, err = dao.FindTopByHotScore(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{pepe.Id, doge.Id, shib.Id}, []int64{hot[0].Id, hot[1].Id, hot[2].Id})
+	assert.InDelta(t, 1.5, hot[1].HotScore, 1e-3)
+	assert.InDelta(t, 2, hot[0].HotScore, 1e-3)
+	assert.Zero(t, hot[2].HotScore)
+
+	// a year later doge has decayed below the minimum score and is zeroed, pepe follows
+	n, err = dao.RefreshHotScores(ctx, time.Now().Add(365*24*time.Hour).UnixMilli(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	hot, err = dao.FindTopByHotScore(ctx, 3)
+	require.NoError(t, err)
+	for _, c := range hot {
+		assert.Zero(t, c.HotScore)
+	}
+}
+
+func TestGormCoinDAO_ReadReplica(t *testing.T) {
+	ctx := context.Background()
+	primary := newSQLiteDB(t, "primary")
+	replica := newSQLiteDB(t, "replica")
+	primaryDB, err := primary.DB()
+	require.NoError(t, err)
+	replicaDB, err := replica.DB()
+	require.NoError(t, err)
+
+	policy := readreplica.NewPolicy([]readreplica.Replica{{Name: "replica", DB: replicaDB}},
+		nil, readreplica.Config{}, logger.NewNopLogger())
+	policy.Refresh(ctx)
+	err = primary.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{&sqlite.Dialector{Conn: replicaDB}, &sqlite.Dialector{Conn: primaryDB}},
+		Policy:   policy,
+	}))
+	require.NoError(t, err)
+	dao := NewGormCoinDAO(primary, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+	// the replica has not caught up with the insert
+	doge, err := dao.Insert(ctx, Coin{Name: "doge"})
+	require.NoError(t, err)
+	_, err = dao.FindById(ctx, doge.Id)
+	assert.Equal(t, ErrRecordNotFound, err)
+
+	// reads after a write of the same session go to the primary
+	sess := readreplica.NewSession(ctx)
+	pepe, err := dao.Insert(sess, Coin{Name: "pepe"})
+	require.NoError(t, err)
+	found, err := dao.FindById(sess, pepe.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "pepe", found.Name)
+
+	// reads fall back to the primary once the replica is excluded
+	require.NoError(t, replicaDB.Close())
+	policy.Refresh(ctx)
+	found, err = dao.FindById(ctx, doge.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "doge", found.Name)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"math"
 	"regexp"
 	"testing"
 	"time"
@@ -30,9 +31,13 @@ func TestGormCoinDAO_Insert(t *testing.T) {
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
 				mockRes := sqlmock.NewResult(1, 1)
 				mock.ExpectExec("INSERT INTO `coins` .*").
 					WillReturnResult(mockRes)
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 				return db
 			},
 			ctx: context.Background(),
@@ -49,20 +54,41 @@ func TestGormCoinDAO_Insert(t *testing.T) {
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO `coins` .*").
 					WillReturnError(&mysqlDriver.MySQLError{Number: 1062})
+				mock.ExpectRollback()
 				return db
 			},
 			ctx:     context.Background(),
 			wantErr: ErrDuplicateName,
 		},
+		{
+			name: "insert failed - duplicate id",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `coins` .*").
+					WillReturnError(&mysqlDriver.MySQLError{
+						Number:  1062,
+						Message: "Duplicate entry '1' for key 'coins.PRIMARY'",
+					})
+				mock.ExpectRollback()
+				return db
+			},
+			ctx:     context.Background(),
+			wantErr: ErrDuplicateId,
+		},
 		{
 			name: "insert failed",
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO `coins` .*").
 					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
 				return db
 			},
 			ctx:     context.Background(),
@@ -81,7 +107,7 @@ func TestGormCoinDAO_Insert(t *testing.T) {
 				SkipDefaultTransaction: true,
 			})
 			assert.NoError(t, err)
-			dao := NewGormCoinDAO(db, logger.NewNopLogger())
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
 			ret, err := dao.Insert(tc.ctx, tc.coin)
 			assert.Equal(t, tc.wantErr, err)
 			if err != nil {
@@ -112,9 +138,13 @@ func TestGormCoinDAO_UpdateById(t *testing.T) {
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
 				mockRes := sqlmock.NewResult(0, 1)
 				mock.ExpectExec("UPDATE `coins` .*").
 					WillReturnResult(mockRes)
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 				return db
 			},
 			ctx: context.Background(),
@@ -132,9 +162,13 @@ func TestGormCoinDAO_UpdateById(t *testing.T) {
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
 				mockRes := sqlmock.NewResult(0, 0)
 				mock.ExpectExec("UPDATE `coins` .*").
 					WillReturnResult(mockRes)
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
 				return db
 			},
 			ctx: context.Background(),
@@ -152,8 +186,10 @@ func TestGormCoinDAO_UpdateById(t *testing.T) {
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
 				mock.ExpectExec("UPDATE `coins` .*").
 					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
 				return db
 			},
 			ctx: context.Background(),
@@ -180,7 +216,7 @@ func TestGormCoinDAO_UpdateById(t *testing.T) {
 				SkipDefaultTransaction: true,
 			})
 			assert.NoError(t, err)
-			dao := NewGormCoinDAO(db, logger.NewNopLogger())
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
 			err = dao.UpdateById(tc.ctx, tc.coin)
 			assert.Equal(t, tc.wantErr, err)
 		})
@@ -261,7 +297,7 @@ func TestGormCoinDAO_FindById(t *testing.T) {
 				SkipDefaultTransaction: true,
 			})
 			assert.NoError(t, err)
-			dao := NewGormCoinDAO(db, logger.NewNopLogger())
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
 			ret, err := dao.FindById(context.Background(), tc.id)
 			assert.Equal(t, tc.wantErr, err)
 			if err != nil {
@@ -272,6 +308,141 @@ func TestGormCoinDAO_FindById(t *testing.T) {
 	}
 }
 
+func TestGormCoinDAO_FindByIdForUpdate(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		id int64
+
+		wantRet Coin
+		wantErr error
+	}{
+		{
+			name: "success",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `coins` WHERE id = ? ORDER BY `coins`.`id` LIMIT ? FOR UPDATE")).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "popularity_score"}).
+						AddRow(1, "test", nil, nowMs, nowMs, 0))
+				return db
+			},
+			wantRet: Coin{
+				Id:        1,
+				Name:      "test",
+				CreatedAt: nowMs,
+				UpdatedAt: nowMs,
+			},
+			id: 1,
+		},
+		{
+			name: "id not found",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `coins` WHERE id = ? ORDER BY `coins`.`id` LIMIT ? FOR UPDATE")).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "popularity_score"}))
+				return db
+			},
+			id:      1,
+			wantErr: ErrRecordNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			ret, err := dao.FindByIdForUpdate(context.Background(), tc.id)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestGormCoinDAO_FindByPublicId(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		publicId string
+
+		wantRet Coin
+		wantErr error
+	}{
+		{
+			name: "success",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `coins` WHERE public_id = ? ORDER BY `coins`.`id` LIMIT ?")).
+					WithArgs("01ARYZ6S410000000000000001", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "public_id", "name", "description", "created_at", "updated_at", "popularity_score"}).
+						AddRow(1, "01ARYZ6S410000000000000001", "test", nil, nowMs, nowMs, 0))
+				return db
+			},
+			wantRet: Coin{
+				Id:        1,
+				PublicId:  sql.NullString{String: "01ARYZ6S410000000000000001", Valid: true},
+				Name:      "test",
+				CreatedAt: nowMs,
+				UpdatedAt: nowMs,
+			},
+			publicId: "01ARYZ6S410000000000000001",
+		},
+		{
+			name: "public id not found",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `coins` WHERE public_id = ? ORDER BY `coins`.`id` LIMIT ?")).
+					WithArgs("01ARYZ6S410000000000000001", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "popularity_score"}))
+				return db
+			},
+			publicId: "01ARYZ6S410000000000000001",
+			wantErr:  ErrRecordNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			ret, err := dao.FindByPublicId(context.Background(), tc.publicId)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
 func TestGormCoinDAO_DeleteById(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -286,9 +457,17 @@ func TestGormCoinDAO_DeleteById(t *testing.T) {
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
 				mockRes := sqlmock.NewResult(0, 1)
 				mock.ExpectExec("DELETE FROM `coins` .*").
 					WillReturnResult(mockRes)
+				mock.ExpectExec("DELETE FROM `coin_reactions` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM `coin_reaction_counts` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
 				return db
 			},
 			id: 1,
@@ -298,9 +477,17 @@ func TestGormCoinDAO_DeleteById(t *testing.T) {
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
 				mockRes := sqlmock.NewResult(0, 0)
 				mock.ExpectExec("DELETE FROM `coins` .*").
 					WillReturnResult(mockRes)
+				mock.ExpectExec("DELETE FROM `coin_reactions` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM `coin_reaction_counts` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
 				return db
 			},
 			id: 1,
@@ -310,8 +497,26 @@ func TestGormCoinDAO_DeleteById(t *testing.T) {
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("DELETE FROM `coins` .*").
 					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
+				return db
+			},
+			id:      1,
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "record revision failed",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
 				return db
 			},
 			id:      1,
@@ -330,57 +535,365 @@ func TestGormCoinDAO_DeleteById(t *testing.T) {
 				SkipDefaultTransaction: true,
 			})
 			assert.NoError(t, err)
-			dao := NewGormCoinDAO(db, logger.NewNopLogger())
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
 			err = dao.DeleteById(context.Background(), tc.id)
 			assert.Equal(t, tc.wantErr, err)
 		})
 	}
 }
 
-func TestGormCoinDAO_IncrPopularityScore(t *testing.T) {
+func TestGormCoinDAO_AddReaction(t *testing.T) {
+	lockQuery := regexp.QuoteMeta("SELECT * FROM `coins` WHERE id = ? ORDER BY `coins`.`id` LIMIT ? FOR UPDATE")
 	testCases := []struct {
 		name    string
 		sqlmock func(t *testing.T) *sql.DB
 
-		id int64
+		id       int64
+		reaction string
 
 		wantErr error
 	}{
 		{
-			name: "incr success",
+			name: "poke success",
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
-				mockRes := sqlmock.NewResult(0, 1)
-				mock.ExpectExec("UPDATE `coins` .*").
-					WillReturnResult(mockRes)
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "popularity_score", "hot_score", "hot_score_at"}).
+						AddRow(1, "doge", 1, 1, 0))
+				mock.ExpectExec("INSERT INTO `coin_reactions` .*").
+					WithArgs(1, "fp:1", "poke", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `coin_reaction_counts` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE `coins` SET .*").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 2, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `coin_poke_rollups` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 				return db
 			},
-			id: 1,
+			id:       1,
+			reaction: ReactionPoke,
 		},
 		{
-			name: "incr but no affected",
+			name: "popularity score saturated",
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
-				mockRes := sqlmock.NewResult(0, 0)
-				mock.ExpectExec("UPDATE `coins` .*").
-					WillReturnResult(mockRes)
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "popularity_score", "hot_score", "hot_score_at"}).
+						AddRow(1, "doge", uint32(math.MaxUint32), 1, 0))
+				mock.ExpectExec("INSERT INTO `coin_reactions` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `coin_reaction_counts` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE `coins` SET .*").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), uint32(math.MaxUint32), sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `coin_poke_rollups` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO coin_revisions .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 				return db
 			},
-			id:      1,
-			wantErr: ErrRecordNotFound,
+			id:       1,
+			reaction: ReactionPoke,
+		},
+		{
+			name: "other reactions leave the scores alone",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "doge"))
+				mock.ExpectExec("INSERT INTO `coin_reactions` .*").
+					WithArgs(1, "fp:1", "rocket", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `coin_reaction_counts` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return db
+			},
+			id:       1,
+			reaction: "rocket",
+		},
+		{
+			name: "duplicate reaction",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "doge"))
+				mock.ExpectExec("INSERT INTO `coin_reactions` .*").
+					WillReturnError(&mysqlDriver.MySQLError{Number: 1062})
+				mock.ExpectRollback()
+				return db
+			},
+			id:       1,
+			reaction: ReactionPoke,
+			wantErr:  ErrDuplicateReaction,
+		},
+		{
+			name: "coin not found",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+				return db
+			},
+			id:       1,
+			reaction: ReactionPoke,
+			wantErr:  ErrRecordNotFound,
 		},
 		{
 			name: "incr failed",
 			sqlmock: func(t *testing.T) *sql.DB {
 				db, mock, err := sqlmock.New()
 				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "popularity_score"}).
+						AddRow(1, "doge", 1))
+				mock.ExpectExec("INSERT INTO `coin_reactions` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `coin_reaction_counts` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("UPDATE `coins` .*").
 					WillReturnError(errors.New("mock db error"))
+				mock.ExpectRollback()
+				return db
+			},
+			id:       1,
+			reaction: ReactionPoke,
+			wantErr:  errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			err = dao.AddReaction(context.Background(), tc.id, "fp:1", tc.reaction)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGormCoinDAO_FindTopByPopularity(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		limit int
+
+		wantRet []Coin
+		wantErr error
+	}{
+		{
+			name: "success",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `coins` ORDER BY popularity_score DESC,id DESC LIMIT ?")).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "popularity_score"}).
+						AddRow(2, "hot", nil, nowMs, nowMs, 10).
+						AddRow(1, "cold", nil, nowMs, nowMs, 1))
+				return db
+			},
+			limit: 2,
+			wantRet: []Coin{
+				{Id: 2, Name: "hot", CreatedAt: nowMs, UpdatedAt: nowMs, PopularityScore: 10},
+				{Id: 1, Name: "cold", CreatedAt: nowMs, UpdatedAt: nowMs, PopularityScore: 1},
+			},
+		},
+		{
+			name: "query failed",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `coins` ORDER BY popularity_score DESC,id DESC LIMIT ?")).
+					WithArgs(2).
+					WillReturnError(errors.New("mock db error"))
+				return db
+			},
+			limit:   2,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			ret, err := dao.FindTopByPopularity(context.Background(), tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestGormCoinDAO_FindRecent(t *testing.T) {
+	nowMs := time.Now().UnixMilli()
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		limit int
+
+		wantRet []Coin
+		wantErr error
+	}{
+		{
+			name: "success",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `coins` ORDER BY created_at DESC,id DESC LIMIT ?")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "popularity_score"}).
+						AddRow(3, "new", "desc", nowMs, nowMs, 0))
+				return db
+			},
+			limit: 1,
+			wantRet: []Coin{
+				{
+					Id:          3,
+					Name:        "new",
+					Description: sql.NullString{String: "desc", Valid: true},
+					CreatedAt:   nowMs,
+					UpdatedAt:   nowMs,
+				},
+			},
+		},
+		{
+			name: "empty",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `coins` ORDER BY created_at DESC,id DESC LIMIT ?")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "popularity_score"}))
+				return db
+			},
+			limit:   1,
+			wantRet: []Coin{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			ret, err := dao.FindRecent(context.Background(), tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestGormCoinDAO_RefreshHotScores(t *testing.T) {
+	now := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC).UnixMilli()
+	dayAgo := now - (24 * time.Hour).Milliseconds()
+	minuteAgo := now - time.Minute.Milliseconds()
+	selectSQL := regexp.QuoteMeta("SELECT `id`,`popularity_score`,`created_at`,`hot_score`,`hot_score_at` FROM `coins` " +
+		"WHERE id > ? AND hot_score > 0 ORDER BY id LIMIT ?")
+	updateSQL := regexp.QuoteMeta("UPDATE `coins` SET `hot_score`=CASE id WHEN 1 THEN ") + "[0-9.e-]+" +
+		regexp.QuoteMeta(" WHEN 4 THEN 0 END,`hot_score_at`=? WHERE (id = ? AND hot_score_at = ?) OR (id = ? AND hot_score_at = ?)")
+	columns := []string{"id", "popularity_score", "created_at", "hot_score", "hot_score_at"}
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		wantCount int64
+		wantErr   error
+	}{
+		{
+			name: "refresh success",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				// 1 halves, 2 barely changes, 3 was poked since, 4 decays below the minimum score
+				mock.ExpectQuery(selectSQL).
+					WithArgs(0, 4).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, 4, dayAgo, 4.0, dayAgo).
+						AddRow(2, 4, dayAgo, 4.0, minuteAgo).
+						AddRow(3, 4, dayAgo, 4.0, now).
+						AddRow(4, 1, dayAgo, 1e-6, dayAgo))
+				mock.ExpectExec(updateSQL).
+					WithArgs(now, 1, dayAgo, 4, dayAgo).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery(selectSQL).
+					WithArgs(4, 4).
+					WillReturnRows(sqlmock.NewRows(columns))
+				return db
+			},
+			wantCount: 2,
+		},
+		{
+			name: "update failed",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(selectSQL).
+					WithArgs(0, 4).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, 4, dayAgo, 4.0, dayAgo).
+						AddRow(4, 1, dayAgo, 1e-6, dayAgo))
+				mock.ExpectExec(updateSQL).
+					WillReturnError(errors.New("mock db error"))
 				return db
 			},
-			id:      1,
 			wantErr: errors.New("mock db error"),
 		},
 	}
@@ -396,9 +909,10 @@ func TestGormCoinDAO_IncrPopularityScore(t *testing.T) {
 				SkipDefaultTransaction: true,
 			})
 			assert.NoError(t, err)
-			dao := NewGormCoinDAO(db, logger.NewNopLogger())
-			err = dao.IncrPopularityScore(context.Background(), tc.id)
+			dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			n, err := dao.RefreshHotScores(context.Background(), now, 4)
 			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCount, n)
 		})
 	}
 }
@@ -0,0 +1,73 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	gosqlite "github.com/glebarez/go-sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	sqlite3 "modernc.org/sqlite/lib"
+	"strings"
+)
+
+// translateError maps the driver specific errors of every supported
+// dialect to the errors exposed by this package.
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrRecordNotFound
+	case isPrimaryKeyViolation(err):
+		// a generator sharing the node of another one, not a conflict the caller can resolve
+		return ErrDuplicateId
+	case isUniqueViolation(err):
+		// name is the only unique column besides the primary key
+		return ErrDuplicateName
+	default:
+		return err
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var me *mysql.MySQLError
+	if errors.As(err, &me) {
+		const duplicateErr uint16 = 1062
+		return me.Number == duplicateErr
+	}
+	var pe *pgconn.PgError
+	if errors.As(err, &pe) {
+		const uniqueViolation = "23505"
+		return pe.Code == uniqueViolation
+	}
+	var se *gosqlite.Error
+	if errors.As(err, &se) {
+		return se.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+			se.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
+
+// isPrimaryKeyViolation tells the unique violations of a primary key apart from those of the other unique indexes.
+func isPrimaryKeyViolation(err error) bool {
+	var me *mysql.MySQLError
+	if errors.As(err, &me) {
+		const duplicateErr uint16 = 1062
+		// "Duplicate entry '1' for key 'coins.PRIMARY'", the table is left out before MySQL 8
+		return me.Number == duplicateErr && strings.HasSuffix(me.Message, "PRIMARY'")
+	}
+	var pe *pgconn.PgError
+	if errors.As(err, &pe) {
+		const uniqueViolation = "23505"
+		return pe.Code == uniqueViolation && strings.HasSuffix(pe.ConstraintName, "_pkey")
+	}
+	var se *gosqlite.Error
+	if errors.As(err, &se) {
+		return se.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
@@ -1,11 +0,0 @@
-package dao
-
-import (
-	"gorm.io/gorm"
-)
-
-func InitTable(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&Coin{},
-	)
-}
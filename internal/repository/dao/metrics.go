@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// MetricsCoinDAO observes the latency of every operation of a CoinDAO, by operation and result.
+type MetricsCoinDAO struct {
+	dao      CoinDAO
+	duration *prometheus.HistogramVec
+}
+
+func NewMetricsCoinDAO(dao CoinDAO, reg prometheus.Registerer) CoinDAO {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "meme_coin",
+		Subsystem: "dao",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of the coin dao operations.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"operation", "result"})
+	reg.MustRegister(duration)
+	return &MetricsCoinDAO{
+		dao:      dao,
+		duration: duration,
+	}
+}
+
+// observe is deferred by the operations, a missing record is not counted as an error.
+func (m *MetricsCoinDAO) observe(op string, start time.Time, err *error) {
+	result := "ok"
+	switch {
+	case errors.Is(*err, ErrRecordNotFound):
+		result = "not_found"
+	case *err != nil:
+		result = "error"
+	}
+	m.duration.WithLabelValues(op, result).Observe(time.Since(start).Seconds())
+}
+
+func (m *MetricsCoinDAO) Insert(ctx context.Context, c Coin) (res Coin, err error) {
+	defer m.observe("insert", time.Now(), &err)
+	return m.dao.Insert(ctx, c)
+}
+
+func (m *MetricsCoinDAO) UpdateById(ctx context.Context, entity Coin) (err error) {
+	defer m.observe("update_by_id", time.Now(), &err)
+	return m.dao.UpdateById(ctx, entity)
+}
+
+func (m *MetricsCoinDAO) FindById(ctx context.Context, id int64) (res Coin, err error) {
+	defer m.observe("find_by_id", time.Now(), &err)
+	return m.dao.FindById(ctx, id)
+}
+
+func (m *MetricsCoinDAO) FindByIdForUpdate(ctx context.Context, id int64) (res Coin, err error) {
+	defer m.observe("find_by_id_for_update", time.Now(), &err)
+	return m.dao.FindByIdForUpdate(ctx, id)
+}
+
+func (m *MetricsCoinDAO) FindByPublicId(ctx context.Context, publicId string) (res Coin, err error) {
+	defer m.observe("find_by_public_id", time.Now(), &err)
+	return m.dao.FindByPublicId(ctx, publicId)
+}
+
+func (m *MetricsCoinDAO) FindByPublicIdForUpdate(ctx context.Context, publicId string) (res Coin, err error) {
+	defer m.observe("find_by_public_id_for_update", time.Now(), &err)
+	return m.dao.FindByPublicIdForUpdate(ctx, publicId)
+}
+
+func (m *MetricsCoinDAO) DeleteById(ctx context.Context, id int64) (err error) {
+	defer m.observe("delete_by_id", time.Now(), &err)
+	return m.dao.DeleteById(ctx, id)
+}
+
+func (m *MetricsCoinDAO) AddReaction(ctx context.Context, id int64, actor, reaction string) (err error) {
+	defer m.observe("add_reaction", time.Now(), &err)
+	return m.dao.AddReaction(ctx, id, actor, reaction)
+}
+
+func (m *MetricsCoinDAO) RemoveReaction(ctx context.Context, id int64, actor, reaction string) (err error) {
+	defer m.observe("remove_reaction", time.Now(), &err)
+	return m.dao.RemoveReaction(ctx, id, actor, reaction)
+}
+
+func (m *MetricsCoinDAO) FindReactionCounts(ctx context.Context, ids []int64) (res []CoinReactionCount, err error) {
+	defer m.observe("find_reaction_counts", time.Now(), &err)
+	return m.dao.FindReactionCounts(ctx, ids)
+}
+
+func (m *MetricsCoinDAO) FindTopByPopularity(ctx context.Context, limit int) (res []Coin, err error) {
+	defer m.observe("find_top_by_popularity", time.Now(), &err)
+	return m.dao.FindTopByPopularity(ctx, limit)
+}
+
+func (m *MetricsCoinDAO) FindTopByHotScore(ctx context.Context, limit int) (res []Coin, err error) {
+	defer m.observe("find_top_by_hot_score", time.Now(), &err)
+	return m.dao.FindTopByHotScore(ctx, limit)
+}
+
+func (m *MetricsCoinDAO) FindRecent(ctx context.Context, limit int) (res []Coin, err error) {
+	defer m.observe("find_recent", time.Now(), &err)
+	return m.dao.FindRecent(ctx, limit)
+}
+
+func (m *MetricsCoinDAO) FindRevisions(ctx context.Context, publicId string, after int64, limit int) (res []CoinRevision, err error) {
+	defer m.observe("find_revisions", time.Now(), &err)
+	return m.dao.FindRevisions(ctx, publicId, after, limit)
+}
+
+func (m *MetricsCoinDAO) FindRevisionAsOf(ctx context.Context, publicId string, at int64) (res CoinRevision, err error) {
+	defer m.observe("find_revision_as_of", time.Now(), &err)
+	return m.dao.FindRevisionAsOf(ctx, publicId, at)
+}
+
+func (m *MetricsCoinDAO) RefreshHotScores(ctx context.Context, now int64, batchSize int) (n int64, err error) {
+	defer m.observe("refresh_hot_scores", time.Now(), &err)
+	return m.dao.RefreshHotScores(ctx, now, batchSize)
+}
@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"context"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMetricsCoinDAO(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	dao := NewMetricsCoinDAO(NewGormCoinDAO(newSQLiteDB(t, "primary"), newIdGenerator(t),
+		newHotScoreModel(), logger.NewNopLogger()), reg)
+
+	c, err := dao.Insert(ctx, Coin{Name: "doge"})
+	require.NoError(t, err)
+	_, err = dao.FindById(ctx, c.Id)
+	require.NoError(t, err)
+	_, err = dao.FindById(ctx, c.Id+1)
+	assert.Equal(t, ErrRecordNotFound, err)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	observed := make(map[string]uint64)
+	for _, m := range families[0].GetMetric() {
+		labels := m.GetLabel()
+		observed[labels[0].GetValue()+"/"+labels[1].GetValue()] = m.GetHistogram().GetSampleCount()
+	}
+	assert.Equal(t, map[string]uint64{
+		"insert/ok":            1,
+		"find_by_id/ok":        1,
+		"find_by_id/not_found": 1,
+	}, observed)
+}
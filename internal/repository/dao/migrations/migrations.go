@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// files holds the versioned schema migrations, one directory per dialect
+// with the same versions in each, see pkg/migrator for the file layout.
+//
+//go:embed mysql postgres sqlite
+var files embed.FS
+
+// For returns the migrations of a gorm dialect name.
+func For(dialect string) (fs.FS, error) {
+	switch dialect {
+	case "mysql", "postgres", "sqlite":
+		return fs.Sub(files, dialect)
+	default:
+		return nil, fmt.Errorf("no migrations for dialect %s", dialect)
+	}
+}
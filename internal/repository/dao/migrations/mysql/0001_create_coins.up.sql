@@ -0,0 +1,11 @@
+-- Matches the table previously created by gorm AutoMigrate,
+-- existing databases adopt it as their first version.
+CREATE TABLE IF NOT EXISTS `coins` (
+    `id`               BIGINT AUTO_INCREMENT,
+    `name`             LONGTEXT,
+    `description`      LONGTEXT,
+    `created_at`       BIGINT,
+    `updated_at`       BIGINT,
+    `popularity_score` INT UNSIGNED DEFAULT 0,
+    PRIMARY KEY (`id`)
+);
@@ -0,0 +1,7 @@
+ALTER TABLE `coins`
+    DROP INDEX `uniq_coins_name`,
+    DROP INDEX `idx_coins_popularity_score`,
+    DROP INDEX `idx_coins_created_at`,
+    MODIFY `name` LONGTEXT,
+    MODIFY `description` LONGTEXT,
+    MODIFY `popularity_score` INT UNSIGNED DEFAULT 0;
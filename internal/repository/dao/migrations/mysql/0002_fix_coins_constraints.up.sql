@@ -0,0 +1,10 @@
+-- The original gorm tags were malformed, so names were not unique and no
+-- column had its intended size. Existing duplicate names or descriptions
+-- longer than 128 characters must be fixed before applying this migration.
+ALTER TABLE `coins`
+    MODIFY `name` VARCHAR(255) NOT NULL,
+    MODIFY `description` VARCHAR(128) NULL,
+    MODIFY `popularity_score` INT UNSIGNED NOT NULL DEFAULT 0,
+    ADD UNIQUE INDEX `uniq_coins_name` (`name`),
+    ADD INDEX `idx_coins_popularity_score` (`popularity_score`),
+    ADD INDEX `idx_coins_created_at` (`created_at`);
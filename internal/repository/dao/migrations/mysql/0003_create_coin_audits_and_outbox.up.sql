@@ -0,0 +1,24 @@
+CREATE TABLE `coin_audits` (
+    `id`         BIGINT NOT NULL AUTO_INCREMENT,
+    `coin_id`    BIGINT NOT NULL,
+    `action`     VARCHAR(32) NOT NULL,
+    -- json snapshots of the coin, old_value is empty on create and new_value on delete
+    `old_value`  TEXT,
+    `new_value`  TEXT,
+    `created_at` BIGINT NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_coin_audits_coin_id` (`coin_id`, `id`)
+);
+
+CREATE TABLE `outbox_events` (
+    `id`             BIGINT NOT NULL AUTO_INCREMENT,
+    `aggregate_type` VARCHAR(64) NOT NULL,
+    `aggregate_id`   BIGINT NOT NULL,
+    `event_type`     VARCHAR(64) NOT NULL,
+    `payload`        TEXT NOT NULL,
+    `created_at`     BIGINT NOT NULL,
+    -- set by the relay once the event is delivered
+    `published_at`   BIGINT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_outbox_events_published_at` (`published_at`, `id`)
+);
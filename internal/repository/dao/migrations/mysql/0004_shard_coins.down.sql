@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `coin_names`;
+ALTER TABLE `coins` MODIFY `id` BIGINT NOT NULL AUTO_INCREMENT;
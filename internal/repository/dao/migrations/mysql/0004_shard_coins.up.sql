@@ -0,0 +1,10 @@
+-- Coin ids are generated by the application so they stay unique across shards.
+ALTER TABLE `coins` MODIFY `id` BIGINT NOT NULL;
+
+-- Reserves a coin name on the shard its name hashes to, names are unique
+-- across shards while each coins table only sees its own rows.
+CREATE TABLE `coin_names` (
+    `name`    VARCHAR(255) NOT NULL,
+    `coin_id` BIGINT NOT NULL,
+    PRIMARY KEY (`name`)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE `coins`
+    DROP INDEX `uniq_coins_public_id`,
+    DROP COLUMN `public_id`;
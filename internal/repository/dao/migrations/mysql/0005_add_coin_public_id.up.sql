@@ -0,0 +1,4 @@
+-- The opaque id exposed by the api, existing coins are backfilled by the service on startup.
+ALTER TABLE `coins`
+    ADD COLUMN `public_id` CHAR(26) NULL,
+    ADD UNIQUE INDEX `uniq_coins_public_id` (`public_id`);
@@ -0,0 +1,25 @@
+CREATE TABLE `coin_revisions` (
+    `id`               BIGINT NOT NULL AUTO_INCREMENT,
+    `coin_id`          BIGINT NOT NULL,
+    `public_id`        CHAR(26),
+    -- numbered from 1 for each coin in the order the changes were made
+    `revision`         BIGINT NOT NULL,
+    `action`           VARCHAR(32) NOT NULL,
+    `name`             VARCHAR(255) NOT NULL,
+    `description`      VARCHAR(128),
+    `popularity_score` INT UNSIGNED NOT NULL,
+    `created_at`       BIGINT,
+    `updated_at`       BIGINT,
+    -- the coin looked like this from valid_from until the valid_from of its next revision
+    `valid_from`       BIGINT NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `uniq_coin_revisions_coin_id` (`coin_id`, `revision`),
+    INDEX `idx_coin_revisions_public_id` (`public_id`, `revision`)
+);
+
+-- The history of the existing coins starts with their current state, since their creation.
+INSERT INTO `coin_revisions` (`coin_id`, `public_id`, `revision`, `action`, `name`, `description`,
+                              `popularity_score`, `created_at`, `updated_at`, `valid_from`)
+SELECT `id`, `public_id`, 1, 'snapshot', `name`, `description`,
+       `popularity_score`, `created_at`, `updated_at`, COALESCE(`created_at`, 0)
+FROM `coins`;
@@ -0,0 +1,12 @@
+-- Pokes per coin and time bucket. Pokes are counted in minute buckets, which a
+-- background job rolls up into hour and day buckets. Each granularity has its own retention.
+CREATE TABLE `coin_poke_rollups` (
+    `coin_id`     BIGINT NOT NULL,
+    -- minute, hour or day
+    `granularity` VARCHAR(8) NOT NULL,
+    -- unix milli of the start of the bucket
+    `bucket`      BIGINT NOT NULL,
+    `pokes`       BIGINT NOT NULL,
+    PRIMARY KEY (`coin_id`, `granularity`, `bucket`),
+    INDEX `idx_coin_poke_rollups_bucket` (`granularity`, `bucket`)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE `coins`
+    DROP INDEX `idx_coins_hot_score`,
+    DROP COLUMN `hot_score_at`,
+    DROP COLUMN `hot_score`;
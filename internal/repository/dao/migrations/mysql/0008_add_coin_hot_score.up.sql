@@ -0,0 +1,7 @@
+-- The popularity decayed over time, see pkg/hotscore. Existing scores count as pokes
+-- at the last update of their coin until the first refresh.
+ALTER TABLE `coins`
+    ADD COLUMN `hot_score` DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN `hot_score_at` BIGINT NOT NULL DEFAULT 0,
+    ADD INDEX `idx_coins_hot_score` (`hot_score`);
+UPDATE `coins` SET `hot_score` = `popularity_score`, `hot_score_at` = COALESCE(`updated_at`, 0);
@@ -0,0 +1,3 @@
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_coins_name ON coins (name);
+CREATE INDEX IF NOT EXISTS idx_coins_popularity_score ON coins (popularity_score);
+CREATE INDEX IF NOT EXISTS idx_coins_created_at ON coins (created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS coin_names;
+ALTER TABLE coins ALTER COLUMN id SET DEFAULT nextval('coins_id_seq');
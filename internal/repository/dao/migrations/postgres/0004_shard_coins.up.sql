@@ -0,0 +1,10 @@
+-- Coin ids are generated by the application so they stay unique across shards,
+-- the sequence is kept for the down migration.
+ALTER TABLE coins ALTER COLUMN id DROP DEFAULT;
+
+-- Reserves a coin name on the shard its name hashes to, names are unique
+-- across shards while each coins table only sees its own rows.
+CREATE TABLE IF NOT EXISTS coin_names (
+    name    VARCHAR(255) PRIMARY KEY,
+    coin_id BIGINT NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uniq_coins_public_id;
+ALTER TABLE coins DROP COLUMN IF EXISTS public_id;
@@ -0,0 +1,3 @@
+-- The opaque id exposed by the api, existing coins are backfilled by the service on startup.
+ALTER TABLE coins ADD COLUMN IF NOT EXISTS public_id CHAR(26);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_coins_public_id ON coins (public_id);
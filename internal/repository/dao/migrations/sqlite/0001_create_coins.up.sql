@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS coins (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    name             VARCHAR(255) NOT NULL,
+    description      VARCHAR(128),
+    created_at       BIGINT NOT NULL,
+    updated_at       BIGINT NOT NULL,
+    popularity_score INTEGER NOT NULL DEFAULT 0 CHECK (popularity_score >= 0)
+);
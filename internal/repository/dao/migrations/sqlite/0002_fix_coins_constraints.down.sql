@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS uniq_coins_name;
+DROP INDEX IF EXISTS idx_coins_popularity_score;
+DROP INDEX IF EXISTS idx_coins_created_at;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS coin_audits (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    coin_id    BIGINT NOT NULL,
+    action     VARCHAR(32) NOT NULL,
+    -- json snapshots of the coin, old_value is empty on create and new_value on delete
+    old_value  TEXT,
+    new_value  TEXT,
+    created_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_coin_audits_coin_id ON coin_audits (coin_id, id);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id   BIGINT NOT NULL,
+    event_type     VARCHAR(64) NOT NULL,
+    payload        TEXT NOT NULL,
+    created_at     BIGINT NOT NULL,
+    -- set by the relay once the event is delivered
+    published_at   BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events (published_at, id);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS coin_revisions (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    coin_id          BIGINT NOT NULL,
+    public_id        CHAR(26),
+    -- numbered from 1 for each coin in the order the changes were made
+    revision         BIGINT NOT NULL,
+    action           VARCHAR(32) NOT NULL,
+    name             VARCHAR(255) NOT NULL,
+    description      VARCHAR(128),
+    popularity_score INTEGER NOT NULL,
+    created_at       BIGINT NOT NULL,
+    updated_at       BIGINT NOT NULL,
+    -- the coin looked like this from valid_from until the valid_from of its next revision
+    valid_from       BIGINT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_coin_revisions_coin_id ON coin_revisions (coin_id, revision);
+CREATE INDEX IF NOT EXISTS idx_coin_revisions_public_id ON coin_revisions (public_id, revision);
+
+-- The history of the existing coins starts with their current state, since their creation.
+INSERT INTO coin_revisions (coin_id, public_id, revision, action, name, description,
+                            popularity_score, created_at, updated_at, valid_from)
+SELECT id, public_id, 1, 'snapshot', name, description,
+       popularity_score, COALESCE(created_at, 0), COALESCE(updated_at, 0), COALESCE(created_at, 0)
+FROM coins;
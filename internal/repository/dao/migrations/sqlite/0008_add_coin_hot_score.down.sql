@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_coins_hot_score;
+ALTER TABLE coins DROP COLUMN hot_score_at;
+ALTER TABLE coins DROP COLUMN hot_score;
@@ -0,0 +1,6 @@
+-- The popularity decayed over time, see pkg/hotscore. Existing scores count as pokes
+-- at the last update of their coin until the first refresh.
+ALTER TABLE coins ADD COLUMN hot_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE coins ADD COLUMN hot_score_at BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_coins_hot_score ON coins (hot_score);
+UPDATE coins SET hot_score = popularity_score, hot_score_at = COALESCE(updated_at, 0);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS coin_reaction_counts;
+DROP TABLE IF EXISTS coin_reactions;
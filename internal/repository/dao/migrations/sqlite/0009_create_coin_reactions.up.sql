@@ -0,0 +1,24 @@
+-- The reactions of the clients to the coins, a client adds each type of reaction
+-- at most once per coin.
+CREATE TABLE IF NOT EXISTS coin_reactions (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    coin_id    BIGINT NOT NULL,
+    -- the client id or the fingerprint of the client
+    actor      VARCHAR(80) NOT NULL,
+    -- poke, rocket, skull, fire or heart
+    reaction   VARCHAR(16) NOT NULL,
+    created_at BIGINT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_coin_reactions_actor ON coin_reactions (coin_id, actor, reaction);
+
+-- The number of reactions of each type per coin, updated with the reactions.
+CREATE TABLE IF NOT EXISTS coin_reaction_counts (
+    coin_id  BIGINT NOT NULL,
+    reaction VARCHAR(16) NOT NULL,
+    total    BIGINT NOT NULL,
+    PRIMARY KEY (coin_id, reaction)
+);
+
+-- The pokes made before the reactions have no actor, they are only counted.
+INSERT INTO coin_reaction_counts (coin_id, reaction, total)
+SELECT id, 'poke', popularity_score FROM coins WHERE popularity_score > 0;
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./audit.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./audit.go -package=daomocks -destination=./mocks/audit.mock.go CoinAuditDAO
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCoinAuditDAO is a mock of CoinAuditDAO interface.
+type MockCoinAuditDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockCoinAuditDAOMockRecorder
+	isgomock struct{}
+}
+
+// MockCoinAuditDAOMockRecorder is the mock recorder for MockCoinAuditDAO.
+type MockCoinAuditDAOMockRecorder struct {
+	mock *MockCoinAuditDAO
+}
+
+// NewMockCoinAuditDAO creates a new mock instance.
+func NewMockCoinAuditDAO(ctrl *gomock.Controller) *MockCoinAuditDAO {
+	mock := &MockCoinAuditDAO{ctrl: ctrl}
+	mock.recorder = &MockCoinAuditDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCoinAuditDAO) EXPECT() *MockCoinAuditDAOMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockCoinAuditDAO) Insert(ctx context.Context, a dao.CoinAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCoinAuditDAOMockRecorder) Insert(ctx, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCoinAuditDAO)(nil).Insert), ctx, a)
+}
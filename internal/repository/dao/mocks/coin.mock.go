@@ -41,6 +41,20 @@ func (m *MockCoinDAO) EXPECT() *MockCoinDAOMockRecorder {
 	return m.recorder
 }
 
+// AddReaction mocks base method.
+func (m *MockCoinDAO) AddReaction(ctx context.Context, id int64, actor, reaction string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReaction", ctx, id, actor, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddReaction indicates an expected call of AddReaction.
+func (mr *MockCoinDAOMockRecorder) AddReaction(ctx, id, actor, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReaction", reflect.TypeOf((*MockCoinDAO)(nil).AddReaction), ctx, id, actor, reaction)
+}
+
 // DeleteById mocks base method.
 func (m *MockCoinDAO) DeleteById(ctx context.Context, uid int64) error {
 	m.ctrl.T.Helper()
@@ -70,18 +84,139 @@ func (mr *MockCoinDAOMockRecorder) FindById(ctx, uid any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockCoinDAO)(nil).FindById), ctx, uid)
 }
 
-// IncrPopularityScore mocks base method.
-func (m *MockCoinDAO) IncrPopularityScore(ctx context.Context, id int64) error {
+// FindByIdForUpdate mocks base method.
+func (m *MockCoinDAO) FindByIdForUpdate(ctx context.Context, id int64) (dao.Coin, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrPopularityScore", ctx, id)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "FindByIdForUpdate", ctx, id)
+	ret0, _ := ret[0].(dao.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdForUpdate indicates an expected call of FindByIdForUpdate.
+func (mr *MockCoinDAOMockRecorder) FindByIdForUpdate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdForUpdate", reflect.TypeOf((*MockCoinDAO)(nil).FindByIdForUpdate), ctx, id)
+}
+
+// FindByPublicId mocks base method.
+func (m *MockCoinDAO) FindByPublicId(ctx context.Context, publicId string) (dao.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPublicId", ctx, publicId)
+	ret0, _ := ret[0].(dao.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPublicId indicates an expected call of FindByPublicId.
+func (mr *MockCoinDAOMockRecorder) FindByPublicId(ctx, publicId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPublicId", reflect.TypeOf((*MockCoinDAO)(nil).FindByPublicId), ctx, publicId)
+}
+
+// FindByPublicIdForUpdate mocks base method.
+func (m *MockCoinDAO) FindByPublicIdForUpdate(ctx context.Context, publicId string) (dao.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPublicIdForUpdate", ctx, publicId)
+	ret0, _ := ret[0].(dao.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// IncrPopularityScore indicates an expected call of IncrPopularityScore.
-func (mr *MockCoinDAOMockRecorder) IncrPopularityScore(ctx, id any) *gomock.Call {
+// FindByPublicIdForUpdate indicates an expected call of FindByPublicIdForUpdate.
+func (mr *MockCoinDAOMockRecorder) FindByPublicIdForUpdate(ctx, publicId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrPopularityScore", reflect.TypeOf((*MockCoinDAO)(nil).IncrPopularityScore), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPublicIdForUpdate", reflect.TypeOf((*MockCoinDAO)(nil).FindByPublicIdForUpdate), ctx, publicId)
+}
+
+// FindReactionCounts mocks base method.
+func (m *MockCoinDAO) FindReactionCounts(ctx context.Context, ids []int64) ([]dao.CoinReactionCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReactionCounts", ctx, ids)
+	ret0, _ := ret[0].([]dao.CoinReactionCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReactionCounts indicates an expected call of FindReactionCounts.
+func (mr *MockCoinDAOMockRecorder) FindReactionCounts(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReactionCounts", reflect.TypeOf((*MockCoinDAO)(nil).FindReactionCounts), ctx, ids)
+}
+
+// FindRecent mocks base method.
+func (m *MockCoinDAO) FindRecent(ctx context.Context, limit int) ([]dao.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRecent", ctx, limit)
+	ret0, _ := ret[0].([]dao.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRecent indicates an expected call of FindRecent.
+func (mr *MockCoinDAOMockRecorder) FindRecent(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRecent", reflect.TypeOf((*MockCoinDAO)(nil).FindRecent), ctx, limit)
+}
+
+// FindRevisionAsOf mocks base method.
+func (m *MockCoinDAO) FindRevisionAsOf(ctx context.Context, publicId string, at int64) (dao.CoinRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevisionAsOf", ctx, publicId, at)
+	ret0, _ := ret[0].(dao.CoinRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevisionAsOf indicates an expected call of FindRevisionAsOf.
+func (mr *MockCoinDAOMockRecorder) FindRevisionAsOf(ctx, publicId, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevisionAsOf", reflect.TypeOf((*MockCoinDAO)(nil).FindRevisionAsOf), ctx, publicId, at)
+}
+
+// FindRevisions mocks base method.
+func (m *MockCoinDAO) FindRevisions(ctx context.Context, publicId string, after int64, limit int) ([]dao.CoinRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevisions", ctx, publicId, after, limit)
+	ret0, _ := ret[0].([]dao.CoinRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevisions indicates an expected call of FindRevisions.
+func (mr *MockCoinDAOMockRecorder) FindRevisions(ctx, publicId, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevisions", reflect.TypeOf((*MockCoinDAO)(nil).FindRevisions), ctx, publicId, after, limit)
+}
+
+// FindTopByHotScore mocks base method.
+func (m *MockCoinDAO) FindTopByHotScore(ctx context.Context, limit int) ([]dao.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTopByHotScore", ctx, limit)
+	ret0, _ := ret[0].([]dao.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTopByHotScore indicates an expected call of FindTopByHotScore.
+func (mr *MockCoinDAOMockRecorder) FindTopByHotScore(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTopByHotScore", reflect.TypeOf((*MockCoinDAO)(nil).FindTopByHotScore), ctx, limit)
+}
+
+// FindTopByPopularity mocks base method.
+func (m *MockCoinDAO) FindTopByPopularity(ctx context.Context, limit int) ([]dao.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTopByPopularity", ctx, limit)
+	ret0, _ := ret[0].([]dao.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTopByPopularity indicates an expected call of FindTopByPopularity.
+func (mr *MockCoinDAOMockRecorder) FindTopByPopularity(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTopByPopularity", reflect.TypeOf((*MockCoinDAO)(nil).FindTopByPopularity), ctx, limit)
 }
 
 // Insert mocks base method.
@@ -99,6 +234,35 @@ func (mr *MockCoinDAOMockRecorder) Insert(ctx, c any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCoinDAO)(nil).Insert), ctx, c)
 }
 
+// RefreshHotScores mocks base method.
+func (m *MockCoinDAO) RefreshHotScores(ctx context.Context, now int64, batchSize int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshHotScores", ctx, now, batchSize)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshHotScores indicates an expected call of RefreshHotScores.
+func (mr *MockCoinDAOMockRecorder) RefreshHotScores(ctx, now, batchSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshHotScores", reflect.TypeOf((*MockCoinDAO)(nil).RefreshHotScores), ctx, now, batchSize)
+}
+
+// RemoveReaction mocks base method.
+func (m *MockCoinDAO) RemoveReaction(ctx context.Context, id int64, actor, reaction string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReaction", ctx, id, actor, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveReaction indicates an expected call of RemoveReaction.
+func (mr *MockCoinDAOMockRecorder) RemoveReaction(ctx, id, actor, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReaction", reflect.TypeOf((*MockCoinDAO)(nil).RemoveReaction), ctx, id, actor, reaction)
+}
+
 // UpdateById mocks base method.
 func (m *MockCoinDAO) UpdateById(ctx context.Context, entity dao.Coin) error {
 	m.ctrl.T.Helper()
@@ -112,3 +276,41 @@ func (mr *MockCoinDAOMockRecorder) UpdateById(ctx, entity any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockCoinDAO)(nil).UpdateById), ctx, entity)
 }
+
+// MockIdGenerator is a mock of IdGenerator interface.
+type MockIdGenerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdGeneratorMockRecorder
+	isgomock struct{}
+}
+
+// MockIdGeneratorMockRecorder is the mock recorder for MockIdGenerator.
+type MockIdGeneratorMockRecorder struct {
+	mock *MockIdGenerator
+}
+
+// NewMockIdGenerator creates a new mock instance.
+func NewMockIdGenerator(ctrl *gomock.Controller) *MockIdGenerator {
+	mock := &MockIdGenerator{ctrl: ctrl}
+	mock.recorder = &MockIdGeneratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdGenerator) EXPECT() *MockIdGeneratorMockRecorder {
+	return m.recorder
+}
+
+// NextId mocks base method.
+func (m *MockIdGenerator) NextId() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextId")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// NextId indicates an expected call of NextId.
+func (mr *MockIdGeneratorMockRecorder) NextId() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextId", reflect.TypeOf((*MockIdGenerator)(nil).NextId))
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./outbox.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./outbox.go -package=daomocks -destination=./mocks/outbox.mock.go OutboxDAO
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOutboxDAO is a mock of OutboxDAO interface.
+type MockOutboxDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxDAOMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxDAOMockRecorder is the mock recorder for MockOutboxDAO.
+type MockOutboxDAOMockRecorder struct {
+	mock *MockOutboxDAO
+}
+
+// NewMockOutboxDAO creates a new mock instance.
+func NewMockOutboxDAO(ctrl *gomock.Controller) *MockOutboxDAO {
+	mock := &MockOutboxDAO{ctrl: ctrl}
+	mock.recorder = &MockOutboxDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxDAO) EXPECT() *MockOutboxDAOMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockOutboxDAO) Insert(ctx context.Context, e dao.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockOutboxDAOMockRecorder) Insert(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockOutboxDAO)(nil).Insert), ctx, e)
+}
@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./rollup.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./rollup.go -package=daomocks -destination=./mocks/rollup.mock.go PokeRollupDAO
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dao "github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPokeRollupDAO is a mock of PokeRollupDAO interface.
+type MockPokeRollupDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockPokeRollupDAOMockRecorder
+	isgomock struct{}
+}
+
+// MockPokeRollupDAOMockRecorder is the mock recorder for MockPokeRollupDAO.
+type MockPokeRollupDAOMockRecorder struct {
+	mock *MockPokeRollupDAO
+}
+
+// NewMockPokeRollupDAO creates a new mock instance.
+func NewMockPokeRollupDAO(ctrl *gomock.Controller) *MockPokeRollupDAO {
+	mock := &MockPokeRollupDAO{ctrl: ctrl}
+	mock.recorder = &MockPokeRollupDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPokeRollupDAO) EXPECT() *MockPokeRollupDAOMockRecorder {
+	return m.recorder
+}
+
+// Compact mocks base method.
+func (m *MockPokeRollupDAO) Compact(ctx context.Context, granularity string, since int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compact", ctx, granularity, since)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Compact indicates an expected call of Compact.
+func (mr *MockPokeRollupDAOMockRecorder) Compact(ctx, granularity, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compact", reflect.TypeOf((*MockPokeRollupDAO)(nil).Compact), ctx, granularity, since)
+}
+
+// DeleteBefore mocks base method.
+func (m *MockPokeRollupDAO) DeleteBefore(ctx context.Context, granularity string, before int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, granularity, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockPokeRollupDAOMockRecorder) DeleteBefore(ctx, granularity, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockPokeRollupDAO)(nil).DeleteBefore), ctx, granularity, before)
+}
+
+// Find mocks base method.
+func (m *MockPokeRollupDAO) Find(ctx context.Context, coinId int64, granularity string, from, to int64) ([]dao.CoinPokeRollup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, coinId, granularity, from, to)
+	ret0, _ := ret[0].([]dao.CoinPokeRollup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockPokeRollupDAOMockRecorder) Find(ctx, coinId, granularity, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockPokeRollupDAO)(nil).Find), ctx, coinId, granularity, from, to)
+}
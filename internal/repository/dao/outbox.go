@@ -0,0 +1,41 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"gorm.io/gorm"
+	"time"
+)
+
+//go:generate mockgen -source=./outbox.go -package=daomocks -destination=./mocks/outbox.mock.go OutboxDAO
+type OutboxDAO interface {
+	// Insert appends an event, it is published only if the transaction of ctx commits.
+	Insert(ctx context.Context, e OutboxEvent) error
+}
+
+type GormOutboxDAO struct {
+	db *gorm.DB
+}
+
+func NewGormOutboxDAO(db *gorm.DB) OutboxDAO {
+	return &GormOutboxDAO{
+		db: db,
+	}
+}
+
+func (dao *GormOutboxDAO) Insert(ctx context.Context, e OutboxEvent) error {
+	e.CreatedAt = time.Now().UnixMilli()
+	db, _ := dbFromContext(ctx, dao.db)
+	return db.Create(&e).Error
+}
+
+// OutboxEvent is an event waiting in the outbox_events table for a relay to publish it.
+type OutboxEvent struct {
+	Id            int64  `gorm:"primaryKey;autoIncrement"`
+	AggregateType string `gorm:"type:varchar(64);not null"`
+	AggregateId   int64  `gorm:"not null"`
+	EventType     string `gorm:"type:varchar(64);not null"`
+	Payload       string `gorm:"type:text;not null"`
+	CreatedAt     int64  `gorm:"not null"`
+	PublishedAt   sql.NullInt64
+}
@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGormOutboxDAO_Insert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		event OutboxEvent
+
+		wantErr error
+	}{
+		{
+			name: "insert success",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("INSERT INTO `outbox_events` .*").
+					WithArgs("coin", 1, "coin.created", `{"id":1}`, sqlmock.AnyArg(), nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				return db
+			},
+			event: OutboxEvent{
+				AggregateType: "coin",
+				AggregateId:   1,
+				EventType:     "coin.created",
+				Payload:       `{"id":1}`,
+			},
+		},
+		{
+			name: "insert failed",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("INSERT INTO `outbox_events` .*").
+					WillReturnError(errors.New("mock db error"))
+				return db
+			},
+			event:   OutboxEvent{AggregateType: "coin", AggregateId: 1},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+			dao := NewGormOutboxDAO(db)
+			err = dao.Insert(context.Background(), tc.event)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"time"
+)
+
+var ErrDuplicateReaction = errors.New("duplicate reaction")
+
+// ReactionPoke is the reaction counted by the popularity and hot scores of the coin.
+const ReactionPoke = "poke"
+
+// AddReaction fails with ErrDuplicateReaction when the actor already added the reaction,
+// the unique index on the coin, the actor and the reaction enforces it.
+func (dao *GormCoinDAO) AddReaction(ctx context.Context, id int64, actor, reaction string) error {
+	now := time.Now().UnixMilli()
+	return inTx(ctx, func(ctx context.Context) error {
+		// the lock serializes the reactions to the coin and fails once it is deleted
+		c, err := dao.FindByIdForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		err = dao.writer(ctx).Create(&CoinReaction{
+			CoinId:    id,
+			Actor:     actor,
+			Reaction:  reaction,
+			CreatedAt: now,
+		}).Error
+		if isUniqueViolation(err) {
+			return ErrDuplicateReaction
+		}
+		if err != nil {
+			return err
+		}
+		err = dao.writer(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "coin_id"}, {Name: "reaction"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"total": gorm.Expr("coin_reaction_counts.total + ?", 1),
+			}),
+		}).Create(&CoinReactionCount{
+			CoinId:   id,
+			Reaction: reaction,
+			Total:    1,
+		}).Error
+		if err != nil || reaction != ReactionPoke {
+			return err
+		}
+		return dao.poke(ctx, c, now)
+	})
+}
+
+// RemoveReaction is idempotent, removing a reaction the actor did not add succeeds.
+func (dao *GormCoinDAO) RemoveReaction(ctx context.Context, id int64, actor, reaction string) error {
+	now := time.Now().UnixMilli()
+	return inTx(ctx, func(ctx context.Context) error {
+		c, err := dao.FindByIdForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		var r CoinReaction
+		err = dao.writer(ctx).Where("coin_id = ? AND actor = ? AND reaction = ?", id, actor, reaction).
+			First(&r).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = dao.writer(ctx).Delete(&r).Error; err != nil {
+			return err
+		}
+		err = dao.writer(ctx).Model(&CoinReactionCount{}).
+			Where("coin_id = ? AND reaction = ? AND total > 0", id, reaction).
+			UpdateColumn("total", gorm.Expr("total - ?", 1)).Error
+		if err != nil || reaction != ReactionPoke {
+			return err
+		}
+		return dao.unpoke(ctx, c, r.CreatedAt, now)
+	})
+}
+
+func (dao *GormCoinDAO) FindReactionCounts(ctx context.Context, ids []int64) ([]CoinReactionCount, error) {
+	var res []CoinReactionCount
+	if len(ids) == 0 {
+		return res, nil
+	}
+	err := dao.reader(ctx).Where("coin_id IN ? AND total > 0", ids).
+		Order("coin_id").Order("reaction").Find(&res).Error
+	return res, err
+}
+
+// poke raises the scores of the coin c locked by the transaction of ctx. The popularity score
+// stops counting at its maximum, the hot score keeps ranking the coin.
+func (dao *GormCoinDAO) poke(ctx context.Context, c Coin, now int64) error {
+	score := c.PopularityScore
+	if score < math.MaxUint32 {
+		score++
+	}
+	err := dao.writer(ctx).Model(&Coin{}).Where("id = ?", c.Id).
+		Updates(map[string]any{
+			"popularity_score": score,
+			"hot_score":        dao.hot.Poke(c.hotItem(), time.UnixMilli(now)),
+			"hot_score_at":     now,
+			"updated_at":       now,
+		}).Error
+	if err != nil {
+		return err
+	}
+	if err = dao.recordPoke(ctx, c.Id, now); err != nil {
+		return err
+	}
+	return dao.recordRevision(ctx, c.Id, RevisionActionPoke, now)
+}
+
+// unpoke withdraws the poke made at the unix milli pokedAt from the scores of the coin c
+// locked by the transaction of ctx.
+func (dao *GormCoinDAO) unpoke(ctx context.Context, c Coin, pokedAt, now int64) error {
+	score := c.PopularityScore
+	if score > 0 {
+		score--
+	}
+	err := dao.writer(ctx).Model(&Coin{}).Where("id = ?", c.Id).
+		Updates(map[string]any{
+			"popularity_score": score,
+			"hot_score":        dao.hot.Unpoke(c.hotItem(), time.UnixMilli(pokedAt), time.UnixMilli(now)),
+			"hot_score_at":     now,
+			"updated_at":       now,
+		}).Error
+	if err != nil {
+		return err
+	}
+	if err = dao.withdrawPoke(ctx, c.Id, pokedAt); err != nil {
+		return err
+	}
+	return dao.recordRevision(ctx, c.Id, RevisionActionUnpoke, now)
+}
+
+// deleteReactions drops the reactions to the coin, call it in the transaction deleting it.
+func (dao *GormCoinDAO) deleteReactions(ctx context.Context, id int64) error {
+	err := dao.writer(ctx).Where("coin_id = ?", id).Delete(&CoinReaction{}).Error
+	if err != nil {
+		return err
+	}
+	return dao.writer(ctx).Where("coin_id = ?", id).Delete(&CoinReactionCount{}).Error
+}
+
+// CoinReaction is a reaction of an actor, a client id or fingerprint, to a coin.
+type CoinReaction struct {
+	Id        int64  `gorm:"primaryKey;autoIncrement"`
+	CoinId    int64  `gorm:"not null;uniqueIndex:uniq_coin_reactions_actor,priority:1"`
+	Actor     string `gorm:"type:varchar(80);not null;uniqueIndex:uniq_coin_reactions_actor,priority:2"`
+	Reaction  string `gorm:"type:varchar(16);not null;uniqueIndex:uniq_coin_reactions_actor,priority:3"`
+	CreatedAt int64  `gorm:"not null"`
+}
+
+// CoinReactionCount is the number of reactions of a type to a coin.
+type CoinReactionCount struct {
+	CoinId   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Reaction string `gorm:"primaryKey;type:varchar(16)"`
+	Total    int64  `gorm:"not null"`
+}
@@ -0,0 +1,107 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGormCoinDAO_Reactions(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, "primary")
+	dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+	rollups := NewGormPokeRollupDAO(db)
+
+	doge, err := dao.Insert(ctx, Coin{
+		Name:     "doge",
+		PublicId: sql.NullString{String: "01ARYZ6S410000000000000001", Valid: true},
+	})
+	require.NoError(t, err)
+	pepe, err := dao.Insert(ctx, Coin{Name: "pepe"})
+	require.NoError(t, err)
+
+	require.NoError(t, dao.AddReaction(ctx, doge.Id, "fp:1", ReactionPoke))
+	require.NoError(t, dao.AddReaction(ctx, doge.Id, "fp:2", ReactionPoke))
+	require.NoError(t, dao.AddReaction(ctx, doge.Id, "fp:1", "rocket"))
+	require.NoError(t, dao.AddReaction(ctx, pepe.Id, "fp:1", "skull"))
+	// each actor adds each reaction once
+	assert.Equal(t, ErrDuplicateReaction, dao.AddReaction(ctx, doge.Id, "fp:1", ReactionPoke))
+	assert.Equal(t, ErrRecordNotFound, dao.AddReaction(ctx, 404, "fp:1", ReactionPoke))
+
+	found, err := dao.FindById(ctx, doge.Id)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), found.PopularityScore)
+	counts, err := dao.FindReactionCounts(ctx, []int64{doge.Id, pepe.Id, 404})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []CoinReactionCount{
+		{CoinId: doge.Id, Reaction: ReactionPoke, Total: 2},
+		{CoinId: doge.Id, Reaction: "rocket", Total: 1},
+		{CoinId: pepe.Id, Reaction: "skull", Total: 1},
+	}, counts)
+
+	// the unpoke withdraws the poke from the scores, the history and the rollups
+	require.NoError(t, dao.RemoveReaction(ctx, doge.Id, "fp:1", ReactionPoke))
+	require.NoError(t, dao.RemoveReaction(ctx, doge.Id, "fp:1", ReactionPoke))
+	require.NoError(t, dao.RemoveReaction(ctx, doge.Id, "fp:3", "fire"))
+	found, err = dao.FindById(ctx, doge.Id)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), found.PopularityScore)
+	assert.InDelta(t, 1, found.HotScore, 1e-3)
+	minutes, err := rollups.Find(ctx, doge.Id, GranularityMinute, 0, time.Now().Add(time.Minute).UnixMilli())
+	require.NoError(t, err)
+	var pokes int64
+	for _, m := range minutes {
+		pokes += m.Pokes
+	}
+	assert.Equal(t, int64(1), pokes)
+	revs, err := dao.FindRevisions(ctx, doge.PublicId.String, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, RevisionActionUnpoke, revs[len(revs)-1].Action)
+	counts, err = dao.FindReactionCounts(ctx, []int64{doge.Id})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []CoinReactionCount{
+		{CoinId: doge.Id, Reaction: ReactionPoke, Total: 1},
+		{CoinId: doge.Id, Reaction: "rocket", Total: 1},
+	}, counts)
+	// the actor may poke again
+	require.NoError(t, dao.AddReaction(ctx, doge.Id, "fp:1", ReactionPoke))
+
+	// the reactions go with the coin
+	require.NoError(t, dao.DeleteById(ctx, doge.Id))
+	counts, err = dao.FindReactionCounts(ctx, []int64{doge.Id})
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+	var cnt int64
+	require.NoError(t, db.Model(&CoinReaction{}).Where("coin_id = ?", doge.Id).Count(&cnt).Error)
+	assert.Zero(t, cnt)
+}
+
+func TestShardedCoinDAO_Reactions(t *testing.T) {
+	ctx := context.Background()
+	dbs := newSQLiteShards(t, 3)
+	dao := NewShardedCoinDAO(dbs, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+	var ids []int64
+	var want []CoinReactionCount
+	for i := range 6 {
+		c, err := dao.Insert(ctx, Coin{Name: fmt.Sprintf("coin-%d", i)})
+		require.NoError(t, err)
+		require.NoError(t, dao.AddReaction(ctx, c.Id, "fp:1", "rocket"))
+		ids = append(ids, c.Id)
+		want = append(want, CoinReactionCount{CoinId: c.Id, Reaction: "rocket", Total: 1})
+	}
+
+	counts, err := dao.FindReactionCounts(ctx, ids)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, want, counts)
+
+	require.NoError(t, dao.RemoveReaction(ctx, ids[0], "fp:1", "rocket"))
+	counts, err = dao.FindReactionCounts(ctx, ids[:1])
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
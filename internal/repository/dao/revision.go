@@ -0,0 +1,57 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+)
+
+// The actions recorded in the history of a coin.
+const (
+	RevisionActionCreate = "create"
+	RevisionActionUpdate = "update"
+	RevisionActionPoke   = "poke"
+	RevisionActionUnpoke = "unpoke"
+	RevisionActionDelete = "delete"
+	// RevisionActionSnapshot is the state of a coin when its history began.
+	RevisionActionSnapshot = "snapshot"
+)
+
+// recordRevision copies the current row of the coin into its history, call it in the transaction
+// of the change. The row lock taken by the change serializes the numbering of the revisions.
+func (dao *GormCoinDAO) recordRevision(ctx context.Context, id int64, action string, validFrom int64) error {
+	return dao.writer(ctx).Exec(`INSERT INTO coin_revisions (coin_id, public_id, revision, action, name, description,
+    popularity_score, created_at, updated_at, valid_from)
+SELECT c.id, c.public_id, r.next, ?, c.name, c.description, c.popularity_score, c.created_at, c.updated_at, ?
+FROM coins c, (SELECT COALESCE(MAX(revision), 0) + 1 AS next FROM coin_revisions WHERE coin_id = ?) r
+WHERE c.id = ?`, action, validFrom, id, id).Error
+}
+
+func (dao *GormCoinDAO) FindRevisions(ctx context.Context, publicId string, after int64, limit int) ([]CoinRevision, error) {
+	var res []CoinRevision
+	err := dao.reader(ctx).Where("public_id = ? AND revision > ?", publicId, after).
+		Order("revision").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GormCoinDAO) FindRevisionAsOf(ctx context.Context, publicId string, at int64) (CoinRevision, error) {
+	var res CoinRevision
+	err := dao.reader(ctx).Where("public_id = ? AND valid_from <= ?", publicId, at).
+		Order("revision DESC").First(&res).Error
+	return res, translateError(err)
+}
+
+// CoinRevision is a state of a coin, it is written with every change and never modified.
+type CoinRevision struct {
+	Id              int64          `gorm:"primaryKey;autoIncrement"`
+	CoinId          int64          `gorm:"not null;uniqueIndex:uniq_coin_revisions_coin_id,priority:1"`
+	PublicId        sql.NullString `gorm:"type:char(26);index:idx_coin_revisions_public_id,priority:1"`
+	Revision        int64          `gorm:"not null;uniqueIndex:uniq_coin_revisions_coin_id,priority:2;index:idx_coin_revisions_public_id,priority:2"`
+	Action          string         `gorm:"type:varchar(32);not null"`
+	Name            string         `gorm:"type:varchar(255);not null"`
+	Description     sql.NullString `gorm:"type:varchar(128)"`
+	PopularityScore uint32         `gorm:"not null"`
+	CreatedAt       int64
+	UpdatedAt       int64
+	// ValidFrom is when the coin took this state, it holds until the next revision.
+	ValidFrom int64 `gorm:"not null"`
+}
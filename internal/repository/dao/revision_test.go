@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGormCoinDAO_Revisions(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, "primary")
+	dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+	publicId := sql.NullString{String: "01ARYZ6S410000000000000001", Valid: true}
+
+	doge, err := dao.Insert(ctx, Coin{Name: "doge", PublicId: publicId})
+	require.NoError(t, err)
+	require.NoError(t, dao.UpdateById(ctx, Coin{
+		Id:          doge.Id,
+		Description: sql.NullString{String: "much wow", Valid: true},
+	}))
+	require.NoError(t, dao.AddReaction(ctx, doge.Id, "fp:1", ReactionPoke))
+	require.NoError(t, dao.DeleteById(ctx, doge.Id))
+	// a failed write records nothing
+	assert.Equal(t, ErrRecordNotFound, dao.AddReaction(ctx, doge.Id, "fp:2", ReactionPoke))
+
+	// spread the revisions in time, the writes above share the same millisecond
+	for i := int64(1); i <= 4; i++ {
+		require.NoError(t, db.Model(&CoinRevision{}).Where("revision = ?", i).
+			Update("valid_from", i*1000).Error)
+	}
+
+	revs, err := dao.FindRevisions(ctx, publicId.String, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, revs, 4)
+	for i, rev := range revs {
+		assert.Equal(t, int64(i+1), rev.Revision)
+		assert.Equal(t, doge.Id, rev.CoinId)
+		assert.Equal(t, "doge", rev.Name)
+	}
+	assert.Equal(t, []string{RevisionActionCreate, RevisionActionUpdate, RevisionActionPoke, RevisionActionDelete},
+		[]string{revs[0].Action, revs[1].Action, revs[2].Action, revs[3].Action})
+	assert.False(t, revs[0].Description.Valid)
+	assert.Equal(t, "much wow", revs[1].Description.String)
+	assert.Equal(t, uint32(0), revs[1].PopularityScore)
+	assert.Equal(t, uint32(1), revs[2].PopularityScore)
+	// the delete revision keeps the last state of the coin
+	assert.Equal(t, uint32(1), revs[3].PopularityScore)
+
+	revs, err = dao.FindRevisions(ctx, publicId.String, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	assert.Equal(t, int64(3), revs[0].Revision)
+
+	_, err = dao.FindRevisionAsOf(ctx, publicId.String, 999)
+	assert.Equal(t, ErrRecordNotFound, err)
+	rev, err := dao.FindRevisionAsOf(ctx, publicId.String, 2500)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), rev.Revision)
+	rev, err = dao.FindRevisionAsOf(ctx, publicId.String, 3000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rev.Revision)
+	rev, err = dao.FindRevisionAsOf(ctx, publicId.String, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, RevisionActionDelete, rev.Action)
+}
+
+func TestGormCoinDAO_RevisionsInTx(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, "primary")
+	dao := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+	doge, err := dao.Insert(ctx, Coin{Name: "doge"})
+	require.NoError(t, err)
+
+	// the revision is rolled back with the change
+	err = NewGormTransactor().InTx(ctx, func(ctx context.Context) error {
+		if err := dao.AddReaction(ctx, doge.Id, "fp:1", ReactionPoke); err != nil {
+			return err
+		}
+		return assert.AnError
+	})
+	assert.Equal(t, assert.AnError, err)
+	var cnt int64
+	require.NoError(t, db.Model(&CoinRevision{}).Where("coin_id = ?", doge.Id).Count(&cnt).Error)
+	assert.Equal(t, int64(1), cnt)
+}
@@ -0,0 +1,123 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// The granularities of the poke rollups, pokes are counted by minute
+// and compacted into the coarser ones.
+const (
+	GranularityMinute = "minute"
+	GranularityHour   = "hour"
+	GranularityDay    = "day"
+)
+
+// bucketWidths are the widths of the buckets of each granularity in milliseconds.
+var bucketWidths = map[string]int64{
+	GranularityMinute: 60 * 1000,
+	GranularityHour:   60 * 60 * 1000,
+	GranularityDay:    24 * 60 * 60 * 1000,
+}
+
+// finerGranularities is the granularity each one is compacted from.
+var finerGranularities = map[string]string{
+	GranularityHour: GranularityMinute,
+	GranularityDay:  GranularityHour,
+}
+
+//go:generate mockgen -source=./rollup.go -package=daomocks -destination=./mocks/rollup.mock.go PokeRollupDAO
+type PokeRollupDAO interface {
+	// Find returns the non-empty buckets of the coin starting in [from, to), in order.
+	Find(ctx context.Context, coinId int64, granularity string, from, to int64) ([]CoinPokeRollup, error)
+	// Compact recomputes the buckets of granularity starting at since or later
+	// from the finer granularity. It is idempotent, so instances may run it concurrently.
+	Compact(ctx context.Context, granularity string, since int64) error
+	// DeleteBefore drops the buckets of granularity starting before the unix milli before.
+	DeleteBefore(ctx context.Context, granularity string, before int64) (int64, error)
+}
+
+type GormPokeRollupDAO struct {
+	db *gorm.DB
+}
+
+func NewGormPokeRollupDAO(db *gorm.DB) PokeRollupDAO {
+	return &GormPokeRollupDAO{
+		db: db,
+	}
+}
+
+func (dao *GormPokeRollupDAO) Find(ctx context.Context, coinId int64, granularity string, from, to int64) ([]CoinPokeRollup, error) {
+	var res []CoinPokeRollup
+	err := dao.db.WithContext(ctx).
+		Where("coin_id = ? AND granularity = ? AND bucket >= ? AND bucket < ?", coinId, granularity, from, to).
+		Order("bucket").Find(&res).Error
+	return res, err
+}
+
+func (dao *GormPokeRollupDAO) Compact(ctx context.Context, granularity string, since int64) error {
+	finer, ok := finerGranularities[granularity]
+	if !ok {
+		return fmt.Errorf("granularity %s is not compacted", granularity)
+	}
+	width := bucketWidths[granularity]
+	since -= since % width
+	start := fmt.Sprintf("bucket - bucket %% %d", width)
+	var rollups []CoinPokeRollup
+	err := dao.db.WithContext(ctx).Model(&CoinPokeRollup{}).
+		Select("coin_id, ? AS granularity, "+start+" AS bucket, SUM(pokes) AS pokes", granularity).
+		Where("granularity = ? AND bucket >= ?", finer, since).
+		Group("coin_id, " + start).
+		Find(&rollups).Error
+	if err != nil || len(rollups) == 0 {
+		return err
+	}
+	// the sums replace the buckets, the finer ones keep every poke until their retention expires
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "coin_id"}, {Name: "granularity"}, {Name: "bucket"}},
+		DoUpdates: clause.AssignmentColumns([]string{"pokes"}),
+	}).CreateInBatches(rollups, 500).Error
+}
+
+func (dao *GormPokeRollupDAO) DeleteBefore(ctx context.Context, granularity string, before int64) (int64, error) {
+	res := dao.db.WithContext(ctx).Where("granularity = ? AND bucket < ?", granularity, before).
+		Delete(&CoinPokeRollup{})
+	return res.RowsAffected, res.Error
+}
+
+// recordPoke counts a poke of the coin in the minute bucket of at, call it in the transaction of the poke.
+func (dao *GormCoinDAO) recordPoke(ctx context.Context, id int64, at int64) error {
+	width := bucketWidths[GranularityMinute]
+	return dao.writer(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "coin_id"}, {Name: "granularity"}, {Name: "bucket"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"pokes": gorm.Expr("coin_poke_rollups.pokes + ?", 1),
+		}),
+	}).Create(&CoinPokeRollup{
+		CoinId:      id,
+		Granularity: GranularityMinute,
+		Bucket:      at - at%width,
+		Pokes:       1,
+	}).Error
+}
+
+// withdrawPoke uncounts a poke made at the unix milli at from its minute bucket, call it in the
+// transaction of the unpoke. The hour and day buckets follow at their next compaction,
+// unless the poke is older than the lookback of the compaction.
+func (dao *GormCoinDAO) withdrawPoke(ctx context.Context, id int64, at int64) error {
+	width := bucketWidths[GranularityMinute]
+	return dao.writer(ctx).Model(&CoinPokeRollup{}).
+		Where("coin_id = ? AND granularity = ? AND bucket = ? AND pokes > 0", id, GranularityMinute, at-at%width).
+		UpdateColumn("pokes", gorm.Expr("pokes - ?", 1)).Error
+}
+
+// CoinPokeRollup counts the pokes of a coin in the bucket of a granularity.
+type CoinPokeRollup struct {
+	CoinId      int64  `gorm:"primaryKey;autoIncrement:false"`
+	Granularity string `gorm:"primaryKey;type:varchar(8);index:idx_coin_poke_rollups_bucket,priority:1"`
+	// Bucket is the unix milli of the start of the bucket.
+	Bucket int64 `gorm:"primaryKey;autoIncrement:false;index:idx_coin_poke_rollups_bucket,priority:2"`
+	Pokes  int64 `gorm:"not null"`
+}
@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGormPokeRollupDAO(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t, "primary")
+	coins := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+	rollups := NewGormPokeRollupDAO(db)
+
+	doge, err := coins.Insert(ctx, Coin{Name: "doge"})
+	require.NoError(t, err)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, coins.AddReaction(ctx, doge.Id, fmt.Sprintf("fp:%d", i), ReactionPoke))
+	}
+	// the pokes above may straddle a minute
+	minutes, err := rollups.Find(ctx, doge.Id, GranularityMinute, 0, time.Now().Add(time.Minute).UnixMilli())
+	require.NoError(t, err)
+	var total int64
+	for _, m := range minutes {
+		assert.Zero(t, m.Bucket%time.Minute.Milliseconds())
+		assert.LessOrEqual(t, m.Bucket, start.UnixMilli())
+		total += m.Pokes
+	}
+	assert.Equal(t, int64(3), total)
+
+	// pokes of another coin spread over two days
+	const hour = int64(60 * 60 * 1000)
+	day := 24 * hour
+	for _, r := range []CoinPokeRollup{
+		{CoinId: 1, Granularity: GranularityMinute, Bucket: day + 5*60*1000, Pokes: 2},
+		{CoinId: 1, Granularity: GranularityMinute, Bucket: day + 50*60*1000, Pokes: 3},
+		{CoinId: 1, Granularity: GranularityMinute, Bucket: day + hour, Pokes: 4},
+		{CoinId: 1, Granularity: GranularityMinute, Bucket: 2*day + 3*hour, Pokes: 1},
+	} {
+		require.NoError(t, db.Create(&r).Error)
+	}
+	// compacting twice gives the same buckets
+	for i := 0; i < 2; i++ {
+		require.NoError(t, rollups.Compact(ctx, GranularityHour, day+10*60*1000))
+		require.NoError(t, rollups.Compact(ctx, GranularityDay, day))
+	}
+	hours, err := rollups.Find(ctx, 1, GranularityHour, 0, 3*day)
+	require.NoError(t, err)
+	assert.Equal(t, []CoinPokeRollup{
+		{CoinId: 1, Granularity: GranularityHour, Bucket: day, Pokes: 5},
+		{CoinId: 1, Granularity: GranularityHour, Bucket: day + hour, Pokes: 4},
+		{CoinId: 1, Granularity: GranularityHour, Bucket: 2*day + 3*hour, Pokes: 1},
+	}, hours)
+	days, err := rollups.Find(ctx, 1, GranularityDay, 0, 3*day)
+	require.NoError(t, err)
+	assert.Equal(t, []CoinPokeRollup{
+		{CoinId: 1, Granularity: GranularityDay, Bucket: day, Pokes: 9},
+		{CoinId: 1, Granularity: GranularityDay, Bucket: 2 * day, Pokes: 1},
+	}, days)
+	assert.Error(t, rollups.Compact(ctx, GranularityMinute, 0))
+
+	n, err := rollups.DeleteBefore(ctx, GranularityMinute, 2*day)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	minutes, err = rollups.Find(ctx, 1, GranularityMinute, 0, 3*day)
+	require.NoError(t, err)
+	assert.Len(t, minutes, 1)
+	// the coarser buckets outlive the finer ones
+	days, err = rollups.Find(ctx, 1, GranularityDay, 0, 3*day)
+	require.NoError(t, err)
+	assert.Len(t, days, 2)
+}
+
+func TestShardedPokeRollupDAO(t *testing.T) {
+	ctx := context.Background()
+	dbs := newSQLiteShards(t, 3)
+	coins := NewShardedCoinDAO(dbs, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+	rollups := NewShardedPokeRollupDAO(dbs)
+
+	var ids []int64
+	for _, name := range []string{"doge", "pepe", "shib", "bonk"} {
+		c, err := coins.Insert(ctx, Coin{Name: name})
+		require.NoError(t, err)
+		require.NoError(t, coins.AddReaction(ctx, c.Id, "fp:1", ReactionPoke))
+		ids = append(ids, c.Id)
+	}
+	require.NoError(t, rollups.Compact(ctx, GranularityHour, 0))
+	for _, id := range ids {
+		hours, err := rollups.Find(ctx, id, GranularityHour, 0, time.Now().Add(time.Hour).UnixMilli())
+		require.NoError(t, err)
+		require.Len(t, hours, 1)
+		assert.Equal(t, int64(1), hours[0].Pokes)
+	}
+	n, err := rollups.DeleteBefore(ctx, GranularityMinute, time.Now().Add(time.Minute).UnixMilli())
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(ids)), n)
+}
@@ -0,0 +1,395 @@
+package dao
+
+import (
+	"cmp"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/pkg/hotscore"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"hash/fnv"
+	"slices"
+)
+
+// ShardedCoinDAO spreads the coins across databases by the hash of their id.
+// Ids come from the IdGenerator so they are unique across shards, and the names
+// are reserved in the coin_names table of the shard their hash routes to.
+// The number of shards must not change once coins are stored, it would move them.
+type ShardedCoinDAO struct {
+	dbs    []*gorm.DB
+	shards []*GormCoinDAO
+	ids    IdGenerator
+	l      logger.Logger
+}
+
+func NewShardedCoinDAO(dbs []*gorm.DB, ids IdGenerator, hot hotscore.Model, l logger.Logger) CoinDAO {
+	shards := make([]*GormCoinDAO, 0, len(dbs))
+	for _, db := range dbs {
+		shards = append(shards, &GormCoinDAO{
+			db:  db,
+			ids: ids,
+			hot: hot,
+			l:   l,
+		})
+	}
+	return &ShardedCoinDAO{
+		dbs:    dbs,
+		shards: shards,
+		ids:    ids,
+		l:      l,
+	}
+}
+
+func (dao *ShardedCoinDAO) Insert(ctx context.Context, c Coin) (Coin, error) {
+	if c.Id == 0 {
+		c.Id = dao.ids.NextId()
+	}
+	if err := dao.reserveName(ctx, c.Name, c.Id); err != nil {
+		return Coin{}, err
+	}
+	res, err := dao.byId(c.Id).Insert(ctx, c)
+	if err != nil {
+		dao.releaseName(ctx, c.Name, c.Id)
+		return Coin{}, err
+	}
+	return res, nil
+}
+
+func (dao *ShardedCoinDAO) UpdateById(ctx context.Context, entity Coin) error {
+	return dao.byId(entity.Id).UpdateById(ctx, entity)
+}
+
+func (dao *ShardedCoinDAO) FindById(ctx context.Context, id int64) (Coin, error) {
+	return dao.byId(id).FindById(ctx, id)
+}
+
+func (dao *ShardedCoinDAO) FindByIdForUpdate(ctx context.Context, id int64) (Coin, error) {
+	return dao.byId(id).FindByIdForUpdate(ctx, id)
+}
+
+// FindByPublicId looks the coin up on every shard, public ids do not route to a shard.
+func (dao *ShardedCoinDAO) FindByPublicId(ctx context.Context, publicId string) (Coin, error) {
+	_, c, err := dao.locate(ctx, publicId)
+	return c, err
+}
+
+func (dao *ShardedCoinDAO) FindByPublicIdForUpdate(ctx context.Context, publicId string) (Coin, error) {
+	shard, c, err := dao.locate(withoutTx(ctx), publicId)
+	if err != nil {
+		return Coin{}, err
+	}
+	// the public id of a coin never changes, only the row of its shard needs the lock
+	return shard.FindByIdForUpdate(ctx, c.Id)
+}
+
+// locate finds the shard holding the coin of publicId by querying them all concurrently.
+func (dao *ShardedCoinDAO) locate(ctx context.Context, publicId string) (*GormCoinDAO, Coin, error) {
+	found := make([]Coin, len(dao.shards))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, shard := range dao.shards {
+		eg.Go(func() error {
+			c, err := shard.FindByPublicId(egCtx, publicId)
+			if errors.Is(err, ErrRecordNotFound) {
+				return nil
+			}
+			found[i] = c
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, Coin{}, err
+	}
+	for i, c := range found {
+		if c.Id != 0 {
+			return dao.shards[i], c, nil
+		}
+	}
+	return nil, Coin{}, ErrRecordNotFound
+}
+
+// DeleteById releases the name of the coin once it is deleted.
+func (dao *ShardedCoinDAO) DeleteById(ctx context.Context, id int64) error {
+	shard := dao.byId(id)
+	c, err := shard.FindByIdForUpdate(ctx, id)
+	if errors.Is(err, ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = shard.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	db, _ := dbFromContext(ctx, dao.dbs[shardOf(nameKey(c.Name), len(dao.dbs))])
+	return db.Where("name = ? AND coin_id = ?", c.Name, c.Id).Delete(&CoinName{}).Error
+}
+
+func (dao *ShardedCoinDAO) AddReaction(ctx context.Context, id int64, actor, reaction string) error {
+	return dao.byId(id).AddReaction(ctx, id, actor, reaction)
+}
+
+func (dao *ShardedCoinDAO) RemoveReaction(ctx context.Context, id int64, actor, reaction string) error {
+	return dao.byId(id).RemoveReaction(ctx, id, actor, reaction)
+}
+
+// FindReactionCounts asks each shard for the counts of its coins.
+func (dao *ShardedCoinDAO) FindReactionCounts(ctx context.Context, ids []int64) ([]CoinReactionCount, error) {
+	byShard := make([][]int64, len(dao.shards))
+	for _, id := range ids {
+		i := shardOf(idKey(id), len(dao.shards))
+		byShard[i] = append(byShard[i], id)
+	}
+	results := make([][]CoinReactionCount, len(dao.shards))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, shard := range dao.shards {
+		if len(byShard[i]) == 0 {
+			continue
+		}
+		eg.Go(func() error {
+			counts, err := shard.FindReactionCounts(egCtx, byShard[i])
+			results[i] = counts
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return slices.Concat(results...), nil
+}
+
+func (dao *ShardedCoinDAO) FindTopByPopularity(ctx context.Context, limit int) ([]Coin, error) {
+	return dao.gather(ctx, limit, (*GormCoinDAO).FindTopByPopularity, func(a, b Coin) int {
+		if c := cmp.Compare(b.PopularityScore, a.PopularityScore); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Id, a.Id)
+	})
+}
+
+func (dao *ShardedCoinDAO) FindTopByHotScore(ctx context.Context, limit int) ([]Coin, error) {
+	return dao.gather(ctx, limit, (*GormCoinDAO).FindTopByHotScore, func(a, b Coin) int {
+		if c := cmp.Compare(b.HotScore, a.HotScore); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Id, a.Id)
+	})
+}
+
+func (dao *ShardedCoinDAO) FindRecent(ctx context.Context, limit int) ([]Coin, error) {
+	return dao.gather(ctx, limit, (*GormCoinDAO).FindRecent, func(a, b Coin) int {
+		if c := cmp.Compare(b.CreatedAt, a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Id, a.Id)
+	})
+}
+
+// FindRevisions asks every shard, the revisions of a coin all live on the shard of the coin.
+func (dao *ShardedCoinDAO) FindRevisions(ctx context.Context, publicId string, after int64, limit int) ([]CoinRevision, error) {
+	results := make([][]CoinRevision, len(dao.shards))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, shard := range dao.shards {
+		eg.Go(func() error {
+			revs, err := shard.FindRevisions(egCtx, publicId, after, limit)
+			results[i] = revs
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return slices.Concat(results...), nil
+}
+
+func (dao *ShardedCoinDAO) FindRevisionAsOf(ctx context.Context, publicId string, at int64) (CoinRevision, error) {
+	found := make([]CoinRevision, len(dao.shards))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, shard := range dao.shards {
+		eg.Go(func() error {
+			rev, err := shard.FindRevisionAsOf(egCtx, publicId, at)
+			if errors.Is(err, ErrRecordNotFound) {
+				return nil
+			}
+			found[i] = rev
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return CoinRevision{}, err
+	}
+	for _, rev := range found {
+		if rev.Id != 0 {
+			return rev, nil
+		}
+	}
+	return CoinRevision{}, ErrRecordNotFound
+}
+
+func (dao *ShardedCoinDAO) RefreshHotScores(ctx context.Context, now int64, batchSize int) (int64, error) {
+	var updated int64
+	for _, shard := range dao.shards {
+		n, err := shard.RefreshHotScores(ctx, now, batchSize)
+		updated += n
+		if err != nil {
+			return updated, err
+		}
+	}
+	return updated, nil
+}
+
+// gather runs find on every shard concurrently and merges their results,
+// each shard returns its first limit coins in the order of compare.
+func (dao *ShardedCoinDAO) gather(ctx context.Context, limit int,
+	find func(dao *GormCoinDAO, ctx context.Context, limit int) ([]Coin, error),
+	compare func(a, b Coin) int) ([]Coin, error) {
+	results := make([][]Coin, len(dao.shards))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, shard := range dao.shards {
+		eg.Go(func() error {
+			coins, err := find(shard, egCtx, limit)
+			results[i] = coins
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	res := slices.Concat(results...)
+	slices.SortFunc(res, compare)
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+// reserveName claims name for the coin id on the shard of the name,
+// it fails with ErrDuplicateName when another coin holds it.
+func (dao *ShardedCoinDAO) reserveName(ctx context.Context, name string, id int64) error {
+	db, _ := dbFromContext(ctx, dao.dbs[shardOf(nameKey(name), len(dao.dbs))])
+	// the name is the primary key of coin_names, any unique violation is a taken name
+	err := db.Create(&CoinName{Name: name, CoinId: id}).Error
+	if isUniqueViolation(err) {
+		return ErrDuplicateName
+	}
+	return err
+}
+
+// releaseName undoes reserveName after a failed insert, a transaction of ctx
+// rolls it back anyway.
+func (dao *ShardedCoinDAO) releaseName(ctx context.Context, name string, id int64) {
+	db, _ := dbFromContext(ctx, dao.dbs[shardOf(nameKey(name), len(dao.dbs))])
+	err := db.Where("name = ? AND coin_id = ?", name, id).Delete(&CoinName{}).Error
+	if err != nil {
+		dao.l.Error("failed to release coin name after failed insert",
+			logger.String("name", name),
+			logger.Int64("coin_id", id),
+			logger.Error(err))
+	}
+}
+
+func (dao *ShardedCoinDAO) byId(id int64) *GormCoinDAO {
+	return dao.shards[shardOf(idKey(id), len(dao.shards))]
+}
+
+// ShardedCoinAuditDAO stores the audits of a coin on the shard of the coin.
+type ShardedCoinAuditDAO struct {
+	shards []CoinAuditDAO
+}
+
+func NewShardedCoinAuditDAO(dbs []*gorm.DB) CoinAuditDAO {
+	shards := make([]CoinAuditDAO, 0, len(dbs))
+	for _, db := range dbs {
+		shards = append(shards, NewGormCoinAuditDAO(db))
+	}
+	return &ShardedCoinAuditDAO{
+		shards: shards,
+	}
+}
+
+func (dao *ShardedCoinAuditDAO) Insert(ctx context.Context, a CoinAudit) error {
+	return dao.shards[shardOf(idKey(a.CoinId), len(dao.shards))].Insert(ctx, a)
+}
+
+// ShardedOutboxDAO stores the events of an aggregate on the shard of the aggregate,
+// a relay publishes the outbox of every shard.
+type ShardedOutboxDAO struct {
+	shards []OutboxDAO
+}
+
+func NewShardedOutboxDAO(dbs []*gorm.DB) OutboxDAO {
+	shards := make([]OutboxDAO, 0, len(dbs))
+	for _, db := range dbs {
+		shards = append(shards, NewGormOutboxDAO(db))
+	}
+	return &ShardedOutboxDAO{
+		shards: shards,
+	}
+}
+
+func (dao *ShardedOutboxDAO) Insert(ctx context.Context, e OutboxEvent) error {
+	return dao.shards[shardOf(idKey(e.AggregateId), len(dao.shards))].Insert(ctx, e)
+}
+
+// ShardedPokeRollupDAO keeps the rollups of a coin on the shard of the coin,
+// compaction and retention run on every shard.
+type ShardedPokeRollupDAO struct {
+	shards []PokeRollupDAO
+}
+
+func NewShardedPokeRollupDAO(dbs []*gorm.DB) PokeRollupDAO {
+	shards := make([]PokeRollupDAO, 0, len(dbs))
+	for _, db := range dbs {
+		shards = append(shards, NewGormPokeRollupDAO(db))
+	}
+	return &ShardedPokeRollupDAO{
+		shards: shards,
+	}
+}
+
+func (dao *ShardedPokeRollupDAO) Find(ctx context.Context, coinId int64, granularity string, from, to int64) ([]CoinPokeRollup, error) {
+	return dao.shards[shardOf(idKey(coinId), len(dao.shards))].Find(ctx, coinId, granularity, from, to)
+}
+
+func (dao *ShardedPokeRollupDAO) Compact(ctx context.Context, granularity string, since int64) error {
+	for _, shard := range dao.shards {
+		if err := shard.Compact(ctx, granularity, since); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dao *ShardedPokeRollupDAO) DeleteBefore(ctx context.Context, granularity string, before int64) (int64, error) {
+	var total int64
+	for _, shard := range dao.shards {
+		n, err := shard.DeleteBefore(ctx, granularity, before)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// shardOf returns the shard of a key among n shards.
+func shardOf(key []byte, n int) int {
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	return int(h.Sum64() % uint64(n))
+}
+
+func idKey(id int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
+
+func nameKey(name string) []byte {
+	return []byte(name)
+}
+
+// CoinName reserves a coin name across the shards, see ShardedCoinDAO.
+type CoinName struct {
+	Name   string `gorm:"type:varchar(255);primaryKey"`
+	CoinId int64  `gorm:"not null"`
+}
@@ -0,0 +1,197 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func newSQLiteShards(t *testing.T, n int) []*gorm.DB {
+	dbs := make([]*gorm.DB, 0, n)
+	for i := 0; i < n; i++ {
+		dbs = append(dbs, newSQLiteDB(t, fmt.Sprintf("shard%d", i)))
+	}
+	return dbs
+}
+
+func countCoins(t *testing.T, db *gorm.DB) int64 {
+	var cnt int64
+	require.NoError(t, db.Model(&Coin{}).Count(&cnt).Error)
+	return cnt
+}
+
+func TestShardedCoinDAO(t *testing.T) {
+	ctx := context.Background()
+	dbs := newSQLiteShards(t, 3)
+	dao := NewShardedCoinDAO(dbs, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+	var coins []Coin
+	for i := 0; i < 30; i++ {
+		c, err := dao.Insert(ctx, Coin{
+			Name:     fmt.Sprintf("coin-%d", i),
+			PublicId: sql.NullString{String: fmt.Sprintf("public-%d", i), Valid: true},
+		})
+		require.NoError(t, err)
+		coins = append(coins, c)
+	}
+	// every coin is stored on the shard of its id only
+	var total int64
+	for i, db := range dbs {
+		cnt := countCoins(t, db)
+		assert.NotZero(t, cnt, "shard %d is empty", i)
+		total += cnt
+	}
+	assert.Equal(t, int64(len(coins)), total)
+	for _, c := range coins {
+		var found Coin
+		db := dbs[shardOf(idKey(c.Id), len(dbs))]
+		require.NoError(t, db.Where("id = ?", c.Id).First(&found).Error)
+		assert.Equal(t, c.Name, found.Name)
+	}
+
+	// names are unique across shards
+	_, err := dao.Insert(ctx, Coin{Name: "coin-7"})
+	assert.Equal(t, ErrDuplicateName, err)
+	assert.Equal(t, int64(len(coins)), countCoins(t, dbs[0])+countCoins(t, dbs[1])+countCoins(t, dbs[2]))
+
+	for _, c := range coins[:5] {
+		require.NoError(t, dao.AddReaction(ctx, c.Id, "fp:1", ReactionPoke))
+	}
+	require.NoError(t, dao.AddReaction(ctx, coins[3].Id, "fp:2", ReactionPoke))
+	top, err := dao.FindTopByPopularity(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, top, 3)
+	// ties on the score are ordered by id
+	assert.Equal(t, []int64{coins[3].Id, coins[4].Id, coins[2].Id}, []int64{top[0].Id, top[1].Id, top[2].Id})
+
+	recent, err := dao.FindRecent(ctx, 50)
+	require.NoError(t, err)
+	assert.Len(t, recent, len(coins))
+	for i := 1; i < len(recent); i++ {
+		assert.GreaterOrEqual(t, recent[i-1].CreatedAt, recent[i].CreatedAt)
+	}
+
+	found, err := dao.FindById(ctx, coins[7].Id)
+	require.NoError(t, err)
+	assert.Equal(t, "coin-7", found.Name)
+	found, err = dao.FindByPublicId(ctx, "public-7")
+	require.NoError(t, err)
+	assert.Equal(t, coins[7].Id, found.Id)
+	_, err = dao.FindByPublicId(ctx, "public-404")
+	assert.Equal(t, ErrRecordNotFound, err)
+	err = NewGormTransactor().InTx(ctx, func(ctx context.Context) error {
+		found, err = dao.FindByPublicIdForUpdate(ctx, "public-8")
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, coins[8].Id, found.Id)
+
+	// a deleted coin releases its name
+	require.NoError(t, dao.DeleteById(ctx, coins[7].Id))
+	require.NoError(t, dao.DeleteById(ctx, coins[7].Id))
+	// and keeps its history on its shard
+	revs, err := dao.FindRevisions(ctx, "public-7", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	assert.Equal(t, RevisionActionDelete, revs[1].Action)
+	rev, err := dao.FindRevisionAsOf(ctx, "public-7", revs[0].ValidFrom)
+	require.NoError(t, err)
+	assert.Equal(t, coins[7].Id, rev.CoinId)
+	_, err = dao.FindRevisionAsOf(ctx, "public-404", revs[0].ValidFrom)
+	assert.Equal(t, ErrRecordNotFound, err)
+	_, err = dao.FindById(ctx, coins[7].Id)
+	assert.Equal(t, ErrRecordNotFound, err)
+	_, err = dao.Insert(ctx, Coin{Name: "coin-7"})
+	assert.NoError(t, err)
+}
+
+func TestShardedCoinDAO_HotScore(t *testing.T) {
+	ctx := context.Background()
+	dbs := newSQLiteShards(t, 3)
+	dao := NewShardedCoinDAO(dbs, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+
+	var ids []int64
+	for i := range 6 {
+		c, err := dao.Insert(ctx, Coin{Name: fmt.Sprintf("coin-%d", i)})
+		require.NoError(t, err)
+		for j := range i {
+			require.NoError(t, dao.AddReaction(ctx, c.Id, fmt.Sprintf("fp:%d", j), ReactionPoke))
+		}
+		ids = append(ids, c.Id)
+	}
+
+	hot, err := dao.FindTopByHotScore(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, hot, 3)
+	assert.Equal(t, []int64{ids[5], ids[4], ids[3]}, []int64{hot[0].Id, hot[1].Id, hot[2].Id})
+
+	// every poked coin of every shard is refreshed
+	n, err := dao.RefreshHotScores(ctx, time.Now().Add(time.Hour).UnixMilli(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+}
+
+func TestShardedCoinDAO_InTx(t *testing.T) {
+	ctx := context.Background()
+	dbs := newSQLiteShards(t, 2)
+	coins := NewShardedCoinDAO(dbs, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+	audits := NewShardedCoinAuditDAO(dbs)
+	tx := NewGormTransactor()
+
+	// the reservation of the name and the coin may land on different shards,
+	// both are rolled back
+	err := tx.InTx(ctx, func(ctx context.Context) error {
+		for i := 0; i < 10; i++ {
+			c, err := coins.Insert(ctx, Coin{Name: fmt.Sprintf("coin-%d", i)})
+			if err != nil {
+				return err
+			}
+			if err = audits.Insert(ctx, CoinAudit{CoinId: c.Id, Action: "create"}); err != nil {
+				return err
+			}
+		}
+		return errors.New("mock error")
+	})
+	assert.Equal(t, errors.New("mock error"), err)
+	for _, db := range dbs {
+		assert.Zero(t, countCoins(t, db))
+		var cnt int64
+		require.NoError(t, db.Model(&CoinName{}).Count(&cnt).Error)
+		assert.Zero(t, cnt)
+	}
+
+	var created Coin
+	err = tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = coins.Insert(ctx, Coin{Name: "doge"})
+		if err != nil {
+			return err
+		}
+		return audits.Insert(ctx, CoinAudit{CoinId: created.Id, Action: "create"})
+	})
+	require.NoError(t, err)
+	var audit CoinAudit
+	db := dbs[shardOf(idKey(created.Id), len(dbs))]
+	require.NoError(t, db.Where("coin_id = ?", created.Id).First(&audit).Error)
+	_, err = coins.Insert(ctx, Coin{Name: "doge"})
+	assert.Equal(t, ErrDuplicateName, err)
+}
+
+func TestShardOf(t *testing.T) {
+	// the routing must never change, it would lose the stored coins
+	assert.Equal(t, 2, shardOf(idKey(1), 4))
+	assert.Equal(t, 0, shardOf(nameKey("doge"), 4))
+	for _, n := range []int{1, 2, 3, 8} {
+		for id := int64(0); id < 100; id++ {
+			s := shardOf(idKey(id), n)
+			assert.True(t, s >= 0 && s < n)
+		}
+	}
+}
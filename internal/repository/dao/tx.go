@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"sync"
+)
+
+// Transactor runs functions in a transaction carried by their context,
+// the DAOs called with that context join the transaction.
+//
+// A transaction is begun lazily on each database the DAOs touch. The changes
+// of a coin all land on the shard of the coin, but a function touching several
+// shards commits them one after another and is not atomic across them.
+type Transactor interface {
+	// InTx commits when fn returns nil and rolls back when it fails or panics,
+	// the panic is propagated. A nested call joins the outer transaction.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type txState struct {
+	// ctx begins the transactions, it outlives the contexts of the calls joining them
+	ctx context.Context
+
+	mu          sync.Mutex
+	txs         map[*gorm.DB]*gorm.DB
+	order       []*gorm.DB
+	afterCommit []func()
+}
+
+// begin returns the transaction on db, beginning it on first use.
+func (s *txState) begin(db *gorm.DB) *gorm.DB {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tx, ok := s.txs[db]; ok {
+		return tx
+	}
+	tx := db.WithContext(s.ctx).Begin()
+	if tx.Error != nil {
+		// the failed db carries the error into the statement of the caller
+		return tx
+	}
+	s.txs[db] = tx
+	s.order = append(s.order, tx)
+	return tx
+}
+
+// commit commits the transactions in the order they began, the ones following
+// a failed commit are rolled back.
+func (s *txState) commit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, tx := range s.order {
+		if err := tx.Commit().Error; err != nil {
+			for _, rest := range s.order[i+1:] {
+				rest.Rollback()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *txState) rollback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tx := range s.order {
+		tx.Rollback()
+	}
+}
+
+type GormTransactor struct{}
+
+func NewGormTransactor() Transactor {
+	return &GormTransactor{}
+}
+
+func (t *GormTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if state, _ := ctx.Value(txKey{}).(*txState); state != nil {
+		return fn(ctx)
+	}
+	state := &txState{
+		ctx: ctx,
+		txs: make(map[*gorm.DB]*gorm.DB),
+	}
+	done := false
+	defer func() {
+		// a failed or panicking fn, the panic keeps unwinding after the rollback
+		if !done {
+			state.rollback()
+		}
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		return err
+	}
+	done = true
+	if err := state.commit(); err != nil {
+		return err
+	}
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+// inTx runs fn in the transaction of ctx, or in a new one when ctx carries none.
+func inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return (&GormTransactor{}).InTx(ctx, fn)
+}
+
+// AfterCommit runs fn once the transaction of ctx commits, it is dropped on rollback.
+// fn runs immediately when ctx carries no transaction.
+func AfterCommit(ctx context.Context, fn func()) {
+	if state, _ := ctx.Value(txKey{}).(*txState); state != nil {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+// withoutTx returns ctx detached from its transaction, for reads that must not begin one.
+func withoutTx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txKey{}, (*txState)(nil))
+}
+
+// dbFromContext returns the transaction carried by ctx on db, or db when there is none.
+func dbFromContext(ctx context.Context, db *gorm.DB) (*gorm.DB, bool) {
+	if state, _ := ctx.Value(txKey{}).(*txState); state != nil {
+		return state.begin(db).WithContext(ctx), true
+	}
+	return db.WithContext(ctx), false
+}
@@ -0,0 +1,122 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGormTransactor_InTx(t *testing.T) {
+	testCases := []struct {
+		name string
+		fn   func(ctx context.Context, coins CoinDAO, audits CoinAuditDAO) error
+
+		wantErr       error
+		wantPanic     bool
+		wantCommitted bool
+	}{
+		{
+			name: "commit",
+			fn: func(ctx context.Context, coins CoinDAO, audits CoinAuditDAO) error {
+				c, err := coins.Insert(ctx, Coin{Name: "doge"})
+				if err != nil {
+					return err
+				}
+				return audits.Insert(ctx, CoinAudit{CoinId: c.Id, Action: "create"})
+			},
+			wantCommitted: true,
+		},
+		{
+			name: "rollback on error",
+			fn: func(ctx context.Context, coins CoinDAO, audits CoinAuditDAO) error {
+				if _, err := coins.Insert(ctx, Coin{Name: "doge"}); err != nil {
+					return err
+				}
+				return errors.New("audit failed")
+			},
+			wantErr: errors.New("audit failed"),
+		},
+		{
+			name: "rollback on panic",
+			fn: func(ctx context.Context, coins CoinDAO, audits CoinAuditDAO) error {
+				if _, err := coins.Insert(ctx, Coin{Name: "doge"}); err != nil {
+					return err
+				}
+				panic("boom")
+			},
+			wantPanic: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newSQLiteDB(t, "primary")
+			coins := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+			audits := NewGormCoinAuditDAO(db)
+			tx := NewGormTransactor()
+
+			hooked := false
+			run := func() error {
+				return tx.InTx(context.Background(), func(ctx context.Context) error {
+					AfterCommit(ctx, func() {
+						hooked = true
+					})
+					return tc.fn(ctx, coins, audits)
+				})
+			}
+			if tc.wantPanic {
+				assert.Panics(t, func() {
+					_ = run()
+				})
+			} else {
+				assert.Equal(t, tc.wantErr, run())
+			}
+
+			var coinCnt, auditCnt int64
+			require.NoError(t, db.Model(&Coin{}).Count(&coinCnt).Error)
+			require.NoError(t, db.Model(&CoinAudit{}).Count(&auditCnt).Error)
+			if tc.wantCommitted {
+				assert.Equal(t, int64(1), coinCnt)
+				assert.Equal(t, int64(1), auditCnt)
+			} else {
+				assert.Zero(t, coinCnt)
+				assert.Zero(t, auditCnt)
+			}
+			assert.Equal(t, tc.wantCommitted, hooked)
+		})
+	}
+}
+
+func TestGormTransactor_Nested(t *testing.T) {
+	db := newSQLiteDB(t, "primary")
+	coins := NewGormCoinDAO(db, newIdGenerator(t), newHotScoreModel(), logger.NewNopLogger())
+	tx := NewGormTransactor()
+
+	err := tx.InTx(context.Background(), func(ctx context.Context) error {
+		err := tx.InTx(ctx, func(ctx context.Context) error {
+			_, err := coins.Insert(ctx, Coin{Name: "doge"})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		// the outer failure also rolls back the inner work, it joined the transaction
+		return errors.New("outer failed")
+	})
+	assert.Equal(t, errors.New("outer failed"), err)
+
+	var cnt int64
+	require.NoError(t, db.Model(&Coin{}).Count(&cnt).Error)
+	assert.Zero(t, cnt)
+}
+
+func TestAfterCommit_WithoutTx(t *testing.T) {
+	called := false
+	AfterCommit(context.Background(), func() {
+		called = true
+	})
+	assert.True(t, called)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./audit.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./audit.go -package=repomocks -destination=./mocks/audit.mock.go CoinAuditRepository
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/miles0wu/meme-coin-api/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCoinAuditRepository is a mock of CoinAuditRepository interface.
+type MockCoinAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCoinAuditRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCoinAuditRepositoryMockRecorder is the mock recorder for MockCoinAuditRepository.
+type MockCoinAuditRepositoryMockRecorder struct {
+	mock *MockCoinAuditRepository
+}
+
+// NewMockCoinAuditRepository creates a new mock instance.
+func NewMockCoinAuditRepository(ctrl *gomock.Controller) *MockCoinAuditRepository {
+	mock := &MockCoinAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockCoinAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCoinAuditRepository) EXPECT() *MockCoinAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockCoinAuditRepository) Record(ctx context.Context, a domain.CoinAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockCoinAuditRepositoryMockRecorder) Record(ctx, a any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockCoinAuditRepository)(nil).Record), ctx, a)
+}
@@ -12,6 +12,7 @@ package repomocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/miles0wu/meme-coin-api/internal/domain"
 	gomock "go.uber.org/mock/gomock"
@@ -41,6 +42,20 @@ func (m *MockCoinRepository) EXPECT() *MockCoinRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddReaction mocks base method.
+func (m *MockCoinRepository) AddReaction(ctx context.Context, publicId, actor string, reaction domain.ReactionType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReaction", ctx, publicId, actor, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddReaction indicates an expected call of AddReaction.
+func (mr *MockCoinRepositoryMockRecorder) AddReaction(ctx, publicId, actor, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReaction", reflect.TypeOf((*MockCoinRepository)(nil).AddReaction), ctx, publicId, actor, reaction)
+}
+
 // Create mocks base method.
 func (m *MockCoinRepository) Create(ctx context.Context, coin domain.Coin) (domain.Coin, error) {
 	m.ctrl.T.Helper()
@@ -56,47 +71,166 @@ func (mr *MockCoinRepositoryMockRecorder) Create(ctx, coin any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCoinRepository)(nil).Create), ctx, coin)
 }
 
-// DeleteById mocks base method.
-func (m *MockCoinRepository) DeleteById(ctx context.Context, id int64) error {
+// Delete mocks base method.
+func (m *MockCoinRepository) Delete(ctx context.Context, coin domain.Coin) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteById", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, coin)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteById indicates an expected call of DeleteById.
-func (mr *MockCoinRepositoryMockRecorder) DeleteById(ctx, id any) *gomock.Call {
+// Delete indicates an expected call of Delete.
+func (mr *MockCoinRepositoryMockRecorder) Delete(ctx, coin any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockCoinRepository)(nil).DeleteById), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCoinRepository)(nil).Delete), ctx, coin)
 }
 
-// FindById mocks base method.
-func (m *MockCoinRepository) FindById(ctx context.Context, id int64) (domain.Coin, error) {
+// FindByPublicId mocks base method.
+func (m *MockCoinRepository) FindByPublicId(ctx context.Context, publicId string) (domain.Coin, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret := m.ctrl.Call(m, "FindByPublicId", ctx, publicId)
 	ret0, _ := ret[0].(domain.Coin)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindById indicates an expected call of FindById.
-func (mr *MockCoinRepositoryMockRecorder) FindById(ctx, id any) *gomock.Call {
+// FindByPublicId indicates an expected call of FindByPublicId.
+func (mr *MockCoinRepositoryMockRecorder) FindByPublicId(ctx, publicId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPublicId", reflect.TypeOf((*MockCoinRepository)(nil).FindByPublicId), ctx, publicId)
+}
+
+// FindByPublicIdForUpdate mocks base method.
+func (m *MockCoinRepository) FindByPublicIdForUpdate(ctx context.Context, publicId string) (domain.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPublicIdForUpdate", ctx, publicId)
+	ret0, _ := ret[0].(domain.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPublicIdForUpdate indicates an expected call of FindByPublicIdForUpdate.
+func (mr *MockCoinRepositoryMockRecorder) FindByPublicIdForUpdate(ctx, publicId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPublicIdForUpdate", reflect.TypeOf((*MockCoinRepository)(nil).FindByPublicIdForUpdate), ctx, publicId)
+}
+
+// FindRecent mocks base method.
+func (m *MockCoinRepository) FindRecent(ctx context.Context, limit int) ([]domain.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRecent", ctx, limit)
+	ret0, _ := ret[0].([]domain.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRecent indicates an expected call of FindRecent.
+func (mr *MockCoinRepositoryMockRecorder) FindRecent(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRecent", reflect.TypeOf((*MockCoinRepository)(nil).FindRecent), ctx, limit)
+}
+
+// FindRevisionAsOf mocks base method.
+func (m *MockCoinRepository) FindRevisionAsOf(ctx context.Context, publicId string, at time.Time) (domain.CoinRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevisionAsOf", ctx, publicId, at)
+	ret0, _ := ret[0].(domain.CoinRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevisionAsOf indicates an expected call of FindRevisionAsOf.
+func (mr *MockCoinRepositoryMockRecorder) FindRevisionAsOf(ctx, publicId, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevisionAsOf", reflect.TypeOf((*MockCoinRepository)(nil).FindRevisionAsOf), ctx, publicId, at)
+}
+
+// FindRevisions mocks base method.
+func (m *MockCoinRepository) FindRevisions(ctx context.Context, publicId string, after int64, limit int) ([]domain.CoinRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevisions", ctx, publicId, after, limit)
+	ret0, _ := ret[0].([]domain.CoinRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevisions indicates an expected call of FindRevisions.
+func (mr *MockCoinRepositoryMockRecorder) FindRevisions(ctx, publicId, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevisions", reflect.TypeOf((*MockCoinRepository)(nil).FindRevisions), ctx, publicId, after, limit)
+}
+
+// FindTopByHotScore mocks base method.
+func (m *MockCoinRepository) FindTopByHotScore(ctx context.Context, limit int) ([]domain.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTopByHotScore", ctx, limit)
+	ret0, _ := ret[0].([]domain.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTopByHotScore indicates an expected call of FindTopByHotScore.
+func (mr *MockCoinRepositoryMockRecorder) FindTopByHotScore(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTopByHotScore", reflect.TypeOf((*MockCoinRepository)(nil).FindTopByHotScore), ctx, limit)
+}
+
+// FindTopByPopularity mocks base method.
+func (m *MockCoinRepository) FindTopByPopularity(ctx context.Context, limit int) ([]domain.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTopByPopularity", ctx, limit)
+	ret0, _ := ret[0].([]domain.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTopByPopularity indicates an expected call of FindTopByPopularity.
+func (mr *MockCoinRepositoryMockRecorder) FindTopByPopularity(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTopByPopularity", reflect.TypeOf((*MockCoinRepository)(nil).FindTopByPopularity), ctx, limit)
+}
+
+// Preload mocks base method.
+func (m *MockCoinRepository) Preload(ctx context.Context, coins []domain.Coin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preload", ctx, coins)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Preload indicates an expected call of Preload.
+func (mr *MockCoinRepositoryMockRecorder) Preload(ctx, coins any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preload", reflect.TypeOf((*MockCoinRepository)(nil).Preload), ctx, coins)
+}
+
+// RefreshHotScores mocks base method.
+func (m *MockCoinRepository) RefreshHotScores(ctx context.Context, now time.Time, batchSize int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshHotScores", ctx, now, batchSize)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshHotScores indicates an expected call of RefreshHotScores.
+func (mr *MockCoinRepositoryMockRecorder) RefreshHotScores(ctx, now, batchSize any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockCoinRepository)(nil).FindById), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshHotScores", reflect.TypeOf((*MockCoinRepository)(nil).RefreshHotScores), ctx, now, batchSize)
 }
 
-// IncrPopularityScore mocks base method.
-func (m *MockCoinRepository) IncrPopularityScore(ctx context.Context, id int64) error {
+// RemoveReaction mocks base method.
+func (m *MockCoinRepository) RemoveReaction(ctx context.Context, publicId, actor string, reaction domain.ReactionType) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrPopularityScore", ctx, id)
+	ret := m.ctrl.Call(m, "RemoveReaction", ctx, publicId, actor, reaction)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// IncrPopularityScore indicates an expected call of IncrPopularityScore.
-func (mr *MockCoinRepositoryMockRecorder) IncrPopularityScore(ctx, id any) *gomock.Call {
+// RemoveReaction indicates an expected call of RemoveReaction.
+func (mr *MockCoinRepositoryMockRecorder) RemoveReaction(ctx, publicId, actor, reaction any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrPopularityScore", reflect.TypeOf((*MockCoinRepository)(nil).IncrPopularityScore), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReaction", reflect.TypeOf((*MockCoinRepository)(nil).RemoveReaction), ctx, publicId, actor, reaction)
 }
 
 // Update mocks base method.
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./score.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./score.go -package=repomocks -destination=./mocks/score.mock.go ScoreHistoryRepository
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/miles0wu/meme-coin-api/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockScoreHistoryRepository is a mock of ScoreHistoryRepository interface.
+type MockScoreHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockScoreHistoryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockScoreHistoryRepositoryMockRecorder is the mock recorder for MockScoreHistoryRepository.
+type MockScoreHistoryRepositoryMockRecorder struct {
+	mock *MockScoreHistoryRepository
+}
+
+// NewMockScoreHistoryRepository creates a new mock instance.
+func NewMockScoreHistoryRepository(ctrl *gomock.Controller) *MockScoreHistoryRepository {
+	mock := &MockScoreHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockScoreHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScoreHistoryRepository) EXPECT() *MockScoreHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Compact mocks base method.
+func (m *MockScoreHistoryRepository) Compact(ctx context.Context, interval domain.ScoreInterval, since time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compact", ctx, interval, since)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Compact indicates an expected call of Compact.
+func (mr *MockScoreHistoryRepositoryMockRecorder) Compact(ctx, interval, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compact", reflect.TypeOf((*MockScoreHistoryRepository)(nil).Compact), ctx, interval, since)
+}
+
+// DeleteBefore mocks base method.
+func (m *MockScoreHistoryRepository) DeleteBefore(ctx context.Context, interval domain.ScoreInterval, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, interval, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockScoreHistoryRepositoryMockRecorder) DeleteBefore(ctx, interval, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockScoreHistoryRepository)(nil).DeleteBefore), ctx, interval, before)
+}
+
+// FindBuckets mocks base method.
+func (m *MockScoreHistoryRepository) FindBuckets(ctx context.Context, coinId int64, interval domain.ScoreInterval, from, to time.Time) ([]domain.ScoreBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBuckets", ctx, coinId, interval, from, to)
+	ret0, _ := ret[0].([]domain.ScoreBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBuckets indicates an expected call of FindBuckets.
+func (mr *MockScoreHistoryRepositoryMockRecorder) FindBuckets(ctx, coinId, interval, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBuckets", reflect.TypeOf((*MockScoreHistoryRepository)(nil).FindBuckets), ctx, coinId, interval, from, to)
+}
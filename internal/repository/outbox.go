@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+)
+
+//go:generate mockgen -source=./outbox.go -package=repomocks -destination=./mocks/outbox.mock.go OutboxRepository
+type OutboxRepository interface {
+	// Append stores evt in the outbox, call it in the transaction of the change it describes.
+	Append(ctx context.Context, evt domain.CoinEvent) error
+}
+
+type outboxRepository struct {
+	dao dao.OutboxDAO
+}
+
+func NewOutboxRepository(dao dao.OutboxDAO) OutboxRepository {
+	return &outboxRepository{
+		dao: dao,
+	}
+}
+
+func (repo *outboxRepository) Append(ctx context.Context, evt domain.CoinEvent) error {
+	payload, err := json.Marshal(newCoinSnapshot(evt.Coin))
+	if err != nil {
+		return err
+	}
+	return repo.dao.Insert(ctx, dao.OutboxEvent{
+		AggregateType: "coin",
+		AggregateId:   evt.Coin.Id,
+		EventType:     string(evt.Type),
+		Payload:       string(payload),
+	})
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	daomocks "github.com/miles0wu/meme-coin-api/internal/repository/dao/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestOutboxRepository_Append(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) dao.OutboxDAO
+
+		event domain.CoinEvent
+
+		wantErr error
+	}{
+		{
+			name: "append success",
+			mock: func(ctrl *gomock.Controller) dao.OutboxDAO {
+				outboxDAO := daomocks.NewMockOutboxDAO(ctrl)
+				outboxDAO.EXPECT().Insert(gomock.Any(), dao.OutboxEvent{
+					AggregateType: "coin",
+					AggregateId:   1,
+					EventType:     "coin.created",
+					Payload:       `{"id":1,"publicId":"01ARYZ6S410000000000000001","name":"doge","description":"","createdAt":1700000000000,"updatedAt":1700000000000,"popularityScore":0}`,
+				}).Return(nil)
+				return outboxDAO
+			},
+			event: domain.CoinEvent{
+				Type: domain.CoinEventCreated,
+				Coin: domain.Coin{Id: 1, PublicId: "01ARYZ6S410000000000000001", Name: "doge", CreatedAt: now, UpdatedAt: now},
+			},
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) dao.OutboxDAO {
+				outboxDAO := daomocks.NewMockOutboxDAO(ctrl)
+				outboxDAO.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(errors.New("mock db error"))
+				return outboxDAO
+			},
+			event: domain.CoinEvent{
+				Type: domain.CoinEventDeleted,
+				Coin: domain.Coin{Id: 1},
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewOutboxRepository(tc.mock(ctrl))
+			err := repo.Append(context.Background(), tc.event)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
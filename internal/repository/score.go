@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	"time"
+)
+
+//go:generate mockgen -source=./score.go -package=repomocks -destination=./mocks/score.mock.go ScoreHistoryRepository
+type ScoreHistoryRepository interface {
+	// FindBuckets returns the non-empty buckets of the coin starting in [from, to), in order.
+	FindBuckets(ctx context.Context, coinId int64, interval domain.ScoreInterval, from, to time.Time) ([]domain.ScoreBucket, error)
+	// Compact recomputes the buckets of interval starting at since or later from the finer ones,
+	// pokes are only recorded by minute.
+	Compact(ctx context.Context, interval domain.ScoreInterval, since time.Time) error
+	// DeleteBefore drops the buckets of interval starting before before.
+	DeleteBefore(ctx context.Context, interval domain.ScoreInterval, before time.Time) (int64, error)
+}
+
+type scoreHistoryRepository struct {
+	dao dao.PokeRollupDAO
+}
+
+func NewScoreHistoryRepository(dao dao.PokeRollupDAO) ScoreHistoryRepository {
+	return &scoreHistoryRepository{
+		dao: dao,
+	}
+}
+
+func (repo *scoreHistoryRepository) FindBuckets(ctx context.Context, coinId int64, interval domain.ScoreInterval,
+	from, to time.Time) ([]domain.ScoreBucket, error) {
+	rollups, err := repo.dao.Find(ctx, coinId, string(interval), from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.ScoreBucket, 0, len(rollups))
+	for _, r := range rollups {
+		res = append(res, domain.ScoreBucket{
+			Start: time.UnixMilli(r.Bucket),
+			Pokes: r.Pokes,
+		})
+	}
+	return res, nil
+}
+
+func (repo *scoreHistoryRepository) Compact(ctx context.Context, interval domain.ScoreInterval, since time.Time) error {
+	return repo.dao.Compact(ctx, string(interval), since.UnixMilli())
+}
+
+func (repo *scoreHistoryRepository) DeleteBefore(ctx context.Context, interval domain.ScoreInterval, before time.Time) (int64, error) {
+	return repo.dao.DeleteBefore(ctx, string(interval), before.UnixMilli())
+}
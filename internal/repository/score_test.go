@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	daomocks "github.com/miles0wu/meme-coin-api/internal/repository/dao/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestScoreHistoryRepository_FindBuckets(t *testing.T) {
+	from := time.UnixMilli(1700000000000)
+	to := from.Add(2 * time.Hour)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) dao.PokeRollupDAO
+
+		wantRet []domain.ScoreBucket
+		wantErr error
+	}{
+		{
+			name: "find success",
+			mock: func(ctrl *gomock.Controller) dao.PokeRollupDAO {
+				rollupDAO := daomocks.NewMockPokeRollupDAO(ctrl)
+				rollupDAO.EXPECT().Find(gomock.Any(), int64(1), dao.GranularityHour, from.UnixMilli(), to.UnixMilli()).
+					Return([]dao.CoinPokeRollup{
+						{CoinId: 1, Granularity: dao.GranularityHour, Bucket: from.UnixMilli(), Pokes: 3},
+					}, nil)
+				return rollupDAO
+			},
+			wantRet: []domain.ScoreBucket{
+				{Start: from, Pokes: 3},
+			},
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) dao.PokeRollupDAO {
+				rollupDAO := daomocks.NewMockPokeRollupDAO(ctrl)
+				rollupDAO.EXPECT().Find(gomock.Any(), int64(1), dao.GranularityHour, from.UnixMilli(), to.UnixMilli()).
+					Return(nil, errors.New("mock db error"))
+				return rollupDAO
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewScoreHistoryRepository(tc.mock(ctrl))
+			ret, err := repo.FindBuckets(context.Background(), 1, domain.ScoreIntervalHour, from, to)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestScoreHistoryRepository_Compact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	since := time.UnixMilli(1700000000000)
+	rollupDAO := daomocks.NewMockPokeRollupDAO(ctrl)
+	rollupDAO.EXPECT().Compact(gomock.Any(), dao.GranularityDay, since.UnixMilli()).Return(nil)
+	rollupDAO.EXPECT().DeleteBefore(gomock.Any(), dao.GranularityMinute, since.UnixMilli()).Return(int64(4), nil)
+
+	repo := NewScoreHistoryRepository(rollupDAO)
+	assert.NoError(t, repo.Compact(context.Background(), domain.ScoreIntervalDay, since))
+	n, err := repo.DeleteBefore(context.Background(), domain.ScoreIntervalMinute, since)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+}
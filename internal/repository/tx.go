@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+)
+
+// Transactor composes repository operations atomically, the operations
+// called with the context passed to fn run in the same transaction.
+//
+//go:generate mockgen -source=./tx.go -package=repomocks -destination=./mocks/tx.mock.go Transactor
+type Transactor interface {
+	// InTx commits when fn returns nil and rolls back when it fails or panics.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
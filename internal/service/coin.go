@@ -2,50 +2,233 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/miles0wu/meme-coin-api/internal/domain"
 	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/pkg/ulid"
+	"time"
 )
 
 var (
 	ErrDuplicateName = repository.ErrDuplicateName
 	ErrNotFound      = repository.ErrNotFound
+	ErrUnknownSort   = errors.New("unknown coin sort")
+	// ErrDuplicateReaction is returned when the actor already added the reaction to the coin.
+	ErrDuplicateReaction = repository.ErrDuplicateReaction
+	ErrUnknownReaction   = errors.New("unknown reaction")
 )
 
 //go:generate mockgen -source=./coin.go -package=svcmocks -destination=./mocks/coin.mock.go CoinService
 type CoinService interface {
 	Create(ctx context.Context, coin domain.Coin) (domain.Coin, error)
 	Update(ctx context.Context, coin domain.Coin) error
-	GetById(ctx context.Context, id int64) (domain.Coin, error)
-	DeleteById(ctx context.Context, id int64) error
-	IncrPopularityScore(ctx context.Context, id int64) error
+	GetByPublicId(ctx context.Context, publicId string) (domain.Coin, error)
+	DeleteByPublicId(ctx context.Context, publicId string) error
+	// React adds the reaction of the actor, a client id or fingerprint, to the coin.
+	// A poke also raises the popularity and hot scores of the coin.
+	React(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) error
+	// Unreact withdraws the reaction of the actor from the coin, it succeeds when there is none.
+	Unreact(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) error
+	// List returns the first limit coins in the order of sort.
+	List(ctx context.Context, sort domain.CoinSort, limit int) ([]domain.Coin, error)
+	// GetByPublicIdAsOf returns the coin as it was at the time at,
+	// it fails with ErrNotFound when the coin did not exist then.
+	GetByPublicIdAsOf(ctx context.Context, publicId string, at time.Time) (domain.Coin, error)
+	// ListRevisions returns the first limit revisions of the coin following the revision after,
+	// with the changes of each one. A deleted coin keeps its revisions.
+	ListRevisions(ctx context.Context, publicId string, after int64, limit int) ([]domain.CoinRevision, error)
 }
 
-func NewCoinService(repo repository.CoinRepository) CoinService {
+func NewCoinService(repo repository.CoinRepository, audits repository.CoinAuditRepository,
+	outbox repository.OutboxRepository, tx repository.Transactor) CoinService {
 	return &coinService{
-		repo: repo,
+		repo:        repo,
+		audits:      audits,
+		outbox:      outbox,
+		tx:          tx,
+		newPublicId: ulid.New,
 	}
 }
 
+// coinService records an audit and an outbox event with every change of a coin,
+// in the transaction of the change.
 type coinService struct {
-	repo repository.CoinRepository
+	repo   repository.CoinRepository
+	audits repository.CoinAuditRepository
+	outbox repository.OutboxRepository
+	tx     repository.Transactor
+	// newPublicId returns the opaque id exposed in place of the internal one,
+	// which would leak the growth rate and invite enumeration.
+	newPublicId func() string
 }
 
 func (svc *coinService) Create(ctx context.Context, coin domain.Coin) (domain.Coin, error) {
-	return svc.repo.Create(ctx, coin)
+	if err := validateCreate(coin); err != nil {
+		return domain.Coin{}, err
+	}
+	coin.PublicId = svc.newPublicId()
+	var created domain.Coin
+	err := svc.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = svc.repo.Create(ctx, coin)
+		if err != nil {
+			return err
+		}
+		return svc.record(ctx, domain.AuditActionCreate, nil, &created)
+	})
+	if err != nil {
+		return domain.Coin{}, err
+	}
+	return created, nil
 }
 
+// Update modifies the description of the coin of coin.PublicId, the other fields of coin are ignored.
 func (svc *coinService) Update(ctx context.Context, coin domain.Coin) error {
-	return svc.repo.Update(ctx, coin)
+	if err := validateUpdate(coin); err != nil {
+		return err
+	}
+	return svc.tx.InTx(ctx, func(ctx context.Context) error {
+		before, err := svc.repo.FindByPublicIdForUpdate(ctx, coin.PublicId)
+		if err != nil {
+			return err
+		}
+		after := before
+		after.Description = coin.Description
+		after.UpdatedAt = time.Now()
+		if err = svc.repo.Update(ctx, after); err != nil {
+			return err
+		}
+		return svc.record(ctx, domain.AuditActionUpdate, &before, &after)
+	})
+}
+
+func (svc *coinService) GetByPublicId(ctx context.Context, publicId string) (domain.Coin, error) {
+	return svc.repo.FindByPublicId(ctx, publicId)
+}
+
+// DeleteByPublicId is idempotent, deleting a missing coin succeeds without recording anything.
+func (svc *coinService) DeleteByPublicId(ctx context.Context, publicId string) error {
+	return svc.tx.InTx(ctx, func(ctx context.Context) error {
+		before, err := svc.repo.FindByPublicIdForUpdate(ctx, publicId)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = svc.repo.Delete(ctx, before); err != nil {
+			return err
+		}
+		return svc.record(ctx, domain.AuditActionDelete, &before, nil)
+	})
+}
+
+func (svc *coinService) React(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) error {
+	if !reaction.Valid() {
+		return ErrUnknownReaction
+	}
+	return svc.repo.AddReaction(ctx, publicId, actor, reaction)
+}
+
+func (svc *coinService) Unreact(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) error {
+	if !reaction.Valid() {
+		return ErrUnknownReaction
+	}
+	return svc.repo.RemoveReaction(ctx, publicId, actor, reaction)
+}
+
+func (svc *coinService) List(ctx context.Context, sort domain.CoinSort, limit int) ([]domain.Coin, error) {
+	switch sort {
+	case domain.CoinSortHot:
+		return svc.repo.FindTopByHotScore(ctx, limit)
+	case domain.CoinSortPopular:
+		return svc.repo.FindTopByPopularity(ctx, limit)
+	case domain.CoinSortRecent:
+		return svc.repo.FindRecent(ctx, limit)
+	default:
+		return nil, ErrUnknownSort
+	}
 }
 
-func (svc *coinService) GetById(ctx context.Context, id int64) (domain.Coin, error) {
-	return svc.repo.FindById(ctx, id)
+func (svc *coinService) GetByPublicIdAsOf(ctx context.Context, publicId string, at time.Time) (domain.Coin, error) {
+	rev, err := svc.repo.FindRevisionAsOf(ctx, publicId, at)
+	if err != nil {
+		return domain.Coin{}, err
+	}
+	if rev.Action == domain.RevisionActionDelete {
+		return domain.Coin{}, ErrNotFound
+	}
+	return rev.Coin, nil
+}
+
+func (svc *coinService) ListRevisions(ctx context.Context, publicId string, after int64, limit int) ([]domain.CoinRevision, error) {
+	// the revision after is read as well to diff the first one against it
+	from, n := after, limit
+	if after > 0 {
+		from, n = after-1, limit+1
+	}
+	revs, err := svc.repo.FindRevisions(ctx, publicId, from, n)
+	if err != nil {
+		return nil, err
+	}
+	if len(revs) == 0 && after == 0 {
+		return nil, ErrNotFound
+	}
+	var prev *domain.CoinRevision
+	if len(revs) > 0 && after > 0 && revs[0].Revision == after {
+		prev = &revs[0]
+		revs = revs[1:]
+	}
+	res := make([]domain.CoinRevision, 0, min(len(revs), limit))
+	for _, rev := range revs[:min(len(revs), limit)] {
+		if prev != nil {
+			rev.Changes = diffCoins(prev.Coin, rev.Coin)
+		}
+		res = append(res, rev)
+		prev = &rev
+	}
+	return res, nil
+}
+
+// diffCoins returns the fields of the coin changed from before to after.
+func diffCoins(before, after domain.Coin) []domain.FieldChange {
+	var res []domain.FieldChange
+	if before.Name != after.Name {
+		res = append(res, domain.FieldChange{Field: "name", From: before.Name, To: after.Name})
+	}
+	if before.Description != after.Description {
+		res = append(res, domain.FieldChange{Field: "description", From: before.Description, To: after.Description})
+	}
+	if before.PopularityScore != after.PopularityScore {
+		res = append(res, domain.FieldChange{Field: "popularityScore", From: before.PopularityScore, To: after.PopularityScore})
+	}
+	return res
 }
 
-func (svc *coinService) DeleteById(ctx context.Context, id int64) error {
-	return svc.repo.DeleteById(ctx, id)
+var coinEventTypes = map[domain.AuditAction]domain.CoinEventType{
+	domain.AuditActionCreate: domain.CoinEventCreated,
+	domain.AuditActionUpdate: domain.CoinEventUpdated,
+	domain.AuditActionDelete: domain.CoinEventDeleted,
 }
 
-func (svc *coinService) IncrPopularityScore(ctx context.Context, id int64) error {
-	return svc.repo.IncrPopularityScore(ctx, id)
+// record writes the audit and the outbox event of a change, call it in the transaction of the change.
+func (svc *coinService) record(ctx context.Context, action domain.AuditAction, before, after *domain.Coin) error {
+	// the event carries the state after the change, or before it for a deletion
+	coin := after
+	if coin == nil {
+		coin = before
+	}
+	err := svc.audits.Record(ctx, domain.CoinAudit{
+		CoinId: coin.Id,
+		Action: action,
+		Before: before,
+		After:  after,
+	})
+	if err != nil {
+		return err
+	}
+	return svc.outbox.Append(ctx, domain.CoinEvent{
+		Type: coinEventTypes[action],
+		Coin: *coin,
+	})
 }
@@ -8,15 +8,28 @@ import (
 	repomocks "github.com/miles0wu/meme-coin-api/internal/repository/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"strings"
 	"testing"
 	"time"
 )
 
+const publicId = "01ARYZ6S410000000000000001"
+
+// newTestTransactor runs the functions passed to InTx without a transaction.
+func newTestTransactor(ctrl *gomock.Controller) repository.Transactor {
+	tx := repomocks.NewMockTransactor(ctrl)
+	tx.EXPECT().InTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+	return tx
+}
+
 func Test_coinService_Create(t *testing.T) {
 	now := time.Now()
 	testCases := []struct {
 		name string
-		mock func(*gomock.Controller) repository.CoinRepository
+		mock func(*gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository)
 
 		coin domain.Coin
 
@@ -25,20 +38,34 @@ func Test_coinService_Create(t *testing.T) {
 	}{
 		{
 			name: "create success",
-			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().Create(gomock.Any(), domain.Coin{
-					Name:        "test",
-					Description: "test description",
-				}).Return(domain.Coin{
+				auditRepo := repomocks.NewMockCoinAuditRepository(ctrl)
+				outboxRepo := repomocks.NewMockOutboxRepository(ctrl)
+				created := domain.Coin{
 					Id:              1,
+					PublicId:        publicId,
 					Name:            "test",
 					Description:     "test description",
 					CreatedAt:       now,
 					UpdatedAt:       now,
 					PopularityScore: 0,
-				}, nil)
-				return coinRepo
+				}
+				coinRepo.EXPECT().Create(gomock.Any(), domain.Coin{
+					PublicId:    publicId,
+					Name:        "test",
+					Description: "test description",
+				}).Return(created, nil)
+				auditRepo.EXPECT().Record(gomock.Any(), domain.CoinAudit{
+					CoinId: 1,
+					Action: domain.AuditActionCreate,
+					After:  &created,
+				}).Return(nil)
+				outboxRepo.EXPECT().Append(gomock.Any(), domain.CoinEvent{
+					Type: domain.CoinEventCreated,
+					Coin: created,
+				}).Return(nil)
+				return coinRepo, auditRepo, outboxRepo
 			},
 			coin: domain.Coin{
 				Name:        "test",
@@ -46,6 +73,7 @@ func Test_coinService_Create(t *testing.T) {
 			},
 			wantRet: domain.Coin{
 				Id:              1,
+				PublicId:        publicId,
 				Name:            "test",
 				Description:     "test description",
 				CreatedAt:       now,
@@ -56,13 +84,14 @@ func Test_coinService_Create(t *testing.T) {
 		},
 		{
 			name: "duplicate name error",
-			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
 				coinRepo.EXPECT().Create(gomock.Any(), domain.Coin{
+					PublicId:    publicId,
 					Name:        "test",
 					Description: "test description",
 				}).Return(domain.Coin{}, repository.ErrDuplicateName)
-				return coinRepo
+				return coinRepo, repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
 			},
 			coin: domain.Coin{
 				Name:        "test",
@@ -73,13 +102,14 @@ func Test_coinService_Create(t *testing.T) {
 		},
 		{
 			name: "db error",
-			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
 				coinRepo.EXPECT().Create(gomock.Any(), domain.Coin{
+					PublicId:    publicId,
 					Name:        "test",
 					Description: "test description",
 				}).Return(domain.Coin{}, errors.New("mock db error"))
-				return coinRepo
+				return coinRepo, repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
 			},
 			coin: domain.Coin{
 				Name:        "test",
@@ -88,19 +118,80 @@ func Test_coinService_Create(t *testing.T) {
 			wantRet: domain.Coin{},
 			wantErr: errors.New("mock db error"),
 		},
+		{
+			name: "outbox error",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				auditRepo := repomocks.NewMockCoinAuditRepository(ctrl)
+				outboxRepo := repomocks.NewMockOutboxRepository(ctrl)
+				coinRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.Coin{Id: 1, Name: "test"}, nil)
+				auditRepo.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+				outboxRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(errors.New("mock db error"))
+				return coinRepo, auditRepo, outboxRepo
+			},
+			coin: domain.Coin{
+				Name: "test",
+			},
+			wantRet: domain.Coin{},
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "invalid fields",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				return repomocks.NewMockCoinRepository(ctrl), repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
+			},
+			coin: domain.Coin{
+				Name:        "  ",
+				Description: strings.Repeat("測", MaxDescriptionLength+1),
+			},
+			wantRet: domain.Coin{},
+			wantErr: &ValidationError{Fields: []FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "description", Message: "must be at most 128 characters"},
+			}},
+		},
+		{
+			name: "name too long",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				return repomocks.NewMockCoinRepository(ctrl), repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
+			},
+			coin: domain.Coin{
+				Name: strings.Repeat("a", MaxNameLength+1),
+			},
+			wantRet: domain.Coin{},
+			wantErr: &ValidationError{Fields: []FieldError{
+				{Field: "name", Message: "must be at most 255 characters"},
+			}},
+		},
+		{
+			name: "multi-byte name at limit",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				auditRepo := repomocks.NewMockCoinAuditRepository(ctrl)
+				outboxRepo := repomocks.NewMockOutboxRepository(ctrl)
+				coinRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.Coin{Id: 1}, nil)
+				auditRepo.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+				outboxRepo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil)
+				return coinRepo, auditRepo, outboxRepo
+			},
+			coin: domain.Coin{
+				Name: strings.Repeat("🐸", MaxNameLength),
+			},
+			wantRet: domain.Coin{Id: 1},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			coinRepo := tc.mock(ctrl)
-			svc := NewCoinService(coinRepo)
+			coinRepo, auditRepo, outboxRepo := tc.mock(ctrl)
+			svc := NewCoinService(coinRepo, auditRepo, outboxRepo, newTestTransactor(ctrl)).(*coinService)
+			svc.newPublicId = func() string {
+				return publicId
+			}
 			ret, err := svc.Create(context.Background(), tc.coin)
 			assert.Equal(t, tc.wantErr, err)
-			if err == nil {
-				return
-			}
 			assert.Equal(t, tc.wantRet, ret)
 		})
 	}
@@ -108,82 +199,136 @@ func Test_coinService_Create(t *testing.T) {
 
 func Test_coinService_Update(t *testing.T) {
 	now := time.Now()
+	before := domain.Coin{
+		Id:              1,
+		PublicId:        publicId,
+		Name:            "test",
+		Description:     "test description",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		PopularityScore: 3,
+	}
 	testCases := []struct {
 		name string
-		mock func(*gomock.Controller) repository.CoinRepository
+		mock func(*gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository)
 
 		coin    domain.Coin
 		wantErr error
 	}{
 		{
 			name: "update success",
-			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().Update(gomock.Any(), domain.Coin{
-					Id:              1,
-					Name:            "test",
-					Description:     "new test description",
-					CreatedAt:       now,
-					UpdatedAt:       now,
-					PopularityScore: 0,
-				}).Return(nil)
-				return coinRepo
+				auditRepo := repomocks.NewMockCoinAuditRepository(ctrl)
+				outboxRepo := repomocks.NewMockOutboxRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(before, nil)
+				coinRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, c domain.Coin) error {
+						// fields other than the description are kept from the locked row
+						assert.Equal(t, "new test description", c.Description)
+						assert.Equal(t, before.Name, c.Name)
+						assert.Equal(t, before.PopularityScore, c.PopularityScore)
+						return nil
+					})
+				auditRepo.EXPECT().Record(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, a domain.CoinAudit) error {
+						assert.Equal(t, domain.AuditActionUpdate, a.Action)
+						assert.Equal(t, &before, a.Before)
+						assert.Equal(t, "new test description", a.After.Description)
+						return nil
+					})
+				outboxRepo.EXPECT().Append(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, evt domain.CoinEvent) error {
+						assert.Equal(t, domain.CoinEventUpdated, evt.Type)
+						assert.Equal(t, "new test description", evt.Coin.Description)
+						return nil
+					})
+				return coinRepo, auditRepo, outboxRepo
 			},
 			coin: domain.Coin{
-				Id:              1,
-				Name:            "test",
-				Description:     "new test description",
-				CreatedAt:       now,
-				UpdatedAt:       now,
-				PopularityScore: 0,
+				PublicId:    publicId,
+				Description: "new test description",
 			},
 			wantErr: nil,
 		},
+		{
+			name: "id not found",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(domain.Coin{}, repository.ErrNotFound)
+				return coinRepo, repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
+			},
+			coin: domain.Coin{
+				PublicId:    publicId,
+				Description: "new test description",
+			},
+			wantErr: repository.ErrNotFound,
+		},
 		{
 			name: "db error",
-			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().Update(gomock.Any(), domain.Coin{
-					Id:              1,
-					Name:            "test",
-					Description:     "new test description",
-					CreatedAt:       now,
-					UpdatedAt:       now,
-					PopularityScore: 0,
-				}).Return(errors.New("mock db error"))
-				return coinRepo
+				coinRepo.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(before, nil)
+				coinRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("mock db error"))
+				return coinRepo, repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
 			},
 			coin: domain.Coin{
-				Id:              1,
-				Name:            "test",
-				Description:     "new test description",
-				CreatedAt:       now,
-				UpdatedAt:       now,
-				PopularityScore: 0,
+				PublicId:    publicId,
+				Description: "new test description",
+			},
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "audit error",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				auditRepo := repomocks.NewMockCoinAuditRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(before, nil)
+				coinRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+				auditRepo.EXPECT().Record(gomock.Any(), gomock.Any()).Return(errors.New("mock db error"))
+				return coinRepo, auditRepo, repomocks.NewMockOutboxRepository(ctrl)
+			},
+			coin: domain.Coin{
+				PublicId:    publicId,
+				Description: "new test description",
 			},
 			wantErr: errors.New("mock db error"),
 		},
+		{
+			name: "description too long",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				return repomocks.NewMockCoinRepository(ctrl), repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
+			},
+			coin: domain.Coin{
+				PublicId:    publicId,
+				Name:        "test",
+				Description: strings.Repeat("a", MaxDescriptionLength+1),
+			},
+			wantErr: &ValidationError{Fields: []FieldError{
+				{Field: "description", Message: "must be at most 128 characters"},
+			}},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			coinRepo := tc.mock(ctrl)
-			svc := NewCoinService(coinRepo)
+			coinRepo, auditRepo, outboxRepo := tc.mock(ctrl)
+			svc := NewCoinService(coinRepo, auditRepo, outboxRepo, newTestTransactor(ctrl))
 			err := svc.Update(context.Background(), tc.coin)
 			assert.Equal(t, tc.wantErr, err)
 		})
 	}
 }
 
-func Test_coinService_GetById(t *testing.T) {
+func Test_coinService_GetByPublicId(t *testing.T) {
 	now := time.Now()
 	testCases := []struct {
 		name string
 		mock func(*gomock.Controller) repository.CoinRepository
 
-		id int64
+		publicId string
 
 		wantRet domain.Coin
 		wantErr error
@@ -192,7 +337,7 @@ func Test_coinService_GetById(t *testing.T) {
 			name: "get success",
 			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Coin{
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{
 					Id:              1,
 					Name:            "test",
 					Description:     "test description",
@@ -202,7 +347,7 @@ func Test_coinService_GetById(t *testing.T) {
 				}, nil)
 				return coinRepo
 			},
-			id: 1,
+			publicId: publicId,
 			wantRet: domain.Coin{
 				Id:              1,
 				Name:            "test",
@@ -217,22 +362,22 @@ func Test_coinService_GetById(t *testing.T) {
 			name: "id not found",
 			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Coin{}, repository.ErrNotFound)
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{}, repository.ErrNotFound)
 				return coinRepo
 			},
-			id:      1,
-			wantErr: repository.ErrNotFound,
+			publicId: publicId,
+			wantErr:  repository.ErrNotFound,
 		},
 		{
 			name: "db error",
 			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Coin{}, errors.New("mock db error"))
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{}, errors.New("mock db error"))
 				return coinRepo
 			},
-			id:      1,
-			wantRet: domain.Coin{},
-			wantErr: errors.New("mock db error"),
+			publicId: publicId,
+			wantRet:  domain.Coin{},
+			wantErr:  errors.New("mock db error"),
 		},
 	}
 
@@ -241,8 +386,8 @@ func Test_coinService_GetById(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			coinRepo := tc.mock(ctrl)
-			svc := NewCoinService(coinRepo)
-			ret, err := svc.GetById(context.Background(), tc.id)
+			svc := NewCoinService(coinRepo, nil, nil, nil)
+			ret, err := svc.GetByPublicId(context.Background(), tc.publicId)
 			assert.Equal(t, tc.wantErr, err)
 			if err == nil {
 				return
@@ -252,35 +397,291 @@ func Test_coinService_GetById(t *testing.T) {
 	}
 }
 
-func Test_coinService_DeleteById(t *testing.T) {
+func Test_coinService_DeleteByPublicId(t *testing.T) {
+	coin := domain.Coin{Id: 1, PublicId: publicId, Name: "test"}
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository)
+
+		publicId string
+
+		wantErr error
+	}{
+		{
+			name: "delete success",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				auditRepo := repomocks.NewMockCoinAuditRepository(ctrl)
+				outboxRepo := repomocks.NewMockOutboxRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(coin, nil)
+				coinRepo.EXPECT().Delete(gomock.Any(), coin).Return(nil)
+				auditRepo.EXPECT().Record(gomock.Any(), domain.CoinAudit{
+					CoinId: 1,
+					Action: domain.AuditActionDelete,
+					Before: &coin,
+				}).Return(nil)
+				outboxRepo.EXPECT().Append(gomock.Any(), domain.CoinEvent{
+					Type: domain.CoinEventDeleted,
+					Coin: coin,
+				}).Return(nil)
+				return coinRepo, auditRepo, outboxRepo
+			},
+			publicId: publicId,
+			wantErr:  nil,
+		},
+		{
+			name: "already deleted",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(domain.Coin{}, repository.ErrNotFound)
+				return coinRepo, repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
+			},
+			publicId: publicId,
+			wantErr:  nil,
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.CoinAuditRepository, repository.OutboxRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicIdForUpdate(gomock.Any(), publicId).Return(coin, nil)
+				coinRepo.EXPECT().Delete(gomock.Any(), coin).Return(errors.New("mock db error"))
+				return coinRepo, repomocks.NewMockCoinAuditRepository(ctrl), repomocks.NewMockOutboxRepository(ctrl)
+			},
+			publicId: publicId,
+			wantErr:  errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinRepo, auditRepo, outboxRepo := tc.mock(ctrl)
+			svc := NewCoinService(coinRepo, auditRepo, outboxRepo, newTestTransactor(ctrl))
+			err := svc.DeleteByPublicId(context.Background(), tc.publicId)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_coinService_React(t *testing.T) {
 	testCases := []struct {
 		name string
 		mock func(*gomock.Controller) repository.CoinRepository
 
-		id int64
+		reaction domain.ReactionType
 
 		wantErr error
 	}{
 		{
-			name: "incr success",
+			name: "poke success",
 			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().IncrPopularityScore(gomock.Any(), int64(1)).Return(nil)
+				coinRepo.EXPECT().AddReaction(gomock.Any(), publicId, "fp:1", domain.ReactionPoke).Return(nil)
 				return coinRepo
 			},
-			id:      1,
-			wantErr: nil,
+			reaction: domain.ReactionPoke,
+		},
+		{
+			name: "duplicate reaction",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().AddReaction(gomock.Any(), publicId, "fp:1", domain.ReactionRocket).
+					Return(repository.ErrDuplicateReaction)
+				return coinRepo
+			},
+			reaction: domain.ReactionRocket,
+			wantErr:  ErrDuplicateReaction,
+		},
+		{
+			name: "unknown reaction",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				return repomocks.NewMockCoinRepository(ctrl)
+			},
+			reaction: "clap",
+			wantErr:  ErrUnknownReaction,
 		},
 		{
 			name: "db error",
 			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().IncrPopularityScore(gomock.Any(), int64(1)).Return(errors.New("mock db error"))
+				coinRepo.EXPECT().AddReaction(gomock.Any(), publicId, "fp:1", domain.ReactionPoke).
+					Return(errors.New("mock db error"))
 				return coinRepo
 			},
-			id:      1,
+			reaction: domain.ReactionPoke,
+			wantErr:  errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCoinService(tc.mock(ctrl), nil, nil, nil)
+			err := svc.React(context.Background(), publicId, "fp:1", tc.reaction)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_coinService_Unreact(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) repository.CoinRepository
+
+		reaction domain.ReactionType
+
+		wantErr error
+	}{
+		{
+			name: "unpoke success",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().RemoveReaction(gomock.Any(), publicId, "fp:1", domain.ReactionPoke).Return(nil)
+				return coinRepo
+			},
+			reaction: domain.ReactionPoke,
+		},
+		{
+			name: "unknown reaction",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				return repomocks.NewMockCoinRepository(ctrl)
+			},
+			reaction: "clap",
+			wantErr:  ErrUnknownReaction,
+		},
+		{
+			name: "coin not found",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().RemoveReaction(gomock.Any(), publicId, "fp:1", domain.ReactionSkull).
+					Return(repository.ErrNotFound)
+				return coinRepo
+			},
+			reaction: domain.ReactionSkull,
+			wantErr:  ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCoinService(tc.mock(ctrl), nil, nil, nil)
+			err := svc.Unreact(context.Background(), publicId, "fp:1", tc.reaction)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_coinService_List(t *testing.T) {
+	coins := []domain.Coin{{Id: 1, PublicId: publicId, Name: "test", HotScore: 1.5}}
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) repository.CoinRepository
+
+		sort domain.CoinSort
+
+		wantRet []domain.Coin
+		wantErr error
+	}{
+		{
+			name: "hot",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindTopByHotScore(gomock.Any(), 10).Return(coins, nil)
+				return coinRepo
+			},
+			sort:    domain.CoinSortHot,
+			wantRet: coins,
+		},
+		{
+			name: "popular",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindTopByPopularity(gomock.Any(), 10).Return(coins, nil)
+				return coinRepo
+			},
+			sort:    domain.CoinSortPopular,
+			wantRet: coins,
+		},
+		{
+			name: "recent",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindRecent(gomock.Any(), 10).Return(nil, errors.New("mock db error"))
+				return coinRepo
+			},
+			sort:    domain.CoinSortRecent,
 			wantErr: errors.New("mock db error"),
 		},
+		{
+			name: "unknown sort",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				return repomocks.NewMockCoinRepository(ctrl)
+			},
+			sort:    "name",
+			wantErr: ErrUnknownSort,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCoinService(tc.mock(ctrl), nil, nil, nil)
+			ret, err := svc.List(context.Background(), tc.sort, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func Test_coinService_GetByPublicIdAsOf(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) repository.CoinRepository
+
+		wantRet domain.Coin
+		wantErr error
+	}{
+		{
+			name: "get success",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindRevisionAsOf(gomock.Any(), publicId, now).Return(domain.CoinRevision{
+					Revision: 2,
+					Action:   domain.RevisionActionUpdate,
+					Coin:     domain.Coin{Id: 1, PublicId: publicId, Name: "test", Description: "old description"},
+				}, nil)
+				return coinRepo
+			},
+			wantRet: domain.Coin{Id: 1, PublicId: publicId, Name: "test", Description: "old description"},
+		},
+		{
+			name: "deleted then",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindRevisionAsOf(gomock.Any(), publicId, now).Return(domain.CoinRevision{
+					Revision: 3,
+					Action:   domain.RevisionActionDelete,
+					Coin:     domain.Coin{Id: 1, PublicId: publicId, Name: "test"},
+				}, nil)
+				return coinRepo
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "not created yet",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindRevisionAsOf(gomock.Any(), publicId, now).Return(domain.CoinRevision{}, repository.ErrNotFound)
+				return coinRepo
+			},
+			wantErr: ErrNotFound,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -288,40 +689,106 @@ func Test_coinService_DeleteById(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			coinRepo := tc.mock(ctrl)
-			svc := NewCoinService(coinRepo)
-			err := svc.IncrPopularityScore(context.Background(), tc.id)
+			svc := NewCoinService(coinRepo, nil, nil, nil)
+			ret, err := svc.GetByPublicIdAsOf(context.Background(), publicId, now)
 			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
 		})
 	}
 }
 
-func Test_coinService_IncrPopularityScore(t *testing.T) {
+func Test_coinService_ListRevisions(t *testing.T) {
+	revision := func(n int64, action domain.RevisionAction, desc string, score uint32) domain.CoinRevision {
+		return domain.CoinRevision{
+			Revision: n,
+			Action:   action,
+			Coin:     domain.Coin{Id: 1, PublicId: publicId, Name: "test", Description: desc, PopularityScore: score},
+		}
+	}
+	withChanges := func(rev domain.CoinRevision, changes ...domain.FieldChange) domain.CoinRevision {
+		rev.Changes = changes
+		return rev
+	}
 	testCases := []struct {
 		name string
 		mock func(*gomock.Controller) repository.CoinRepository
 
-		id int64
+		after int64
+		limit int
 
+		wantRet []domain.CoinRevision
 		wantErr error
 	}{
 		{
-			name: "delete success",
+			name: "first page",
 			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(nil)
+				coinRepo.EXPECT().FindRevisions(gomock.Any(), publicId, int64(0), 3).Return([]domain.CoinRevision{
+					revision(1, domain.RevisionActionCreate, "", 0),
+					revision(2, domain.RevisionActionUpdate, "much wow", 0),
+					revision(3, domain.RevisionActionPoke, "much wow", 1),
+				}, nil)
 				return coinRepo
 			},
-			id:      1,
-			wantErr: nil,
+			limit: 3,
+			wantRet: []domain.CoinRevision{
+				revision(1, domain.RevisionActionCreate, "", 0),
+				withChanges(revision(2, domain.RevisionActionUpdate, "much wow", 0),
+					domain.FieldChange{Field: "description", From: "", To: "much wow"}),
+				withChanges(revision(3, domain.RevisionActionPoke, "much wow", 1),
+					domain.FieldChange{Field: "popularityScore", From: uint32(0), To: uint32(1)}),
+			},
+		},
+		{
+			name: "next page is diffed against the cursor",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindRevisions(gomock.Any(), publicId, int64(2), 3).Return([]domain.CoinRevision{
+					revision(3, domain.RevisionActionPoke, "much wow", 1),
+					revision(4, domain.RevisionActionPoke, "much wow", 2),
+					revision(5, domain.RevisionActionDelete, "much wow", 2),
+				}, nil)
+				return coinRepo
+			},
+			after: 3,
+			limit: 2,
+			wantRet: []domain.CoinRevision{
+				withChanges(revision(4, domain.RevisionActionPoke, "much wow", 2),
+					domain.FieldChange{Field: "popularityScore", From: uint32(1), To: uint32(2)}),
+				revision(5, domain.RevisionActionDelete, "much wow", 2),
+			},
+		},
+		{
+			name: "past the last revision",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindRevisions(gomock.Any(), publicId, int64(4), 3).Return([]domain.CoinRevision{
+					revision(5, domain.RevisionActionDelete, "much wow", 2),
+				}, nil)
+				return coinRepo
+			},
+			after:   5,
+			limit:   2,
+			wantRet: []domain.CoinRevision{},
+		},
+		{
+			name: "coin not found",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindRevisions(gomock.Any(), publicId, int64(0), 3).Return(nil, nil)
+				return coinRepo
+			},
+			limit:   3,
+			wantErr: ErrNotFound,
 		},
 		{
 			name: "db error",
 			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
 				coinRepo := repomocks.NewMockCoinRepository(ctrl)
-				coinRepo.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(errors.New("mock db error"))
+				coinRepo.EXPECT().FindRevisions(gomock.Any(), publicId, int64(0), 3).Return(nil, errors.New("mock db error"))
 				return coinRepo
 			},
-			id:      1,
+			limit:   3,
 			wantErr: errors.New("mock db error"),
 		},
 	}
@@ -331,9 +798,10 @@ func Test_coinService_IncrPopularityScore(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			coinRepo := tc.mock(ctrl)
-			svc := NewCoinService(coinRepo)
-			err := svc.DeleteById(context.Background(), tc.id)
+			svc := NewCoinService(coinRepo, nil, nil, nil)
+			ret, err := svc.ListRevisions(context.Background(), publicId, tc.after, tc.limit)
 			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
 		})
 	}
 }
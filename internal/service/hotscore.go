@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"time"
+)
+
+type HotScoreRefresherConfig struct {
+	// Interval is the time between two refreshes, the hot scores sorted on are at most that stale.
+	Interval  time.Duration
+	BatchSize int
+}
+
+// HotScoreRefresher decays the hot score of the coins not poked lately, pokes update it as they come.
+type HotScoreRefresher struct {
+	repo repository.CoinRepository
+	cfg  HotScoreRefresherConfig
+	l    logger.Logger
+	now  func() time.Time
+}
+
+func NewHotScoreRefresher(repo repository.CoinRepository, cfg HotScoreRefresherConfig, l logger.Logger) *HotScoreRefresher {
+	return &HotScoreRefresher{
+		repo: repo,
+		cfg:  cfg,
+		l:    l,
+		now:  time.Now,
+	}
+}
+
+// Run refreshes every Interval until ctx is done.
+func (r *HotScoreRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+			r.l.Error("failed to refresh hot scores", logger.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh decays the hot scores of all coins to now. Instances may run it concurrently,
+// a coin is only rewritten by the first of them. It returns the number of coins rewritten.
+func (r *HotScoreRefresher) Refresh(ctx context.Context) (int64, error) {
+	n, err := r.repo.RefreshHotScores(ctx, r.now(), r.cfg.BatchSize)
+	if n > 0 {
+		r.l.Debug("refreshed hot scores", logger.Int64("count", n))
+	}
+	return n, err
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	repomocks "github.com/miles0wu/meme-coin-api/internal/repository/mocks"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestHotScoreRefresher_Refresh(t *testing.T) {
+	now := time.UnixMilli(1717236000000)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) repository.CoinRepository
+
+		wantCount int64
+		wantErr   error
+	}{
+		{
+			name: "refresh success",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().RefreshHotScores(gomock.Any(), now, 500).Return(int64(3), nil)
+				return coinRepo
+			},
+			wantCount: 3,
+		},
+		{
+			name: "refresh failed",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().RefreshHotScores(gomock.Any(), now, 500).
+					Return(int64(1), errors.New("mock db error"))
+				return coinRepo
+			},
+			wantCount: 1,
+			wantErr:   errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			r := NewHotScoreRefresher(tc.mock(ctrl), HotScoreRefresherConfig{
+				Interval:  time.Minute,
+				BatchSize: 500,
+			}, logger.NewNopLogger())
+			r.now = func() time.Time {
+				return now
+			}
+			n, err := r.Refresh(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCount, n)
+		})
+	}
+}
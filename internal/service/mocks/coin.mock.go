@@ -12,6 +12,7 @@ package svcmocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/miles0wu/meme-coin-api/internal/domain"
 	gomock "go.uber.org/mock/gomock"
@@ -56,47 +57,106 @@ func (mr *MockCoinServiceMockRecorder) Create(ctx, coin any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCoinService)(nil).Create), ctx, coin)
 }
 
-// DeleteById mocks base method.
-func (m *MockCoinService) DeleteById(ctx context.Context, id int64) error {
+// DeleteByPublicId mocks base method.
+func (m *MockCoinService) DeleteByPublicId(ctx context.Context, publicId string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteById", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteByPublicId", ctx, publicId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteById indicates an expected call of DeleteById.
-func (mr *MockCoinServiceMockRecorder) DeleteById(ctx, id any) *gomock.Call {
+// DeleteByPublicId indicates an expected call of DeleteByPublicId.
+func (mr *MockCoinServiceMockRecorder) DeleteByPublicId(ctx, publicId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteById", reflect.TypeOf((*MockCoinService)(nil).DeleteById), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByPublicId", reflect.TypeOf((*MockCoinService)(nil).DeleteByPublicId), ctx, publicId)
 }
 
-// GetById mocks base method.
-func (m *MockCoinService) GetById(ctx context.Context, id int64) (domain.Coin, error) {
+// GetByPublicId mocks base method.
+func (m *MockCoinService) GetByPublicId(ctx context.Context, publicId string) (domain.Coin, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret := m.ctrl.Call(m, "GetByPublicId", ctx, publicId)
 	ret0, _ := ret[0].(domain.Coin)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetById indicates an expected call of GetById.
-func (mr *MockCoinServiceMockRecorder) GetById(ctx, id any) *gomock.Call {
+// GetByPublicId indicates an expected call of GetByPublicId.
+func (mr *MockCoinServiceMockRecorder) GetByPublicId(ctx, publicId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockCoinService)(nil).GetById), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPublicId", reflect.TypeOf((*MockCoinService)(nil).GetByPublicId), ctx, publicId)
 }
 
-// IncrPopularityScore mocks base method.
-func (m *MockCoinService) IncrPopularityScore(ctx context.Context, id int64) error {
+// GetByPublicIdAsOf mocks base method.
+func (m *MockCoinService) GetByPublicIdAsOf(ctx context.Context, publicId string, at time.Time) (domain.Coin, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrPopularityScore", ctx, id)
+	ret := m.ctrl.Call(m, "GetByPublicIdAsOf", ctx, publicId, at)
+	ret0, _ := ret[0].(domain.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPublicIdAsOf indicates an expected call of GetByPublicIdAsOf.
+func (mr *MockCoinServiceMockRecorder) GetByPublicIdAsOf(ctx, publicId, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPublicIdAsOf", reflect.TypeOf((*MockCoinService)(nil).GetByPublicIdAsOf), ctx, publicId, at)
+}
+
+// List mocks base method.
+func (m *MockCoinService) List(ctx context.Context, sort domain.CoinSort, limit int) ([]domain.Coin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, sort, limit)
+	ret0, _ := ret[0].([]domain.Coin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCoinServiceMockRecorder) List(ctx, sort, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCoinService)(nil).List), ctx, sort, limit)
+}
+
+// ListRevisions mocks base method.
+func (m *MockCoinService) ListRevisions(ctx context.Context, publicId string, after int64, limit int) ([]domain.CoinRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", ctx, publicId, after, limit)
+	ret0, _ := ret[0].([]domain.CoinRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockCoinServiceMockRecorder) ListRevisions(ctx, publicId, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockCoinService)(nil).ListRevisions), ctx, publicId, after, limit)
+}
+
+// React mocks base method.
+func (m *MockCoinService) React(ctx context.Context, publicId, actor string, reaction domain.ReactionType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "React", ctx, publicId, actor, reaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// React indicates an expected call of React.
+func (mr *MockCoinServiceMockRecorder) React(ctx, publicId, actor, reaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "React", reflect.TypeOf((*MockCoinService)(nil).React), ctx, publicId, actor, reaction)
+}
+
+// Unreact mocks base method.
+func (m *MockCoinService) Unreact(ctx context.Context, publicId, actor string, reaction domain.ReactionType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unreact", ctx, publicId, actor, reaction)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// IncrPopularityScore indicates an expected call of IncrPopularityScore.
-func (mr *MockCoinServiceMockRecorder) IncrPopularityScore(ctx, id any) *gomock.Call {
+// Unreact indicates an expected call of Unreact.
+func (mr *MockCoinServiceMockRecorder) Unreact(ctx, publicId, actor, reaction any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrPopularityScore", reflect.TypeOf((*MockCoinService)(nil).IncrPopularityScore), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unreact", reflect.TypeOf((*MockCoinService)(nil).Unreact), ctx, publicId, actor, reaction)
 }
 
 // Update mocks base method.
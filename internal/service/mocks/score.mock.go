@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./score.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./score.go -package=svcmocks -destination=./mocks/score.mock.go ScoreHistoryService
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/miles0wu/meme-coin-api/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockScoreHistoryService is a mock of ScoreHistoryService interface.
+type MockScoreHistoryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockScoreHistoryServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockScoreHistoryServiceMockRecorder is the mock recorder for MockScoreHistoryService.
+type MockScoreHistoryServiceMockRecorder struct {
+	mock *MockScoreHistoryService
+}
+
+// NewMockScoreHistoryService creates a new mock instance.
+func NewMockScoreHistoryService(ctrl *gomock.Controller) *MockScoreHistoryService {
+	mock := &MockScoreHistoryService{ctrl: ctrl}
+	mock.recorder = &MockScoreHistoryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScoreHistoryService) EXPECT() *MockScoreHistoryServiceMockRecorder {
+	return m.recorder
+}
+
+// History mocks base method.
+func (m *MockScoreHistoryService) History(ctx context.Context, publicId string, interval domain.ScoreInterval, from, to time.Time) ([]domain.ScoreBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, publicId, interval, from, to)
+	ret0, _ := ret[0].([]domain.ScoreBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockScoreHistoryServiceMockRecorder) History(ctx, publicId, interval, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockScoreHistoryService)(nil).History), ctx, publicId, interval, from, to)
+}
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./warmup.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./warmup.go -package=svcmocks -destination=./mocks/warmup.mock.go CacheWarmer
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	service "github.com/miles0wu/meme-coin-api/internal/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCacheWarmer is a mock of CacheWarmer interface.
+type MockCacheWarmer struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheWarmerMockRecorder
+	isgomock struct{}
+}
+
+// MockCacheWarmerMockRecorder is the mock recorder for MockCacheWarmer.
+type MockCacheWarmerMockRecorder struct {
+	mock *MockCacheWarmer
+}
+
+// NewMockCacheWarmer creates a new mock instance.
+func NewMockCacheWarmer(ctrl *gomock.Controller) *MockCacheWarmer {
+	mock := &MockCacheWarmer{ctrl: ctrl}
+	mock.recorder = &MockCacheWarmerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCacheWarmer) EXPECT() *MockCacheWarmerMockRecorder {
+	return m.recorder
+}
+
+// WarmUp mocks base method.
+func (m *MockCacheWarmer) WarmUp(ctx context.Context) (service.WarmUpStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WarmUp", ctx)
+	ret0, _ := ret[0].(service.WarmUpStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WarmUp indicates an expected call of WarmUp.
+func (mr *MockCacheWarmerMockRecorder) WarmUp(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarmUp", reflect.TypeOf((*MockCacheWarmer)(nil).WarmUp), ctx)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/pkg/topk"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+type PokeRateConfig struct {
+	// TopK is the number of coins reported, the others are left out to bound the cardinality of the metric.
+	TopK     int
+	Interval time.Duration
+}
+
+// PokeRateReporter reports the pokes per second of the TopK most poked coins of the last Interval.
+// The pokes are counted in memory, each instance reports the pokes it served.
+type PokeRateReporter struct {
+	pokes *topk.Counter
+	rate  *prometheus.GaugeVec
+	cfg   PokeRateConfig
+}
+
+func NewPokeRateReporter(cfg PokeRateConfig, reg prometheus.Registerer) *PokeRateReporter {
+	rate := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "meme_coin",
+		Subsystem: "coin",
+		Name:      "poke_rate",
+		Help:      "Pokes per second of the most poked coins.",
+	}, []string{"coin"})
+	reg.MustRegister(rate)
+	return &PokeRateReporter{
+		// the extra room keeps the counts of the top coins accurate under a long tail
+		pokes: topk.NewCounter(cfg.TopK * 10),
+		rate:  rate,
+		cfg:   cfg,
+	}
+}
+
+func (r *PokeRateReporter) Poked(publicId string) {
+	r.pokes.Add(publicId, 1)
+}
+
+// Run reports every Interval until ctx is done.
+func (r *PokeRateReporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Report()
+		}
+	}
+}
+
+// Report replaces the reported rates with the pokes counted since the last report.
+func (r *PokeRateReporter) Report() {
+	top := r.pokes.Flush(r.cfg.TopK)
+	r.rate.Reset()
+	for _, e := range top {
+		r.rate.WithLabelValues(e.Key).Set(float64(e.Count) / r.cfg.Interval.Seconds())
+	}
+}
+
+// pokeRateCoinService reports the pokes served by a CoinService to a PokeRateReporter.
+type pokeRateCoinService struct {
+	CoinService
+	pokes *PokeRateReporter
+}
+
+func NewPokeRateCoinService(svc CoinService, pokes *PokeRateReporter) CoinService {
+	return &pokeRateCoinService{
+		CoinService: svc,
+		pokes:       pokes,
+	}
+}
+
+func (svc *pokeRateCoinService) React(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) error {
+	err := svc.CoinService.React(ctx, publicId, actor, reaction)
+	if err == nil && reaction == domain.ReactionPoke {
+		svc.pokes.Poked(publicId)
+	}
+	return err
+}
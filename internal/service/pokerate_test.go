@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	repomocks "github.com/miles0wu/meme-coin-api/internal/repository/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPokeRateCoinService_React(t *testing.T) {
+	const (
+		doge = "01ARYZ6S410000000000000001"
+		pepe = "01ARYZ6S410000000000000002"
+		shib = "01ARYZ6S410000000000000003"
+	)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	coinRepo := repomocks.NewMockCoinRepository(ctrl)
+	coinRepo.EXPECT().AddReaction(gomock.Any(), doge, gomock.Any(), domain.ReactionPoke).Return(nil).Times(3)
+	coinRepo.EXPECT().AddReaction(gomock.Any(), pepe, gomock.Any(), domain.ReactionPoke).Return(nil)
+	coinRepo.EXPECT().AddReaction(gomock.Any(), pepe, gomock.Any(), domain.ReactionRocket).Return(nil)
+	coinRepo.EXPECT().AddReaction(gomock.Any(), shib, gomock.Any(), domain.ReactionPoke).
+		Return(errors.New("mock db error"))
+
+	reg := prometheus.NewRegistry()
+	reporter := NewPokeRateReporter(PokeRateConfig{TopK: 1, Interval: time.Second}, reg)
+	svc := NewPokeRateCoinService(NewCoinService(coinRepo, nil, nil, nil), reporter)
+	ctx := context.Background()
+	for range 3 {
+		assert.NoError(t, svc.React(ctx, doge, "fp:1", domain.ReactionPoke))
+	}
+	assert.NoError(t, svc.React(ctx, pepe, "fp:1", domain.ReactionPoke))
+	// other reactions and failed pokes are not counted
+	assert.NoError(t, svc.React(ctx, pepe, "fp:1", domain.ReactionRocket))
+	assert.Error(t, svc.React(ctx, shib, "fp:1", domain.ReactionPoke))
+
+	reporter.Report()
+	want := `
+# HELP meme_coin_coin_poke_rate Pokes per second of the most poked coins.
+# TYPE meme_coin_coin_poke_rate gauge
+meme_coin_coin_poke_rate{coin="01ARYZ6S410000000000000001"} 3
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(want)))
+
+	// the next report starts over
+	reporter.Report()
+	assert.Equal(t, 0, testutil.CollectAndCount(reg))
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"time"
+)
+
+// MaxScoreBuckets bounds the number of buckets of a score history.
+const MaxScoreBuckets = 1500
+
+var (
+	ErrUnknownInterval = errors.New("unknown score interval")
+	ErrInvalidRange    = errors.New("invalid time range")
+	ErrTooManyBuckets  = errors.New("too many buckets")
+)
+
+//go:generate mockgen -source=./score.go -package=svcmocks -destination=./mocks/score.mock.go ScoreHistoryService
+type ScoreHistoryService interface {
+	// History returns the pokes of the coin per interval from from to to, one bucket per interval
+	// including the empty ones. The first bucket holds from, the last one to.
+	// Buckets older than the retention of their interval are empty.
+	History(ctx context.Context, publicId string, interval domain.ScoreInterval, from, to time.Time) ([]domain.ScoreBucket, error)
+}
+
+type scoreHistoryService struct {
+	coins  repository.CoinRepository
+	scores repository.ScoreHistoryRepository
+}
+
+func NewScoreHistoryService(coins repository.CoinRepository, scores repository.ScoreHistoryRepository) ScoreHistoryService {
+	return &scoreHistoryService{
+		coins:  coins,
+		scores: scores,
+	}
+}
+
+func (svc *scoreHistoryService) History(ctx context.Context, publicId string, interval domain.ScoreInterval,
+	from, to time.Time) ([]domain.ScoreBucket, error) {
+	width := interval.Duration().Milliseconds()
+	if width == 0 {
+		return nil, ErrUnknownInterval
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+	// buckets are aligned on the unix epoch, days start at midnight UTC
+	start := from.UnixMilli() - from.UnixMilli()%width
+	end := to.UnixMilli()
+	n := (end - start + width - 1) / width
+	if n > MaxScoreBuckets {
+		return nil, ErrTooManyBuckets
+	}
+
+	coin, err := svc.coins.FindByPublicId(ctx, publicId)
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := svc.scores.FindBuckets(ctx, coin.Id, interval, time.UnixMilli(start), to)
+	if err != nil {
+		return nil, err
+	}
+	pokes := make(map[int64]int64, len(buckets))
+	for _, b := range buckets {
+		pokes[b.Start.UnixMilli()] = b.Pokes
+	}
+	res := make([]domain.ScoreBucket, 0, n)
+	for ms := start; ms < end; ms += width {
+		res = append(res, domain.ScoreBucket{
+			Start: time.UnixMilli(ms),
+			Pokes: pokes[ms],
+		})
+	}
+	return res, nil
+}
+
+// ScoreRetention is how long the buckets of each interval are kept, zero keeps them forever.
+type ScoreRetention struct {
+	Minute time.Duration
+	Hour   time.Duration
+	Day    time.Duration
+}
+
+type ScoreCompactorConfig struct {
+	// Interval is the time between two compactions.
+	Interval time.Duration
+	// Lookback is how far back the hour and day buckets are recomputed by each compaction,
+	// it must exceed Interval. The first compaction catches up on every minute bucket kept.
+	Lookback  time.Duration
+	Retention ScoreRetention
+}
+
+// ScoreCompactor rolls the minute buckets up into hour and day buckets and applies the retention.
+type ScoreCompactor struct {
+	repo repository.ScoreHistoryRepository
+	cfg  ScoreCompactorConfig
+	l    logger.Logger
+	now  func() time.Time
+}
+
+func NewScoreCompactor(repo repository.ScoreHistoryRepository, cfg ScoreCompactorConfig, l logger.Logger) *ScoreCompactor {
+	return &ScoreCompactor{
+		repo: repo,
+		cfg:  cfg,
+		l:    l,
+		now:  time.Now,
+	}
+}
+
+// Run compacts every Interval until ctx is done.
+func (c *ScoreCompactor) Run(ctx context.Context) {
+	lookback := c.cfg.Lookback
+	if c.cfg.Retention.Minute > time.Hour {
+		lookback = max(lookback, c.cfg.Retention.Minute-time.Hour)
+	}
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Compact(ctx, lookback); err != nil && ctx.Err() == nil {
+			c.l.Error("failed to compact score history", logger.Error(err))
+		}
+		lookback = c.cfg.Lookback
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Compact recomputes the hour and day buckets of the last lookback, then drops the expired buckets.
+func (c *ScoreCompactor) Compact(ctx context.Context, lookback time.Duration) error {
+	now := c.now()
+	since := now.Add(-lookback)
+	// the hours first, the days are summed from them
+	if err := c.repo.Compact(ctx, domain.ScoreIntervalHour, since); err != nil {
+		return err
+	}
+	if err := c.repo.Compact(ctx, domain.ScoreIntervalDay, since); err != nil {
+		return err
+	}
+	retentions := []struct {
+		interval  domain.ScoreInterval
+		retention time.Duration
+	}{
+		{domain.ScoreIntervalMinute, c.cfg.Retention.Minute},
+		{domain.ScoreIntervalHour, c.cfg.Retention.Hour},
+		{domain.ScoreIntervalDay, c.cfg.Retention.Day},
+	}
+	for _, r := range retentions {
+		if r.retention <= 0 {
+			continue
+		}
+		n, err := c.repo.DeleteBefore(ctx, r.interval, now.Add(-r.retention))
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			c.l.Debug("dropped expired score buckets",
+				logger.String("interval", string(r.interval)),
+				logger.Int64("count", n))
+		}
+	}
+	return nil
+}
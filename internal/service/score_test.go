@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	repomocks "github.com/miles0wu/meme-coin-api/internal/repository/mocks"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func Test_scoreHistoryService_History(t *testing.T) {
+	// 2024-06-01T10:00:00Z
+	hour := time.UnixMilli(1717236000000)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) (repository.CoinRepository, repository.ScoreHistoryRepository)
+
+		interval domain.ScoreInterval
+		from     time.Time
+		to       time.Time
+
+		wantRet []domain.ScoreBucket
+		wantErr error
+	}{
+		{
+			name: "fills the empty buckets",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.ScoreHistoryRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				scoreRepo := repomocks.NewMockScoreHistoryRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{Id: 1, PublicId: publicId}, nil)
+				scoreRepo.EXPECT().FindBuckets(gomock.Any(), int64(1), domain.ScoreIntervalHour, hour, hour.Add(3*time.Hour+time.Minute)).
+					Return([]domain.ScoreBucket{
+						{Start: hour.Add(time.Hour), Pokes: 5},
+						{Start: hour.Add(3 * time.Hour), Pokes: 1},
+					}, nil)
+				return coinRepo, scoreRepo
+			},
+			interval: domain.ScoreIntervalHour,
+			// from is rounded down to the hour
+			from: hour.Add(30 * time.Minute),
+			to:   hour.Add(3*time.Hour + time.Minute),
+			wantRet: []domain.ScoreBucket{
+				{Start: hour, Pokes: 0},
+				{Start: hour.Add(time.Hour), Pokes: 5},
+				{Start: hour.Add(2 * time.Hour), Pokes: 0},
+				{Start: hour.Add(3 * time.Hour), Pokes: 1},
+			},
+		},
+		{
+			name: "coin not found",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.ScoreHistoryRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{}, repository.ErrNotFound)
+				return coinRepo, repomocks.NewMockScoreHistoryRepository(ctrl)
+			},
+			interval: domain.ScoreIntervalDay,
+			from:     hour,
+			to:       hour.Add(time.Hour),
+			wantErr:  ErrNotFound,
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.ScoreHistoryRepository) {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				scoreRepo := repomocks.NewMockScoreHistoryRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{Id: 1, PublicId: publicId}, nil)
+				scoreRepo.EXPECT().FindBuckets(gomock.Any(), int64(1), domain.ScoreIntervalMinute, hour, hour.Add(time.Hour)).
+					Return(nil, errors.New("mock db error"))
+				return coinRepo, scoreRepo
+			},
+			interval: domain.ScoreIntervalMinute,
+			from:     hour,
+			to:       hour.Add(time.Hour),
+			wantErr:  errors.New("mock db error"),
+		},
+		{
+			name: "unknown interval",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.ScoreHistoryRepository) {
+				return repomocks.NewMockCoinRepository(ctrl), repomocks.NewMockScoreHistoryRepository(ctrl)
+			},
+			interval: "week",
+			from:     hour,
+			to:       hour.Add(time.Hour),
+			wantErr:  ErrUnknownInterval,
+		},
+		{
+			name: "empty range",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.ScoreHistoryRepository) {
+				return repomocks.NewMockCoinRepository(ctrl), repomocks.NewMockScoreHistoryRepository(ctrl)
+			},
+			interval: domain.ScoreIntervalHour,
+			from:     hour,
+			to:       hour,
+			wantErr:  ErrInvalidRange,
+		},
+		{
+			name: "too many buckets",
+			mock: func(ctrl *gomock.Controller) (repository.CoinRepository, repository.ScoreHistoryRepository) {
+				return repomocks.NewMockCoinRepository(ctrl), repomocks.NewMockScoreHistoryRepository(ctrl)
+			},
+			interval: domain.ScoreIntervalMinute,
+			from:     hour,
+			to:       hour.Add(MaxScoreBuckets*time.Minute + time.Second),
+			wantErr:  ErrTooManyBuckets,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinRepo, scoreRepo := tc.mock(ctrl)
+			svc := NewScoreHistoryService(coinRepo, scoreRepo)
+			ret, err := svc.History(context.Background(), publicId, tc.interval, tc.from, tc.to)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestScoreCompactor_Compact(t *testing.T) {
+	now := time.UnixMilli(1717236000000)
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) repository.ScoreHistoryRepository
+
+		retention ScoreRetention
+
+		wantErr error
+	}{
+		{
+			name: "compact and drop expired buckets",
+			mock: func(ctrl *gomock.Controller) repository.ScoreHistoryRepository {
+				scoreRepo := repomocks.NewMockScoreHistoryRepository(ctrl)
+				gomock.InOrder(
+					scoreRepo.EXPECT().Compact(gomock.Any(), domain.ScoreIntervalHour, now.Add(-3*time.Hour)).Return(nil),
+					scoreRepo.EXPECT().Compact(gomock.Any(), domain.ScoreIntervalDay, now.Add(-3*time.Hour)).Return(nil),
+					scoreRepo.EXPECT().DeleteBefore(gomock.Any(), domain.ScoreIntervalMinute, now.Add(-48*time.Hour)).Return(int64(10), nil),
+					scoreRepo.EXPECT().DeleteBefore(gomock.Any(), domain.ScoreIntervalHour, now.Add(-90*24*time.Hour)).Return(int64(0), nil),
+				)
+				return scoreRepo
+			},
+			// the day buckets are kept forever
+			retention: ScoreRetention{Minute: 48 * time.Hour, Hour: 90 * 24 * time.Hour},
+		},
+		{
+			name: "compaction failed",
+			mock: func(ctrl *gomock.Controller) repository.ScoreHistoryRepository {
+				scoreRepo := repomocks.NewMockScoreHistoryRepository(ctrl)
+				scoreRepo.EXPECT().Compact(gomock.Any(), domain.ScoreIntervalHour, now.Add(-3*time.Hour)).
+					Return(errors.New("mock db error"))
+				return scoreRepo
+			},
+			retention: ScoreRetention{Minute: 48 * time.Hour},
+			wantErr:   errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewScoreCompactor(tc.mock(ctrl), ScoreCompactorConfig{
+				Interval:  time.Minute,
+				Lookback:  3 * time.Hour,
+				Retention: tc.retention,
+			}, logger.NewNopLogger())
+			c.now = func() time.Time {
+				return now
+			}
+			err := c.Compact(context.Background(), 3*time.Hour)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
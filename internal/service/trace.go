@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const tracerName = "github.com/miles0wu/meme-coin-api/internal/service"
+
+// tracedCoinService wraps every call of a CoinService in a span, the spans of the repository,
+// the cache and the database nest in it.
+type tracedCoinService struct {
+	svc    CoinService
+	tracer trace.Tracer
+}
+
+func NewTracedCoinService(svc CoinService) CoinService {
+	return &tracedCoinService{
+		svc:    svc,
+		tracer: otel.Tracer(tracerName),
+	}
+}
+
+func (s *tracedCoinService) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "CoinService."+name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, recording err unless it is a not found, which is an answer rather than a failure.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (s *tracedCoinService) Create(ctx context.Context, coin domain.Coin) (res domain.Coin, err error) {
+	ctx, span := s.start(ctx, "Create")
+	defer func() { endSpan(span, err) }()
+	return s.svc.Create(ctx, coin)
+}
+
+func (s *tracedCoinService) Update(ctx context.Context, coin domain.Coin) (err error) {
+	ctx, span := s.start(ctx, "Update", attribute.String("coin.public_id", coin.PublicId))
+	defer func() { endSpan(span, err) }()
+	return s.svc.Update(ctx, coin)
+}
+
+func (s *tracedCoinService) GetByPublicId(ctx context.Context, publicId string) (res domain.Coin, err error) {
+	ctx, span := s.start(ctx, "GetByPublicId", attribute.String("coin.public_id", publicId))
+	defer func() { endSpan(span, err) }()
+	return s.svc.GetByPublicId(ctx, publicId)
+}
+
+func (s *tracedCoinService) DeleteByPublicId(ctx context.Context, publicId string) (err error) {
+	ctx, span := s.start(ctx, "DeleteByPublicId", attribute.String("coin.public_id", publicId))
+	defer func() { endSpan(span, err) }()
+	return s.svc.DeleteByPublicId(ctx, publicId)
+}
+
+func (s *tracedCoinService) React(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) (err error) {
+	ctx, span := s.start(ctx, "React", attribute.String("coin.public_id", publicId),
+		attribute.String("coin.reaction", string(reaction)))
+	defer func() { endSpan(span, err) }()
+	return s.svc.React(ctx, publicId, actor, reaction)
+}
+
+func (s *tracedCoinService) Unreact(ctx context.Context, publicId string, actor string, reaction domain.ReactionType) (err error) {
+	ctx, span := s.start(ctx, "Unreact", attribute.String("coin.public_id", publicId),
+		attribute.String("coin.reaction", string(reaction)))
+	defer func() { endSpan(span, err) }()
+	return s.svc.Unreact(ctx, publicId, actor, reaction)
+}
+
+func (s *tracedCoinService) List(ctx context.Context, sort domain.CoinSort, limit int) (res []domain.Coin, err error) {
+	ctx, span := s.start(ctx, "List", attribute.String("coin.sort", string(sort)), attribute.Int("coin.limit", limit))
+	defer func() { endSpan(span, err) }()
+	return s.svc.List(ctx, sort, limit)
+}
+
+func (s *tracedCoinService) GetByPublicIdAsOf(ctx context.Context, publicId string, at time.Time) (res domain.Coin, err error) {
+	ctx, span := s.start(ctx, "GetByPublicIdAsOf", attribute.String("coin.public_id", publicId))
+	defer func() { endSpan(span, err) }()
+	return s.svc.GetByPublicIdAsOf(ctx, publicId, at)
+}
+
+func (s *tracedCoinService) ListRevisions(ctx context.Context, publicId string, after int64, limit int) (res []domain.CoinRevision, err error) {
+	ctx, span := s.start(ctx, "ListRevisions", attribute.String("coin.public_id", publicId))
+	defer func() { endSpan(span, err) }()
+	return s.svc.ListRevisions(ctx, publicId, after, limit)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	repomocks "github.com/miles0wu/meme-coin-api/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestTracedCoinService_GetByPublicId(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.CoinRepository
+
+		wantErr    error
+		wantStatus codes.Code
+	}{
+		{
+			name: "get success",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{PublicId: publicId}, nil)
+				return coinRepo
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name: "not found is no error",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{}, ErrNotFound)
+				return coinRepo
+			},
+			wantErr:    ErrNotFound,
+			wantStatus: codes.Unset,
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindByPublicId(gomock.Any(), publicId).Return(domain.Coin{}, errors.New("mock db error"))
+				return coinRepo
+			},
+			wantErr:    errors.New("mock db error"),
+			wantStatus: codes.Error,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			svc := NewTracedCoinService(NewCoinService(tc.mock(ctrl), nil, nil, nil)).(*tracedCoinService)
+			svc.tracer = tp.Tracer("test")
+
+			_, err := svc.GetByPublicId(context.Background(), publicId)
+			assert.Equal(t, tc.wantErr, err)
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "CoinService.GetByPublicId", spans[0].Name())
+			assert.Contains(t, spans[0].Attributes(), attribute.String("coin.public_id", publicId))
+			assert.Equal(t, tc.wantStatus, spans[0].Status().Code)
+		})
+	}
+}
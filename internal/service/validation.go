@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits mirror the column sizes of the coins table, checking them here
+// turns would-be truncation errors from the database into field errors.
+const (
+	MaxNameLength        = 255
+	MaxDescriptionLength = 128
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports every invalid field of a request at once.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "invalid input: " + strings.Join(msgs, "; ")
+}
+
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) text(field, value string, required bool, maxLen int) {
+	switch {
+	case !utf8.ValidString(value):
+		v.add(field, "must be valid UTF-8")
+	case required && strings.TrimSpace(value) == "":
+		v.add(field, "is required")
+	case utf8.RuneCountInString(value) > maxLen:
+		v.add(field, fmt.Sprintf("must be at most %d characters", maxLen))
+	}
+}
+
+func (v *validator) add(field, msg string) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: msg})
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+// ValidateCreate returns the error Create would return for the fields of c, without creating it.
+func ValidateCreate(c domain.Coin) error {
+	return validateCreate(c)
+}
+
+func validateCreate(c domain.Coin) error {
+	var v validator
+	v.text("name", c.Name, true, MaxNameLength)
+	v.text("description", c.Description, false, MaxDescriptionLength)
+	return v.err()
+}
+
+func validateUpdate(c domain.Coin) error {
+	var v validator
+	v.text("description", c.Description, false, MaxDescriptionLength)
+	return v.err()
+}
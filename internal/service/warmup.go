@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"sync/atomic"
+	"time"
+)
+
+var ErrWarmUpInProgress = errors.New("cache warm-up already in progress")
+
+//go:generate mockgen -source=./warmup.go -package=svcmocks -destination=./mocks/warmup.mock.go CacheWarmer
+type CacheWarmer interface {
+	// WarmUp preloads the most popular and the most recent coins into the cache.
+	// It stops when the budget is spent and reports what was loaded so far.
+	WarmUp(ctx context.Context) (WarmUpStats, error)
+}
+
+type WarmUpConfig struct {
+	// TopN is the number of coins preloaded by popularity score.
+	TopN int
+	// RecentN is the number of most recently created coins preloaded.
+	RecentN int
+	// BatchSize is the number of coins written per pipeline.
+	BatchSize int
+	// Budget bounds the duration of a warm-up, zero means unbounded.
+	Budget time.Duration
+}
+
+type WarmUpStats struct {
+	Loaded   int
+	Total    int
+	Duration time.Duration
+}
+
+type cacheWarmer struct {
+	repo    repository.CoinRepository
+	cfg     WarmUpConfig
+	l       logger.Logger
+	running atomic.Bool
+}
+
+func NewCacheWarmer(repo repository.CoinRepository, cfg WarmUpConfig, l logger.Logger) CacheWarmer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &cacheWarmer{
+		repo: repo,
+		cfg:  cfg,
+		l:    l,
+	}
+}
+
+func (w *cacheWarmer) WarmUp(ctx context.Context) (WarmUpStats, error) {
+	if !w.running.CompareAndSwap(false, true) {
+		return WarmUpStats{}, ErrWarmUpInProgress
+	}
+	defer w.running.Store(false)
+
+	if w.cfg.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.cfg.Budget)
+		defer cancel()
+	}
+
+	start := time.Now()
+	stats := WarmUpStats{}
+	coins, err := w.candidates(ctx)
+	if err != nil {
+		return stats, err
+	}
+	stats.Total = len(coins)
+	w.l.Info("cache warm-up started",
+		logger.Int("total", stats.Total))
+
+	for i := 0; i < len(coins); i += w.cfg.BatchSize {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		batch := coins[i:min(i+w.cfg.BatchSize, len(coins))]
+		if err = w.repo.Preload(ctx, batch); err != nil {
+			break
+		}
+		stats.Loaded += len(batch)
+		w.l.Info("cache warm-up progress",
+			logger.Int("loaded", stats.Loaded),
+			logger.Int("total", stats.Total))
+	}
+	stats.Duration = time.Since(start)
+
+	if err != nil {
+		w.l.Warn("cache warm-up stopped early",
+			logger.Int("loaded", stats.Loaded),
+			logger.Int("total", stats.Total),
+			logger.Error(err))
+		return stats, err
+	}
+	w.l.Info("cache warm-up finished",
+		logger.Int("loaded", stats.Loaded),
+		logger.Int64("duration_ms", stats.Duration.Milliseconds()))
+	return stats, nil
+}
+
+// candidates returns the top coins followed by the recent ones, without duplicates.
+func (w *cacheWarmer) candidates(ctx context.Context) ([]domain.Coin, error) {
+	var res []domain.Coin
+	if w.cfg.TopN > 0 {
+		top, err := w.repo.FindTopByPopularity(ctx, w.cfg.TopN)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, top...)
+	}
+	if w.cfg.RecentN > 0 {
+		recent, err := w.repo.FindRecent(ctx, w.cfg.RecentN)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[int64]struct{}, len(res))
+		for _, c := range res {
+			seen[c.Id] = struct{}{}
+		}
+		for _, c := range recent {
+			if _, ok := seen[c.Id]; !ok {
+				res = append(res, c)
+			}
+		}
+	}
+	return res, nil
+}
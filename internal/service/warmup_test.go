@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	repomocks "github.com/miles0wu/meme-coin-api/internal/repository/mocks"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func Test_cacheWarmer_WarmUp(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(*gomock.Controller) repository.CoinRepository
+
+		cfg WarmUpConfig
+
+		wantLoaded int
+		wantTotal  int
+		wantErr    error
+	}{
+		{
+			name: "warm up top and recent without duplicates",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindTopByPopularity(gomock.Any(), 2).
+					Return([]domain.Coin{{Id: 1}, {Id: 2}}, nil)
+				coinRepo.EXPECT().FindRecent(gomock.Any(), 2).
+					Return([]domain.Coin{{Id: 2}, {Id: 3}}, nil)
+				coinRepo.EXPECT().Preload(gomock.Any(), []domain.Coin{{Id: 1}, {Id: 2}}).Return(nil)
+				coinRepo.EXPECT().Preload(gomock.Any(), []domain.Coin{{Id: 3}}).Return(nil)
+				return coinRepo
+			},
+			cfg:        WarmUpConfig{TopN: 2, RecentN: 2, BatchSize: 2},
+			wantLoaded: 3,
+			wantTotal:  3,
+		},
+		{
+			name: "disabled sources",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				return repomocks.NewMockCoinRepository(ctrl)
+			},
+			cfg: WarmUpConfig{},
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindTopByPopularity(gomock.Any(), 2).
+					Return(nil, errors.New("mock db error"))
+				return coinRepo
+			},
+			cfg:     WarmUpConfig{TopN: 2, RecentN: 2},
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "cache error stops early",
+			mock: func(ctrl *gomock.Controller) repository.CoinRepository {
+				coinRepo := repomocks.NewMockCoinRepository(ctrl)
+				coinRepo.EXPECT().FindTopByPopularity(gomock.Any(), 3).
+					Return([]domain.Coin{{Id: 1}, {Id: 2}, {Id: 3}}, nil)
+				coinRepo.EXPECT().Preload(gomock.Any(), []domain.Coin{{Id: 1}}).Return(nil)
+				coinRepo.EXPECT().Preload(gomock.Any(), []domain.Coin{{Id: 2}}).Return(errors.New("redis conn error"))
+				return coinRepo
+			},
+			cfg:        WarmUpConfig{TopN: 3, BatchSize: 1},
+			wantLoaded: 1,
+			wantTotal:  3,
+			wantErr:    errors.New("redis conn error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			warmer := NewCacheWarmer(tc.mock(ctrl), tc.cfg, logger.NewNopLogger())
+			stats, err := warmer.WarmUp(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLoaded, stats.Loaded)
+			assert.Equal(t, tc.wantTotal, stats.Total)
+		})
+	}
+}
+
+func Test_cacheWarmer_WarmUpInProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	coinRepo := repomocks.NewMockCoinRepository(ctrl)
+	warmer := NewCacheWarmer(coinRepo, WarmUpConfig{TopN: 1}, logger.NewNopLogger())
+	coinRepo.EXPECT().FindTopByPopularity(gomock.Any(), 1).
+		DoAndReturn(func(ctx context.Context, limit int) ([]domain.Coin, error) {
+			_, err := warmer.WarmUp(ctx)
+			assert.Equal(t, ErrWarmUpInProgress, err)
+			return nil, nil
+		})
+	_, err := warmer.WarmUp(context.Background())
+	assert.NoError(t, err)
+}
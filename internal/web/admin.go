@@ -0,0 +1,167 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"net/http"
+)
+
+// LogLevel is the level of the running loggers, *zap.AtomicLevel implements it.
+type LogLevel interface {
+	String() string
+	UnmarshalText(text []byte) error
+}
+
+type AdminHandler struct {
+	warmer service.CacheWarmer
+	level  LogLevel
+	// token is the bearer token required by admin routes,
+	// the routes are not registered when it is empty.
+	token string
+	l     logger.Logger
+}
+
+func NewAdminHandler(warmer service.CacheWarmer, level LogLevel, token string, l logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		warmer: warmer,
+		level:  level,
+		token:  token,
+		l:      l,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(server *gin.Engine) {
+	if h.token == "" {
+		return
+	}
+	ag := server.Group("/api/v1/admin", h.authenticate)
+	// POST /admin/cache/warm-up
+	ag.POST("/cache/warm-up", h.WarmUp)
+	// GET /admin/debug/vars
+	ag.GET("/debug/vars", h.Vars)
+	// GET /admin/log-level
+	ag.GET("/log-level", h.LogLevel)
+	// PUT /admin/log-level
+	ag.PUT("/log-level", h.SetLogLevel)
+}
+
+func (h *AdminHandler) authenticate(ctx *gin.Context) {
+	want := "Bearer " + h.token
+	got := ctx.GetHeader("Authorization")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, Result{
+			Code: 401,
+			Msg:  "unauthorized",
+		})
+		return
+	}
+	ctx.Next()
+}
+
+// WarmUp is used to preload popular and recent coins into the cache
+// @Summary Warm up coin cache
+// @Description Preload the most popular and the most recently created coins into the cache
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Result{data=WarmUpVo}
+// @Failure 401 {object} Result
+// @Failure 409 {object} Result
+// @Failure 500 {object} Result{data=WarmUpVo}
+// @Router /api/v1/admin/cache/warm-up [post]
+func (h *AdminHandler) WarmUp(ctx *gin.Context) {
+	stats, err := h.warmer.WarmUp(ctx)
+	vo := WarmUpVo{
+		Loaded:     stats.Loaded,
+		Total:      stats.Total,
+		DurationMs: stats.Duration.Milliseconds(),
+	}
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 200,
+			Data: vo,
+		})
+	case errors.Is(err, service.ErrWarmUpInProgress):
+		ctx.JSON(http.StatusConflict, Result{
+			Code: 409,
+			Msg:  "cache warm-up already in progress",
+		})
+	case errors.Is(err, context.DeadlineExceeded):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 200,
+			Msg:  "warm-up budget exhausted",
+			Data: vo,
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "internal server error",
+			Data: vo,
+		})
+		logger.FromContext(ctx, h.l).Error("failed to warm up coin cache",
+			logger.Error(err))
+	}
+}
+
+// Vars is used to expose the runtime and database pool metrics
+// @Summary Runtime metrics
+// @Description Expvar metrics, including the database connection pool statistics under "db"
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]any
+// @Failure 401 {object} Result
+// @Router /api/v1/admin/debug/vars [get]
+func (h *AdminHandler) Vars(ctx *gin.Context) {
+	expvar.Handler().ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// LogLevel is used to read the level of the running loggers
+// @Summary Log level
+// @Description The level of the running loggers
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Result{data=LogLevelVo}
+// @Failure 401 {object} Result
+// @Router /api/v1/admin/log-level [get]
+func (h *AdminHandler) LogLevel(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Data: LogLevelVo{Level: h.level.String()},
+	})
+}
+
+// SetLogLevel is used to change the level of the running loggers
+// @Summary Change log level
+// @Description Change the level of the running loggers until the next restart
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param level body LogLevelVo true "debug, info, warn, error, dpanic, panic or fatal"
+// @Success 200 {object} Result{data=LogLevelVo}
+// @Failure 400 {object} Result
+// @Failure 401 {object} Result
+// @Router /api/v1/admin/log-level [put]
+func (h *AdminHandler) SetLogLevel(ctx *gin.Context) {
+	var req LogLevelVo
+	if err := ctx.ShouldBindJSON(&req); err != nil || h.level.UnmarshalText([]byte(req.Level)) != nil {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "invalid level param",
+		})
+		return
+	}
+	logger.FromContext(ctx, h.l).Warn("log level changed", logger.String("level", h.level.String()))
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Data: LogLevelVo{Level: h.level.String()},
+	})
+}
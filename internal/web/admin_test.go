@@ -0,0 +1,243 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	svcmocks "github.com/miles0wu/meme-coin-api/internal/service/mocks"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminHandler_WarmUp(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.CacheWarmer
+
+		token string
+
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "warm up success",
+			mock: func(ctrl *gomock.Controller) service.CacheWarmer {
+				warmer := svcmocks.NewMockCacheWarmer(ctrl)
+				warmer.EXPECT().WarmUp(gomock.Any()).Return(service.WarmUpStats{
+					Loaded:   3,
+					Total:    3,
+					Duration: time.Second,
+				}, nil)
+				return warmer
+			},
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Data: map[string]any{"loaded": float64(3), "total": float64(3), "durationMs": float64(1000)},
+			},
+		},
+		{
+			name: "budget exhausted",
+			mock: func(ctrl *gomock.Controller) service.CacheWarmer {
+				warmer := svcmocks.NewMockCacheWarmer(ctrl)
+				warmer.EXPECT().WarmUp(gomock.Any()).Return(service.WarmUpStats{
+					Loaded: 1,
+					Total:  3,
+				}, context.DeadlineExceeded)
+				return warmer
+			},
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Msg:  "warm-up budget exhausted",
+				Data: map[string]any{"loaded": float64(1), "total": float64(3), "durationMs": float64(0)},
+			},
+		},
+		{
+			name: "in progress",
+			mock: func(ctrl *gomock.Controller) service.CacheWarmer {
+				warmer := svcmocks.NewMockCacheWarmer(ctrl)
+				warmer.EXPECT().WarmUp(gomock.Any()).Return(service.WarmUpStats{}, service.ErrWarmUpInProgress)
+				return warmer
+			},
+			token:    "secret",
+			wantCode: http.StatusConflict,
+			wantBody: Result{
+				Code: 409,
+				Msg:  "cache warm-up already in progress",
+			},
+		},
+		{
+			name: "warm up failed",
+			mock: func(ctrl *gomock.Controller) service.CacheWarmer {
+				warmer := svcmocks.NewMockCacheWarmer(ctrl)
+				warmer.EXPECT().WarmUp(gomock.Any()).Return(service.WarmUpStats{Total: 3}, errors.New("redis conn error"))
+				return warmer
+			},
+			token:    "secret",
+			wantCode: http.StatusInternalServerError,
+			wantBody: Result{
+				Code: 500,
+				Msg:  "internal server error",
+				Data: map[string]any{"loaded": float64(0), "total": float64(3), "durationMs": float64(0)},
+			},
+		},
+		{
+			name: "wrong token",
+			mock: func(ctrl *gomock.Controller) service.CacheWarmer {
+				return svcmocks.NewMockCacheWarmer(ctrl)
+			},
+			token:    "other",
+			wantCode: http.StatusUnauthorized,
+			wantBody: Result{
+				Code: 401,
+				Msg:  "unauthorized",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			hdl := NewAdminHandler(tc.mock(ctrl), newLogLevel(), "secret", logger.NewNopLogger())
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/admin/cache/warm-up", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			var res Result
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestAdminHandler_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hdl := NewAdminHandler(svcmocks.NewMockCacheWarmer(ctrl), newLogLevel(), "", logger.NewNopLogger())
+	server := gin.Default()
+	hdl.RegisterRoutes(server)
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/admin/cache/warm-up", nil)
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestAdminHandler_Vars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hdl := NewAdminHandler(svcmocks.NewMockCacheWarmer(ctrl), newLogLevel(), "secret", logger.NewNopLogger())
+	server := gin.Default()
+	hdl.RegisterRoutes(server)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/admin/debug/vars", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var vars map[string]any
+	err = json.NewDecoder(recorder.Body).Decode(&vars)
+	assert.NoError(t, err)
+	assert.Contains(t, vars, "memstats")
+}
+
+func TestAdminHandler_LogLevel(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		body   string
+
+		wantCode  int
+		wantBody  Result
+		wantLevel zapcore.Level
+	}{
+		{
+			name:      "get level",
+			method:    http.MethodGet,
+			wantCode:  http.StatusOK,
+			wantBody:  Result{Code: 200, Data: LogLevelVo{Level: "info"}},
+			wantLevel: zapcore.InfoLevel,
+		},
+		{
+			name:      "set level",
+			method:    http.MethodPut,
+			body:      `{"level":"debug"}`,
+			wantCode:  http.StatusOK,
+			wantBody:  Result{Code: 200, Data: LogLevelVo{Level: "debug"}},
+			wantLevel: zapcore.DebugLevel,
+		},
+		{
+			name:      "unknown level",
+			method:    http.MethodPut,
+			body:      `{"level":"verbose"}`,
+			wantCode:  http.StatusBadRequest,
+			wantBody:  Result{Code: 400, Msg: "invalid level param"},
+			wantLevel: zapcore.InfoLevel,
+		},
+		{
+			name:      "missing level",
+			method:    http.MethodPut,
+			body:      `{}`,
+			wantCode:  http.StatusBadRequest,
+			wantBody:  Result{Code: 400, Msg: "invalid level param"},
+			wantLevel: zapcore.InfoLevel,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+			hdl := NewAdminHandler(svcmocks.NewMockCacheWarmer(ctrl), &level, "secret", logger.NewNopLogger())
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/admin/log-level", strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer secret")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			bs, err := json.Marshal(tc.wantBody)
+			assert.NoError(t, err)
+			assert.Equal(t, string(bs), recorder.Body.String())
+			assert.Equal(t, tc.wantLevel, level.Level())
+		})
+	}
+}
+
+func newLogLevel() LogLevel {
+	level := zap.NewAtomicLevel()
+	return &level
+}
@@ -0,0 +1,11 @@
+package web
+
+type WarmUpVo struct {
+	Loaded     int   `json:"loaded"`
+	Total      int   `json:"total"`
+	DurationMs int64 `json:"durationMs"`
+}
+
+type LogLevelVo struct {
+	Level string `json:"level" binding:"required"`
+}
@@ -1,33 +1,59 @@
 package web
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/miles0wu/meme-coin-api/internal/domain"
 	"github.com/miles0wu/meme-coin-api/internal/service"
 	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/miles0wu/meme-coin-api/pkg/ulid"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type CoinHandler struct {
 	svc service.CoinService
 	l   logger.Logger
+	// clientIdSecret signs the trusted client ids, every client is fingerprinted without it
+	clientIdSecret []byte
 }
 
-func NewCoinHandler(svc service.CoinService, l logger.Logger) *CoinHandler {
-	return &CoinHandler{
+type CoinHandlerOption func(*CoinHandler)
+
+// WithClientIdSecret trusts the client ids signed with secret, see SignClientId.
+func WithClientIdSecret(secret string) CoinHandlerOption {
+	return func(h *CoinHandler) {
+		if secret != "" {
+			h.clientIdSecret = []byte(secret)
+		}
+	}
+}
+
+func NewCoinHandler(svc service.CoinService, l logger.Logger, opts ...CoinHandlerOption) *CoinHandler {
+	h := &CoinHandler{
 		svc: svc,
 		l:   l,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *CoinHandler) RegisterRoutes(server *gin.Engine) {
 	cg := server.Group("/api/v1/meme-coins")
 	// POST /meme-coins
 	cg.POST("", h.Create)
+	// GET /meme-coins
+	cg.GET("", h.List)
 	// GET /meme-coins/{id}
 	cg.GET("/:id", h.Detail)
 	// PUT /meme-coins/{id}
@@ -36,6 +62,14 @@ func (h *CoinHandler) RegisterRoutes(server *gin.Engine) {
 	cg.DELETE("/:id", h.Delete)
 	// POST /meme-coins/{id}/poke
 	cg.POST("/:id/poke", h.Poke)
+	// DELETE /meme-coins/{id}/poke
+	cg.DELETE("/:id/poke", h.Unpoke)
+	// POST /meme-coins/{id}/reactions/{reaction}
+	cg.POST("/:id/reactions/:reaction", h.React)
+	// DELETE /meme-coins/{id}/reactions/{reaction}
+	cg.DELETE("/:id/reactions/:reaction", h.Unreact)
+	// GET /meme-coins/{id}/revisions
+	cg.GET("/:id/revisions", h.Revisions)
 }
 
 // Create is used to add a new meme coin
@@ -46,7 +80,7 @@ func (h *CoinHandler) RegisterRoutes(server *gin.Engine) {
 // @Produce json
 // @Param payload body CreateCoinReq true "coin"
 // @Success 201 {object} Result{data=CoinVo}
-// @Failure 400 {object} Result
+// @Failure 400 {object} Result{data=[]service.FieldError}
 // @Failure 500 {object} Result
 // @Router /api/v1/meme-coins [post]
 func (h *CoinHandler) Create(ctx *gin.Context) {
@@ -56,7 +90,7 @@ func (h *CoinHandler) Create(ctx *gin.Context) {
 			Msg:  "invalid input",
 			Code: 400,
 		})
-		h.l.Error("failed to create coin, invalid input",
+		logger.FromContext(ctx, h.l).Error("failed to create coin, invalid input",
 			logger.Error(err))
 		return
 	}
@@ -66,12 +100,23 @@ func (h *CoinHandler) Create(ctx *gin.Context) {
 		Description: req.Description,
 	})
 	if err != nil {
+		var ve *service.ValidationError
+		if errors.As(err, &ve) {
+			ctx.JSON(http.StatusBadRequest, Result{
+				Msg:  "invalid input",
+				Code: 400,
+				Data: ve.Fields,
+			})
+			logger.FromContext(ctx, h.l).Error("failed to create coin, invalid input",
+				logger.Error(err))
+			return
+		}
 		if errors.Is(err, service.ErrDuplicateName) {
 			ctx.JSON(http.StatusBadRequest, Result{
 				Msg:  "coin name already exists",
 				Code: 400,
 			})
-			h.l.Error("failed to create coin, duplicate coin name",
+			logger.FromContext(ctx, h.l).Error("failed to create coin, duplicate coin name",
 				logger.Error(err))
 			return
 		}
@@ -79,7 +124,7 @@ func (h *CoinHandler) Create(ctx *gin.Context) {
 			Code: 500,
 			Msg:  "internal server error",
 		})
-		h.l.Error("failed to create coin",
+		logger.FromContext(ctx, h.l).Error("failed to create coin",
 			logger.Error(err))
 		return
 	}
@@ -87,44 +132,120 @@ func (h *CoinHandler) Create(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, Result{
 		Code: 200,
 		Data: CoinVo{
-			Id:              coin.Id,
+			Id:              coin.PublicId,
 			Name:            coin.Name,
 			Description:     coin.Description,
 			CreatedAt:       coin.CreatedAt.Format(time.DateTime),
 			UpdatedAt:       coin.UpdatedAt.Format(time.DateTime),
 			PopularityScore: coin.PopularityScore,
+			HotScore:        coin.HotScore,
+			Reactions:       reactionsVo(coin.Reactions),
 		},
 	})
-	ctx.Header("Location", fmt.Sprintf("/api/v1/meme-coins/%d", coin.Id))
+	ctx.Header("Location", fmt.Sprintf("/api/v1/meme-coins/%s", coin.PublicId))
+}
+
+// List is used to list the coins in order.
+// @Summary List meme coins
+// @Description List the coins, the hottest first by default.
+// @Tags Coins
+// @Accept json
+// @Produce json
+// @Param sort query string false "hot, popular or recent" default(hot)
+// @Param limit query int false "Number of coins, 1 to 100" default(20)
+// @Success 200 {object} Result{data=[]CoinVo}
+// @Failure 400 {object} Result
+// @Failure 500 {object} Result
+// @Router /api/v1/meme-coins [get]
+func (h *CoinHandler) List(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Msg:  "invalid limit param",
+			Code: 400,
+		})
+		return
+	}
+
+	coins, err := h.svc.List(ctx, domain.CoinSort(ctx.DefaultQuery("sort", string(domain.CoinSortHot))), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownSort) {
+			ctx.JSON(http.StatusBadRequest, Result{
+				Msg:  "invalid sort param",
+				Code: 400,
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "internal server error",
+		})
+		logger.FromContext(ctx, h.l).Error("failed to list coins",
+			logger.Error(err))
+		return
+	}
+
+	vos := make([]CoinVo, 0, len(coins))
+	for _, coin := range coins {
+		vos = append(vos, CoinVo{
+			Id:              coin.PublicId,
+			Name:            coin.Name,
+			Description:     coin.Description,
+			CreatedAt:       coin.CreatedAt.Format(time.DateTime),
+			UpdatedAt:       coin.UpdatedAt.Format(time.DateTime),
+			PopularityScore: coin.PopularityScore,
+			HotScore:        coin.HotScore,
+			Reactions:       reactionsVo(coin.Reactions),
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Data: vos,
+	})
 }
 
 // Detail is used to get a coin info by id.
 // @Summary Get meme coin
-// @Description Get a coin info by id.
+// @Description Get a coin info by id, or as it was at the time asOf.
 // @Tags Coins
 // @Accept json
 // @Produce json
-// @Param id path string true "Coin ID"
+// @Param id path string true "Coin public id (ULID)"
+// @Param asOf query string false "RFC 3339 timestamp, e.g. 2024-06-01T00:00:00Z"
 // @Success 200 {object} Result{data=CoinVo}
 // @Failure 400 {object} Result
 // @Failure 404 {object} Result
 // @Failure 500 {object} Result
 // @Router /api/v1/meme-coins/{id} [get]
 func (h *CoinHandler) Detail(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
+	id, ok := publicIdParam(ctx)
+	if !ok {
 		ctx.JSON(http.StatusBadRequest, Result{
 			Msg:  "invalid id param",
 			Code: 400,
 		})
-		h.l.Error("failed to get coin detail, invalid id",
-			logger.Error(err),
-			logger.String("id", idStr))
+		logger.FromContext(ctx, h.l).Error("failed to get coin detail, invalid id",
+			logger.String("id", ctx.Param("id")))
 		return
 	}
 
-	coin, err := h.svc.GetById(ctx, id)
+	var coin domain.Coin
+	var err error
+	if asOf := ctx.Query("asOf"); asOf != "" {
+		at, er := time.Parse(time.RFC3339, asOf)
+		if er != nil {
+			ctx.JSON(http.StatusBadRequest, Result{
+				Msg:  "invalid asOf param",
+				Code: 400,
+			})
+			logger.FromContext(ctx, h.l).Error("failed to get coin detail, invalid asOf",
+				logger.String("asOf", asOf))
+			return
+		}
+		coin, err = h.svc.GetByPublicIdAsOf(ctx, id, at)
+	} else {
+		coin, err = h.svc.GetByPublicId(ctx, id)
+	}
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			ctx.JSON(http.StatusNotFound, Result{
@@ -137,21 +258,23 @@ func (h *CoinHandler) Detail(ctx *gin.Context) {
 			Code: 500,
 			Msg:  "internal server error",
 		})
-		h.l.Error("failed to get coin detail",
+		logger.FromContext(ctx, h.l).Error("failed to get coin detail",
 			logger.Error(err),
-			logger.Int64("id", id))
+			logger.String("id", id))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, Result{
 		Code: 200,
 		Data: CoinVo{
-			Id:              coin.Id,
+			Id:              coin.PublicId,
 			Name:            coin.Name,
 			Description:     coin.Description,
 			CreatedAt:       coin.CreatedAt.Format(time.DateTime),
 			UpdatedAt:       coin.UpdatedAt.Format(time.DateTime),
 			PopularityScore: coin.PopularityScore,
+			HotScore:        coin.HotScore,
+			Reactions:       reactionsVo(coin.Reactions),
 		},
 	})
 }
@@ -162,10 +285,10 @@ func (h *CoinHandler) Detail(ctx *gin.Context) {
 // @Tags Coins
 // @Accept json
 // @Produce json
-// @Param id path string true "Coin ID"
+// @Param id path string true "Coin public id (ULID)"
 // @Param payload body UpdateCoinReq true "coin"
 // @Success 200 {object} Result
-// @Failure 400 {object} Result
+// @Failure 400 {object} Result{data=[]service.FieldError}
 // @Failure 500 {object} Result
 // @Router /api/v1/meme-coins/{id} [put]
 func (h *CoinHandler) Update(ctx *gin.Context) {
@@ -175,55 +298,56 @@ func (h *CoinHandler) Update(ctx *gin.Context) {
 			Msg:  "invalid input",
 			Code: 400,
 		})
-		h.l.Error("failed to create coin, invalid input",
+		logger.FromContext(ctx, h.l).Error("failed to create coin, invalid input",
 			logger.Error(err))
 		return
 	}
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
+	id, ok := publicIdParam(ctx)
+	if !ok {
 		ctx.JSON(http.StatusBadRequest, Result{
 			Msg:  "invalid id param",
 			Code: 400,
 		})
-		h.l.Error("failed to update coin, invalid id",
-			logger.Error(err),
-			logger.String("id", idStr))
+		logger.FromContext(ctx, h.l).Error("failed to update coin, invalid id",
+			logger.String("id", ctx.Param("id")))
 		return
 	}
 
-	c, err := h.svc.GetById(ctx, id)
+	// the service reads and locks the coin in the transaction of the update
+	err := h.svc.Update(ctx, domain.Coin{
+		PublicId:    id,
+		Description: req.Description,
+	})
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			ctx.JSON(http.StatusBadRequest, Result{
 				Msg:  "invalid id param",
 				Code: 400,
 			})
-			h.l.Error("failed to update coin, input id not found",
+			logger.FromContext(ctx, h.l).Error("failed to update coin, input id not found",
 				logger.Error(err),
-				logger.Int64("id", id))
+				logger.String("id", id))
+			return
+		}
+		var ve *service.ValidationError
+		if errors.As(err, &ve) {
+			ctx.JSON(http.StatusBadRequest, Result{
+				Msg:  "invalid input",
+				Code: 400,
+				Data: ve.Fields,
+			})
+			logger.FromContext(ctx, h.l).Error("failed to update coin, invalid input",
+				logger.Error(err),
+				logger.String("id", id))
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
 			Msg:  "internal server error",
 		})
-		h.l.Error("failed to update coin, get coin by id error",
-			logger.Error(err),
-			logger.Int64("id", id))
-		return
-	}
-	c.Description = req.Description
-
-	err = h.svc.Update(ctx, c)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, Result{
-			Code: 500,
-			Msg:  "internal server error",
-		})
-		h.l.Error("failed to update coin",
+		logger.FromContext(ctx, h.l).Error("failed to update coin",
 			logger.Error(err),
-			logger.Int64("id", id))
+			logger.String("id", id))
 		return
 	}
 	ctx.JSON(http.StatusOK, Result{
@@ -238,35 +362,32 @@ func (h *CoinHandler) Update(ctx *gin.Context) {
 // @Tags Coins
 // @Accept json
 // @Produce json
-// @Param id path string true "Coin ID"
+// @Param id path string true "Coin public id (ULID)"
 // @Success 204 {object} Result
 // @Failure 400 {object} Result
 // @Failure 500 {object} Result
 // @Router /api/v1/meme-coins/{id} [delete]
 func (h *CoinHandler) Delete(ctx *gin.Context) {
-	var err error
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
+	id, ok := publicIdParam(ctx)
+	if !ok {
 		ctx.JSON(http.StatusBadRequest, Result{
 			Msg:  "invalid id param",
 			Code: 400,
 		})
-		h.l.Error("failed to delete coin, invalid id",
-			logger.Error(err),
-			logger.String("id", idStr))
+		logger.FromContext(ctx, h.l).Error("failed to delete coin, invalid id",
+			logger.String("id", ctx.Param("id")))
 		return
 	}
 
-	err = h.svc.DeleteById(ctx, id)
+	err := h.svc.DeleteByPublicId(ctx, id)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
 			Msg:  "internal server error",
 		})
-		h.l.Error("failed to delete coin",
+		logger.FromContext(ctx, h.l).Error("failed to delete coin",
 			logger.Error(err),
-			logger.Int64("id", id))
+			logger.String("id", id))
 		return
 	}
 	ctx.Status(http.StatusNoContent)
@@ -274,49 +395,146 @@ func (h *CoinHandler) Delete(ctx *gin.Context) {
 
 // Poke is used to poke a meme coin to show your interest in its ID
 // @Summary Poke meme coin
-// @Description Poke a meme coin to show your interest in its ID
+// @Description Poke a meme coin to show your interest in its ID, once per client.
 // @Tags Coins
 // @Accept json
 // @Produce json
-// @Param id path string true "Coin ID"
+// @Param id path string true "Coin public id (ULID)"
+// @Param X-Client-Id header string false "Signed client id, the client is fingerprinted without it"
 // @Success 200 {object} Result
 // @Failure 400 {object} Result
+// @Failure 409 {object} Result
 // @Failure 500 {object} Result
 // @Router /api/v1/meme-coins/{id}/poke [post]
 func (h *CoinHandler) Poke(ctx *gin.Context) {
-	var err error
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
+	h.react(ctx, domain.ReactionPoke)
+}
+
+// Unpoke is used to withdraw the poke of a meme coin
+// @Summary Unpoke meme coin
+// @Description Withdraw the poke of the client, succeeds when it did not poke.
+// @Tags Coins
+// @Accept json
+// @Produce json
+// @Param id path string true "Coin public id (ULID)"
+// @Param X-Client-Id header string false "Signed client id, the client is fingerprinted without it"
+// @Success 200 {object} Result
+// @Failure 400 {object} Result
+// @Failure 500 {object} Result
+// @Router /api/v1/meme-coins/{id}/poke [delete]
+func (h *CoinHandler) Unpoke(ctx *gin.Context) {
+	h.unreact(ctx, domain.ReactionPoke)
+}
+
+// React is used to add a reaction to a meme coin
+// @Summary React to meme coin
+// @Description Add a reaction to a meme coin, once per client and reaction. A poke reaction is a poke.
+// @Tags Coins
+// @Accept json
+// @Produce json
+// @Param id path string true "Coin public id (ULID)"
+// @Param reaction path string true "poke, rocket, skull, fire or heart"
+// @Param X-Client-Id header string false "Signed client id, the client is fingerprinted without it"
+// @Success 200 {object} Result
+// @Failure 400 {object} Result
+// @Failure 409 {object} Result
+// @Failure 500 {object} Result
+// @Router /api/v1/meme-coins/{id}/reactions/{reaction} [post]
+func (h *CoinHandler) React(ctx *gin.Context) {
+	h.react(ctx, domain.ReactionType(ctx.Param("reaction")))
+}
+
+// Unreact is used to withdraw a reaction to a meme coin
+// @Summary Withdraw reaction to meme coin
+// @Description Withdraw a reaction of the client, succeeds when it did not react.
+// @Tags Coins
+// @Accept json
+// @Produce json
+// @Param id path string true "Coin public id (ULID)"
+// @Param reaction path string true "poke, rocket, skull, fire or heart"
+// @Param X-Client-Id header string false "Signed client id, the client is fingerprinted without it"
+// @Success 200 {object} Result
+// @Failure 400 {object} Result
+// @Failure 500 {object} Result
+// @Router /api/v1/meme-coins/{id}/reactions/{reaction} [delete]
+func (h *CoinHandler) Unreact(ctx *gin.Context) {
+	h.unreact(ctx, domain.ReactionType(ctx.Param("reaction")))
+}
+
+func (h *CoinHandler) react(ctx *gin.Context, reaction domain.ReactionType) {
+	id, actor, ok := h.reactionParams(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.React(ctx, id, actor, reaction)
+	if errors.Is(err, service.ErrDuplicateReaction) {
+		ctx.JSON(http.StatusConflict, Result{
+			Msg:  "reaction already added",
+			Code: 409,
+		})
+		return
+	}
+	h.reactionResult(ctx, id, reaction, err)
+}
+
+func (h *CoinHandler) unreact(ctx *gin.Context, reaction domain.ReactionType) {
+	id, actor, ok := h.reactionParams(ctx)
+	if !ok {
+		return
+	}
+	h.reactionResult(ctx, id, reaction, h.svc.Unreact(ctx, id, actor, reaction))
+}
+
+// reactionParams reads the coin and the actor of a reaction, it responds itself when they are invalid.
+func (h *CoinHandler) reactionParams(ctx *gin.Context) (string, string, bool) {
+	id, ok := publicIdParam(ctx)
+	if !ok {
 		ctx.JSON(http.StatusBadRequest, Result{
 			Msg:  "invalid id param",
 			Code: 400,
 		})
-		h.l.Error("failed to poke coin, invalid id",
-			logger.Error(err),
-			logger.String("id", idStr))
-		return
+		logger.FromContext(ctx, h.l).Error("failed to react to coin, invalid id",
+			logger.String("id", ctx.Param("id")))
+		return "", "", false
+	}
+	actor, ok := h.actorOf(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Msg:  "invalid client id",
+			Code: 400,
+		})
+		return "", "", false
 	}
+	return id, actor, true
+}
 
-	err = h.svc.IncrPopularityScore(ctx, id)
+func (h *CoinHandler) reactionResult(ctx *gin.Context, id string, reaction domain.ReactionType, err error) {
 	if err != nil {
+		if errors.Is(err, service.ErrUnknownReaction) {
+			ctx.JSON(http.StatusBadRequest, Result{
+				Msg:  "invalid reaction param",
+				Code: 400,
+			})
+			return
+		}
 		if errors.Is(err, service.ErrNotFound) {
 			ctx.JSON(http.StatusBadRequest, Result{
 				Msg:  "invalid id param",
 				Code: 400,
 			})
-			h.l.Error("failed to poke coin, input id not found",
+			logger.FromContext(ctx, h.l).Error("failed to react to coin, input id not found",
 				logger.Error(err),
-				logger.Int64("id", id))
+				logger.String("id", id))
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, Result{
 			Code: 500,
 			Msg:  "internal server error",
 		})
-		h.l.Error("failed to poke coin",
+		logger.FromContext(ctx, h.l).Error("failed to react to coin",
 			logger.Error(err),
-			logger.Int64("id", id))
+			logger.String("id", id),
+			logger.String("reaction", string(reaction)))
 		return
 	}
 	ctx.JSON(http.StatusOK, Result{
@@ -324,3 +542,135 @@ func (h *CoinHandler) Poke(ctx *gin.Context) {
 		Msg:  "OK",
 	})
 }
+
+// Revisions is used to list the history of a meme coin
+// @Summary List meme coin revisions
+// @Description List the revisions of a meme coin in the order they were made, with the fields changed by each one.
+// @Description A deleted coin keeps its revisions. Pass the last revision of a page as after to get the next one.
+// @Tags Coins
+// @Accept json
+// @Produce json
+// @Param id path string true "Coin public id (ULID)"
+// @Param after query int false "List the revisions following this one"
+// @Param limit query int false "Page size, 1 to 100" default(20)
+// @Success 200 {object} Result{data=[]CoinRevisionVo}
+// @Failure 400 {object} Result
+// @Failure 404 {object} Result
+// @Failure 500 {object} Result
+// @Router /api/v1/meme-coins/{id}/revisions [get]
+func (h *CoinHandler) Revisions(ctx *gin.Context) {
+	id, ok := publicIdParam(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Msg:  "invalid id param",
+			Code: 400,
+		})
+		logger.FromContext(ctx, h.l).Error("failed to list coin revisions, invalid id",
+			logger.String("id", ctx.Param("id")))
+		return
+	}
+	after, err := strconv.ParseInt(ctx.DefaultQuery("after", "0"), 10, 64)
+	if err != nil || after < 0 {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Msg:  "invalid after param",
+			Code: 400,
+		})
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Msg:  "invalid limit param",
+			Code: 400,
+		})
+		return
+	}
+
+	revs, err := h.svc.ListRevisions(ctx, id, after, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, Result{
+				Code: 404,
+				Msg:  "coin not found",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "internal server error",
+		})
+		logger.FromContext(ctx, h.l).Error("failed to list coin revisions",
+			logger.Error(err),
+			logger.String("id", id))
+		return
+	}
+
+	vos := make([]CoinRevisionVo, 0, len(revs))
+	for _, rev := range revs {
+		changes := make([]CoinChangeVo, 0, len(rev.Changes))
+		for _, c := range rev.Changes {
+			changes = append(changes, CoinChangeVo{
+				Field: c.Field,
+				From:  c.From,
+				To:    c.To,
+			})
+		}
+		vos = append(vos, CoinRevisionVo{
+			Revision:  rev.Revision,
+			Action:    string(rev.Action),
+			ValidFrom: rev.ValidFrom.UTC().Format(time.RFC3339Nano),
+			Coin: CoinVo{
+				Id:              rev.Coin.PublicId,
+				Name:            rev.Coin.Name,
+				Description:     rev.Coin.Description,
+				CreatedAt:       rev.Coin.CreatedAt.Format(time.DateTime),
+				UpdatedAt:       rev.Coin.UpdatedAt.Format(time.DateTime),
+				PopularityScore: rev.Coin.PopularityScore,
+				Reactions:       reactionsVo(rev.Coin.Reactions),
+			},
+			Changes: changes,
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Data: vos,
+	})
+}
+
+// ClientIdHeader carries the id of the client reacting, signed by SignClientId. Clients without one are fingerprinted.
+const ClientIdHeader = "X-Client-Id"
+
+var clientIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// SignClientId returns the value of ClientIdHeader for the client id, issued by whoever holds the secret.
+func SignClientId(secret, id string) string {
+	return id + "." + clientIdSignature([]byte(secret), id)
+}
+
+func clientIdSignature(secret []byte, id string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// actorOf returns who reacts, the signed client id of the request or else a fingerprint of its address
+// and user agent. The client ids are only trusted with a secret, clients could mint new ones otherwise
+// to react any number of times.
+func (h *CoinHandler) actorOf(ctx *gin.Context) (string, bool) {
+	if value := ctx.GetHeader(ClientIdHeader); value != "" && h.clientIdSecret != nil {
+		id, sig, found := strings.Cut(value, ".")
+		if !found || !clientIdPattern.MatchString(id) ||
+			!hmac.Equal([]byte(sig), []byte(clientIdSignature(h.clientIdSecret, id))) {
+			return "", false
+		}
+		return "client:" + id, true
+	}
+	sum := sha256.Sum256([]byte(ctx.ClientIP() + "\n" + ctx.Request.UserAgent()))
+	return "fp:" + hex.EncodeToString(sum[:16]), true
+}
+
+// publicIdParam returns the id path param, the public id of a coin. Public ids are case-insensitive.
+func publicIdParam(ctx *gin.Context) (string, bool) {
+	id := strings.ToUpper(ctx.Param("id"))
+	return id, ulid.Valid(id)
+}
@@ -10,13 +10,19 @@ import (
 	svcmocks "github.com/miles0wu/meme-coin-api/internal/service/mocks"
 	"github.com/miles0wu/meme-coin-api/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
 
+const publicId = "01ARYZ6S410000000000000001"
+
+const clientIdSecret = "test-secret"
+
 func TestCoinHandler_Create(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -37,6 +43,7 @@ func TestCoinHandler_Create(t *testing.T) {
 					Description: "desc",
 				}).Return(domain.Coin{
 					Id:              1,
+					PublicId:        publicId,
 					Name:            "demo",
 					Description:     "desc",
 					CreatedAt:       time.Now(),
@@ -61,12 +68,13 @@ func TestCoinHandler_Create(t *testing.T) {
 			wantBody: Result{
 				Code: 200,
 				Data: CoinVo{
-					Id:              1,
+					Id:              publicId,
 					Name:            "demo",
 					Description:     "desc",
 					CreatedAt:       time.Now().Format(time.DateTime),
 					UpdatedAt:       time.Now().Format(time.DateTime),
 					PopularityScore: 0,
+					Reactions:       map[string]int64{},
 				},
 			},
 		},
@@ -121,6 +129,36 @@ func TestCoinHandler_Create(t *testing.T) {
 				Msg:  "invalid input",
 			},
 		},
+		{
+			name: "validation error",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().Create(gomock.Any(), domain.Coin{
+					Description: "desc",
+				}).Return(domain.Coin{}, &service.ValidationError{
+					Fields: []service.FieldError{{Field: "name", Message: "is required"}},
+				})
+				return coinSvc
+			},
+			reqBuilder: func(t *testing.T) *http.Request {
+				body := bytes.NewBuffer([]byte(`{"name": "", "description": "desc"}`))
+				req, err := http.NewRequest(
+					http.MethodPost,
+					"/api/v1/meme-coins",
+					body)
+				req.Header.Set("Content-Type", "application/json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid input",
+				Data: []service.FieldError{{Field: "name", Message: "is required"}},
+			},
+		},
 		{
 			name: "db error",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
@@ -176,6 +214,136 @@ func TestCoinHandler_Create(t *testing.T) {
 	}
 }
 
+func TestCoinHandler_List(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.CoinService
+
+		url string
+
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "hot by default",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().List(gomock.Any(), domain.CoinSortHot, 20).Return([]domain.Coin{
+					{
+						Id:              1,
+						PublicId:        publicId,
+						Name:            "demo",
+						CreatedAt:       now,
+						UpdatedAt:       now,
+						PopularityScore: 3,
+						HotScore:        1.5,
+						Reactions: map[domain.ReactionType]int64{
+							domain.ReactionPoke:   3,
+							domain.ReactionRocket: 1,
+						},
+					},
+				}, nil)
+				return coinSvc
+			},
+			url:      "/api/v1/meme-coins",
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Data: []CoinVo{
+					{
+						Id:              publicId,
+						Name:            "demo",
+						CreatedAt:       now.Format(time.DateTime),
+						UpdatedAt:       now.Format(time.DateTime),
+						PopularityScore: 3,
+						HotScore:        1.5,
+						Reactions:       map[string]int64{"poke": 3, "rocket": 1},
+					},
+				},
+			},
+		},
+		{
+			name: "recent",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().List(gomock.Any(), domain.CoinSortRecent, 5).Return(nil, nil)
+				return coinSvc
+			},
+			url:      "/api/v1/meme-coins?sort=recent&limit=5",
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Data: []CoinVo{},
+			},
+		},
+		{
+			name: "invalid sort",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().List(gomock.Any(), domain.CoinSort("name"), 20).Return(nil, service.ErrUnknownSort)
+				return coinSvc
+			},
+			url:      "/api/v1/meme-coins?sort=name",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid sort param",
+			},
+		},
+		{
+			name: "invalid limit",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			url:      "/api/v1/meme-coins?limit=0",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid limit param",
+			},
+		},
+		{
+			name: "internal error",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().List(gomock.Any(), domain.CoinSortPopular, 20).Return(nil, errors.New("mock error"))
+				return coinSvc
+			},
+			url:      "/api/v1/meme-coins?sort=popular",
+			wantCode: http.StatusInternalServerError,
+			wantBody: Result{
+				Code: 500,
+				Msg:  "internal server error",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinSvc := tc.mock(ctrl)
+			hdl := NewCoinHandler(coinSvc, logger.NewNopLogger())
+
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			bs, err := json.Marshal(tc.wantBody)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, string(bs), recorder.Body.String())
+		})
+	}
+}
+
 func TestCoinHandler_Detail(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -191,8 +359,9 @@ func TestCoinHandler_Detail(t *testing.T) {
 			name: "get success",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Coin{
+				coinSvc.EXPECT().GetByPublicId(gomock.Any(), publicId).Return(domain.Coin{
 					Id:              1,
+					PublicId:        publicId,
 					Name:            "demo",
 					Description:     "desc",
 					CreatedAt:       time.Now(),
@@ -204,7 +373,7 @@ func TestCoinHandler_Detail(t *testing.T) {
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodGet,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -216,15 +385,123 @@ func TestCoinHandler_Detail(t *testing.T) {
 			wantBody: Result{
 				Code: 200,
 				Data: CoinVo{
-					Id:              1,
+					Id:              publicId,
 					Name:            "demo",
 					Description:     "desc",
 					CreatedAt:       time.Now().Format(time.DateTime),
 					UpdatedAt:       time.Now().Format(time.DateTime),
 					PopularityScore: 0,
+					Reactions:       map[string]int64{},
 				},
 			},
 		},
+		{
+			name: "get as of",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+				coinSvc.EXPECT().GetByPublicIdAsOf(gomock.Any(), publicId, at).Return(domain.Coin{
+					Id:          1,
+					PublicId:    publicId,
+					Name:        "demo",
+					Description: "old desc",
+					CreatedAt:   at,
+					UpdatedAt:   at,
+				}, nil)
+				return coinSvc
+			},
+			reqBuilder: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(
+					http.MethodGet,
+					"/api/v1/meme-coins/"+publicId+"?asOf=2024-06-01T00:00:00Z",
+					nil)
+				req.Header.Set("Content-Type", "application/json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return req
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Data: CoinVo{
+					Id:          publicId,
+					Name:        "demo",
+					Description: "old desc",
+					CreatedAt:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).Format(time.DateTime),
+					UpdatedAt:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).Format(time.DateTime),
+					Reactions:   map[string]int64{},
+				},
+			},
+		},
+		{
+			name: "invalid asOf",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			reqBuilder: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(
+					http.MethodGet,
+					"/api/v1/meme-coins/"+publicId+"?asOf=yesterday",
+					nil)
+				req.Header.Set("Content-Type", "application/json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid asOf param",
+			},
+		},
+		{
+			name: "lower case public id",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().GetByPublicId(gomock.Any(), publicId).Return(domain.Coin{}, service.ErrNotFound)
+				return coinSvc
+			},
+			reqBuilder: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(
+					http.MethodGet,
+					"/api/v1/meme-coins/"+strings.ToLower(publicId),
+					nil)
+				req.Header.Set("Content-Type", "application/json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return req
+			},
+			wantCode: http.StatusNotFound,
+			wantBody: Result{
+				Code: 404,
+				Msg:  "coin not found",
+			},
+		},
+		{
+			name: "sequential id rejected",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			reqBuilder: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(
+					http.MethodGet,
+					"/api/v1/meme-coins/1",
+					nil)
+				req.Header.Set("Content-Type", "application/json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid id param",
+			},
+		},
 		{
 			name: "invalid id param",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
@@ -251,13 +528,13 @@ func TestCoinHandler_Detail(t *testing.T) {
 			name: "coin id not found",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Coin{}, service.ErrNotFound)
+				coinSvc.EXPECT().GetByPublicId(gomock.Any(), publicId).Return(domain.Coin{}, service.ErrNotFound)
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodGet,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -275,13 +552,13 @@ func TestCoinHandler_Detail(t *testing.T) {
 			name: "db error",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Coin{}, errors.New("mock db error"))
+				coinSvc.EXPECT().GetByPublicId(gomock.Any(), publicId).Return(domain.Coin{}, errors.New("mock db error"))
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodGet,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -336,23 +613,10 @@ func TestCoinHandler_Update(t *testing.T) {
 		{
 			name: "update success",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
-				now := time.Now()
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Coin{
-					Id:              1,
-					Name:            "demo",
-					Description:     "desc",
-					CreatedAt:       now,
-					UpdatedAt:       now,
-					PopularityScore: 0,
-				}, nil)
 				coinSvc.EXPECT().Update(gomock.Any(), domain.Coin{
-					Id:              1,
-					Name:            "demo",
-					Description:     "desc1",
-					CreatedAt:       now,
-					UpdatedAt:       now,
-					PopularityScore: 0,
+					PublicId:    publicId,
+					Description: "desc1",
 				}).Return(nil)
 				return coinSvc
 			},
@@ -360,7 +624,7 @@ func TestCoinHandler_Update(t *testing.T) {
 				body := bytes.NewBuffer([]byte(`{"description": "desc1"}`))
 				req, err := http.NewRequest(
 					http.MethodPut,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					body)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -383,7 +647,7 @@ func TestCoinHandler_Update(t *testing.T) {
 				body := bytes.NewBuffer([]byte(`{"description": "de}`))
 				req, err := http.NewRequest(
 					http.MethodPut,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					body)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -424,14 +688,14 @@ func TestCoinHandler_Update(t *testing.T) {
 			name: "coin id not found",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Coin{}, service.ErrNotFound)
+				coinSvc.EXPECT().Update(gomock.Any(), gomock.Any()).Return(service.ErrNotFound)
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				body := bytes.NewBuffer([]byte(`{"description": "desc1"}`))
 				req, err := http.NewRequest(
 					http.MethodPut,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					body)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -446,17 +710,19 @@ func TestCoinHandler_Update(t *testing.T) {
 			},
 		},
 		{
-			name: "get db error",
+			name: "update validation error",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Coin{}, errors.New("mock db error"))
+				coinSvc.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&service.ValidationError{
+					Fields: []service.FieldError{{Field: "description", Message: "must be at most 128 characters"}},
+				})
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				body := bytes.NewBuffer([]byte(`{"description": "desc1"}`))
 				req, err := http.NewRequest(
 					http.MethodPut,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					body)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -464,32 +730,20 @@ func TestCoinHandler_Update(t *testing.T) {
 				}
 				return req
 			},
-			wantCode: http.StatusInternalServerError,
+			wantCode: http.StatusBadRequest,
 			wantBody: Result{
-				Code: 500,
-				Msg:  "internal server error",
+				Code: 400,
+				Msg:  "invalid input",
+				Data: []service.FieldError{{Field: "description", Message: "must be at most 128 characters"}},
 			},
 		},
 		{
 			name: "update db error",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
-				now := time.Now()
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Coin{
-					Id:              1,
-					Name:            "demo",
-					Description:     "desc",
-					CreatedAt:       now,
-					UpdatedAt:       now,
-					PopularityScore: 0,
-				}, nil)
 				coinSvc.EXPECT().Update(gomock.Any(), domain.Coin{
-					Id:              1,
-					Name:            "demo",
-					Description:     "desc1",
-					CreatedAt:       now,
-					UpdatedAt:       now,
-					PopularityScore: 0,
+					PublicId:    publicId,
+					Description: "desc1",
 				}).Return(errors.New("mock db error"))
 				return coinSvc
 			},
@@ -497,7 +751,7 @@ func TestCoinHandler_Update(t *testing.T) {
 				body := bytes.NewBuffer([]byte(`{"description": "desc1"}`))
 				req, err := http.NewRequest(
 					http.MethodPut,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					body)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -553,13 +807,13 @@ func TestCoinHandler_Delete(t *testing.T) {
 			name: "delete success",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(nil)
+				coinSvc.EXPECT().DeleteByPublicId(gomock.Any(), publicId).Return(nil)
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodDelete,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -595,13 +849,13 @@ func TestCoinHandler_Delete(t *testing.T) {
 			name: "coin id not found",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(nil)
+				coinSvc.EXPECT().DeleteByPublicId(gomock.Any(), publicId).Return(nil)
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodDelete,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -615,13 +869,13 @@ func TestCoinHandler_Delete(t *testing.T) {
 			name: "db error",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().DeleteById(gomock.Any(), int64(1)).Return(errors.New("mock db error"))
+				coinSvc.EXPECT().DeleteByPublicId(gomock.Any(), publicId).Return(errors.New("mock db error"))
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodDelete,
-					"/api/v1/meme-coins/1",
+					"/api/v1/meme-coins/"+publicId,
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -679,13 +933,13 @@ func TestCoinHandler_Poke(t *testing.T) {
 			name: "poke success",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().IncrPopularityScore(gomock.Any(), int64(1)).Return(nil)
+				coinSvc.EXPECT().React(gomock.Any(), publicId, gomock.Any(), domain.ReactionPoke).Return(nil)
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodPost,
-					"/api/v1/meme-coins/1/poke",
+					"/api/v1/meme-coins/"+publicId+"/poke",
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -725,13 +979,13 @@ func TestCoinHandler_Poke(t *testing.T) {
 			name: "coin id not found",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().IncrPopularityScore(gomock.Any(), int64(1)).Return(service.ErrNotFound)
+				coinSvc.EXPECT().React(gomock.Any(), publicId, gomock.Any(), domain.ReactionPoke).Return(service.ErrNotFound)
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodPost,
-					"/api/v1/meme-coins/1/poke",
+					"/api/v1/meme-coins/"+publicId+"/poke",
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -749,13 +1003,13 @@ func TestCoinHandler_Poke(t *testing.T) {
 			name: "db error",
 			mock: func(ctrl *gomock.Controller) service.CoinService {
 				coinSvc := svcmocks.NewMockCoinService(ctrl)
-				coinSvc.EXPECT().IncrPopularityScore(gomock.Any(), int64(1)).Return(errors.New("mock db error"))
+				coinSvc.EXPECT().React(gomock.Any(), publicId, gomock.Any(), domain.ReactionPoke).Return(errors.New("mock db error"))
 				return coinSvc
 			},
 			reqBuilder: func(t *testing.T) *http.Request {
 				req, err := http.NewRequest(
 					http.MethodPost,
-					"/api/v1/meme-coins/1/poke",
+					"/api/v1/meme-coins/"+publicId+"/poke",
 					nil)
 				req.Header.Set("Content-Type", "application/json")
 				if err != nil {
@@ -769,6 +1023,75 @@ func TestCoinHandler_Poke(t *testing.T) {
 				Msg:  "internal server error",
 			},
 		},
+		{
+			name: "already poked",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().React(gomock.Any(), publicId, "client:abc-123", domain.ReactionPoke).
+					Return(service.ErrDuplicateReaction)
+				return coinSvc
+			},
+			reqBuilder: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(
+					http.MethodPost,
+					"/api/v1/meme-coins/"+publicId+"/poke",
+					nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set(ClientIdHeader, SignClientId(clientIdSecret, "abc-123"))
+				return req
+			},
+			wantCode: http.StatusConflict,
+			wantBody: Result{
+				Code: 409,
+				Msg:  "reaction already added",
+			},
+		},
+		{
+			name: "invalid client id",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			reqBuilder: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(
+					http.MethodPost,
+					"/api/v1/meme-coins/"+publicId+"/poke",
+					nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set(ClientIdHeader, "not a client id")
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid client id",
+			},
+		},
+		{
+			name: "forged client id",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			reqBuilder: func(t *testing.T) *http.Request {
+				req, err := http.NewRequest(
+					http.MethodPost,
+					"/api/v1/meme-coins/"+publicId+"/poke",
+					nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set(ClientIdHeader, SignClientId("another secret", "abc-123"))
+				return req
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid client id",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -777,7 +1100,7 @@ func TestCoinHandler_Poke(t *testing.T) {
 			defer ctrl.Finish()
 			coinSvc := tc.mock(ctrl)
 			// build handler
-			hdl := NewCoinHandler(coinSvc, logger.NewNopLogger())
+			hdl := NewCoinHandler(coinSvc, logger.NewNopLogger(), WithClientIdSecret(clientIdSecret))
 
 			// register route
 			server := gin.Default()
@@ -795,3 +1118,315 @@ func TestCoinHandler_Poke(t *testing.T) {
 		})
 	}
 }
+
+func TestCoinHandler_Reactions(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.CoinService
+
+		method   string
+		url      string
+		clientId string
+		// noSecret builds the handler without a client id secret
+		noSecret bool
+
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "react success",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().React(gomock.Any(), publicId, "client:abc", domain.ReactionRocket).Return(nil)
+				return coinSvc
+			},
+			method:   http.MethodPost,
+			url:      "/api/v1/meme-coins/" + publicId + "/reactions/rocket",
+			clientId: SignClientId(clientIdSecret, "abc"),
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Msg:  "OK",
+			},
+		},
+		{
+			name: "unknown reaction",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().React(gomock.Any(), publicId, "client:abc", domain.ReactionType("smile")).
+					Return(service.ErrUnknownReaction)
+				return coinSvc
+			},
+			method:   http.MethodPost,
+			url:      "/api/v1/meme-coins/" + publicId + "/reactions/smile",
+			clientId: SignClientId(clientIdSecret, "abc"),
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid reaction param",
+			},
+		},
+		{
+			name: "unreact success",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().Unreact(gomock.Any(), publicId, "client:abc", domain.ReactionSkull).Return(nil)
+				return coinSvc
+			},
+			method:   http.MethodDelete,
+			url:      "/api/v1/meme-coins/" + publicId + "/reactions/skull",
+			clientId: SignClientId(clientIdSecret, "abc"),
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Msg:  "OK",
+			},
+		},
+		{
+			name: "unpoke fingerprinted client",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().Unreact(gomock.Any(), publicId, gomock.Cond(func(actor string) bool {
+					return strings.HasPrefix(actor, "fp:") && len(actor) == 35
+				}), domain.ReactionPoke).Return(nil)
+				return coinSvc
+			},
+			method:   http.MethodDelete,
+			url:      "/api/v1/meme-coins/" + publicId + "/poke",
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Msg:  "OK",
+			},
+		},
+		{
+			name: "unsigned client id",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			method:   http.MethodPost,
+			url:      "/api/v1/meme-coins/" + publicId + "/reactions/rocket",
+			clientId: "abc",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid client id",
+			},
+		},
+		{
+			name: "client id ignored without secret",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().React(gomock.Any(), publicId, gomock.Cond(func(actor string) bool {
+					return strings.HasPrefix(actor, "fp:")
+				}), domain.ReactionRocket).Return(nil)
+				return coinSvc
+			},
+			method:   http.MethodPost,
+			url:      "/api/v1/meme-coins/" + publicId + "/reactions/rocket",
+			clientId: "abc",
+			noSecret: true,
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Msg:  "OK",
+			},
+		},
+		{
+			name: "unreact db error",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().Unreact(gomock.Any(), publicId, "client:abc", domain.ReactionFire).
+					Return(errors.New("mock db error"))
+				return coinSvc
+			},
+			method:   http.MethodDelete,
+			url:      "/api/v1/meme-coins/" + publicId + "/reactions/fire",
+			clientId: SignClientId(clientIdSecret, "abc"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: Result{
+				Code: 500,
+				Msg:  "internal server error",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			var opts []CoinHandlerOption
+			if !tc.noSecret {
+				opts = append(opts, WithClientIdSecret(clientIdSecret))
+			}
+			hdl := NewCoinHandler(tc.mock(ctrl), logger.NewNopLogger(), opts...)
+
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+			if tc.clientId != "" {
+				req.Header.Set(ClientIdHeader, tc.clientId)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			bs, err := json.Marshal(tc.wantBody)
+			assert.NoError(t, err)
+			assert.Equal(t, string(bs), recorder.Body.String())
+		})
+	}
+}
+
+func TestCoinHandler_Revisions(t *testing.T) {
+	validFrom := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.CoinService
+
+		url string
+
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "list success",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().ListRevisions(gomock.Any(), publicId, int64(1), 20).Return([]domain.CoinRevision{
+					{
+						Revision: 2,
+						Action:   domain.RevisionActionUpdate,
+						Coin: domain.Coin{
+							Id:          1,
+							PublicId:    publicId,
+							Name:        "demo",
+							Description: "desc",
+							CreatedAt:   validFrom,
+							UpdatedAt:   validFrom,
+						},
+						ValidFrom: validFrom,
+						Changes: []domain.FieldChange{
+							{Field: "description", From: "", To: "desc"},
+						},
+					},
+				}, nil)
+				return coinSvc
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/revisions?after=1",
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Data: []CoinRevisionVo{
+					{
+						Revision:  2,
+						Action:    "update",
+						ValidFrom: "2024-06-01T00:00:00Z",
+						Coin: CoinVo{
+							Id:          publicId,
+							Name:        "demo",
+							Description: "desc",
+							CreatedAt:   validFrom.Format(time.DateTime),
+							UpdatedAt:   validFrom.Format(time.DateTime),
+							Reactions:   map[string]int64{},
+						},
+						Changes: []CoinChangeVo{
+							{Field: "description", From: "", To: "desc"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "coin not found",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().ListRevisions(gomock.Any(), publicId, int64(0), 5).Return(nil, service.ErrNotFound)
+				return coinSvc
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/revisions?limit=5",
+			wantCode: http.StatusNotFound,
+			wantBody: Result{
+				Code: 404,
+				Msg:  "coin not found",
+			},
+		},
+		{
+			name: "invalid limit",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/revisions?limit=1000",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid limit param",
+			},
+		},
+		{
+			name: "invalid after",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/revisions?after=-1",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid after param",
+			},
+		},
+		{
+			name: "invalid id param",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				return svcmocks.NewMockCoinService(ctrl)
+			},
+			url:      "/api/v1/meme-coins/abc/revisions",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid id param",
+			},
+		},
+		{
+			name: "internal error",
+			mock: func(ctrl *gomock.Controller) service.CoinService {
+				coinSvc := svcmocks.NewMockCoinService(ctrl)
+				coinSvc.EXPECT().ListRevisions(gomock.Any(), publicId, int64(0), 20).Return(nil, errors.New("mock error"))
+				return coinSvc
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/revisions",
+			wantCode: http.StatusInternalServerError,
+			wantBody: Result{
+				Code: 500,
+				Msg:  "internal server error",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			coinSvc := tc.mock(ctrl)
+			hdl := NewCoinHandler(coinSvc, logger.NewNopLogger())
+
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			bs, err := json.Marshal(tc.wantBody)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, string(bs), recorder.Body.String())
+		})
+	}
+}
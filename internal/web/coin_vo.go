@@ -1,12 +1,43 @@
 package web
 
+import "github.com/miles0wu/meme-coin-api/internal/domain"
+
 type CoinVo struct {
-	Id              int64  `json:"id"`
+	// Id is the public id of the coin, a ULID.
+	Id              string `json:"id"`
 	Name            string `json:"name"`
 	Description     string `json:"description"`
 	CreatedAt       string `json:"createdAt"`
 	UpdatedAt       string `json:"updated"`
 	PopularityScore uint32 `json:"popularityScore"`
+	// HotScore is the popularity decayed over time, it is not recorded by the revisions.
+	HotScore float64 `json:"hotScore"`
+	// Reactions counts the reactions by type, nor are they recorded by the revisions.
+	Reactions map[string]int64 `json:"reactions"`
+}
+
+func reactionsVo(reactions map[domain.ReactionType]int64) map[string]int64 {
+	res := make(map[string]int64, len(reactions))
+	for r, n := range reactions {
+		res[string(r)] = n
+	}
+	return res
+}
+
+type CoinRevisionVo struct {
+	Revision int64  `json:"revision"`
+	Action   string `json:"action"`
+	// ValidFrom is when the coin took this state, in RFC 3339. It holds until the next revision.
+	ValidFrom string `json:"validFrom"`
+	Coin      CoinVo `json:"coin"`
+	// Changes are the fields changed since the previous revision.
+	Changes []CoinChangeVo `json:"changes"`
+}
+
+type CoinChangeVo struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
 }
 
 type CreateCoinReq struct {
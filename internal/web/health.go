@@ -0,0 +1,125 @@
+package web
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/pkg/health"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type HealthHandler struct {
+	checkers []health.Checker
+	timeout  time.Duration
+	// drainDelay is how long Drain keeps serving with the readiness failing,
+	// so that the load balancers stop routing to the instance first
+	drainDelay time.Duration
+	draining   atomic.Bool
+}
+
+func NewHealthHandler(checkers []health.Checker, timeout, drainDelay time.Duration) *HealthHandler {
+	return &HealthHandler{
+		checkers:   checkers,
+		timeout:    timeout,
+		drainDelay: drainDelay,
+	}
+}
+
+func (h *HealthHandler) RegisterRoutes(server *gin.Engine) {
+	// GET /healthz
+	server.GET("/healthz", h.Live)
+	// GET /readyz
+	server.GET("/readyz", h.Ready)
+}
+
+// Drain fails the readiness from now on and waits the drain delay, call it before shutting the server down.
+func (h *HealthHandler) Drain(ctx context.Context) {
+	h.draining.Store(true)
+	select {
+	case <-ctx.Done():
+	case <-time.After(h.drainDelay):
+	}
+}
+
+// Live is used to report that the process is alive
+// @Summary Service liveness
+// @Description Report that the process is alive, without checking its dependencies
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Result{data=HealthVo}
+// @Router /healthz [get]
+func (h *HealthHandler) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Data: HealthVo{Status: health.StatusUp},
+	})
+}
+
+// Ready is used to report whether the service can serve traffic
+// @Summary Service readiness
+// @Description Report the status and latency of the dependencies, the service is not ready when one is down or while it is shutting down
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Result{data=HealthVo}
+// @Failure 503 {object} Result{data=HealthVo}
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(ctx *gin.Context) {
+	if h.draining.Load() {
+		ctx.JSON(http.StatusServiceUnavailable, Result{
+			Code: 503,
+			Msg:  "service is shutting down",
+			Data: HealthVo{
+				Status:   health.StatusDown,
+				Draining: true,
+			},
+		})
+		return
+	}
+
+	vo := HealthVo{
+		Status:     health.StatusUp,
+		Components: h.check(ctx),
+	}
+	for _, r := range vo.Components {
+		vo.Status = health.Worse(vo.Status, r.Status)
+	}
+	if vo.Status == health.StatusDown {
+		ctx.JSON(http.StatusServiceUnavailable, Result{
+			Code: 503,
+			Msg:  "service unavailable",
+			Data: vo,
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Data: vo,
+	})
+}
+
+// check runs the checkers concurrently, so that a slow dependency only costs its own timeout.
+func (h *HealthHandler) check(ctx context.Context) map[string]health.Report {
+	checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	reports := make([]health.Report, len(h.checkers))
+	var wg sync.WaitGroup
+	for i, c := range h.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			reports[i] = c.Check(checkCtx)
+			reports[i].LatencyMs = time.Since(start).Milliseconds()
+		}()
+	}
+	wg.Wait()
+
+	res := make(map[string]health.Report, len(h.checkers))
+	for i, c := range h.checkers {
+		res[c.Name()] = reports[i]
+	}
+	return res
+}
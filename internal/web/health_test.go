@@ -0,0 +1,180 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type stubChecker struct {
+	name   string
+	report health.Report
+}
+
+func (s stubChecker) Name() string {
+	return s.name
+}
+
+func (s stubChecker) Check(ctx context.Context) health.Report {
+	return s.report
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	testCases := []struct {
+		name     string
+		checkers []health.Checker
+
+		wantCode   int
+		wantStatus health.Status
+	}{
+		{
+			name:       "no dependency",
+			wantCode:   http.StatusOK,
+			wantStatus: health.StatusUp,
+		},
+		{
+			name: "all up",
+			checkers: []health.Checker{
+				stubChecker{name: "redis", report: health.Report{Status: health.StatusUp}},
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: health.StatusUp,
+		},
+		{
+			name: "degraded is still serving",
+			checkers: []health.Checker{
+				stubChecker{name: "redis", report: health.Report{Status: health.StatusDegraded}},
+				stubChecker{name: "mysql", report: health.Report{Status: health.StatusUp}},
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: health.StatusDegraded,
+		},
+		{
+			name: "down",
+			checkers: []health.Checker{
+				stubChecker{name: "redis", report: health.Report{Status: health.StatusDegraded}},
+				stubChecker{name: "mysql", report: health.Report{Status: health.StatusDown, Error: "ping failed"}},
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: health.StatusDown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hdl := NewHealthHandler(tc.checkers, time.Second, 0)
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			var res struct {
+				Data HealthVo `json:"data"`
+			}
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, res.Data.Status)
+			assert.Len(t, res.Data.Components, len(tc.checkers))
+		})
+	}
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	hdl := NewHealthHandler([]health.Checker{
+		stubChecker{name: "mysql", report: health.Report{Status: health.StatusDown}},
+	}, time.Second, 0)
+	server := gin.Default()
+	hdl.RegisterRoutes(server)
+
+	// the liveness does not depend on the dependencies, nor on the draining
+	for _, drain := range []bool{false, true} {
+		if drain {
+			hdl.Drain(context.Background())
+		}
+		req, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"code":200,"data":{"status":"up"}}`, recorder.Body.String())
+	}
+}
+
+func TestHealthHandler_Drain(t *testing.T) {
+	hdl := NewHealthHandler([]health.Checker{
+		stubChecker{name: "mysql", report: health.Report{Status: health.StatusUp}},
+	}, time.Second, time.Hour)
+	server := gin.Default()
+	hdl.RegisterRoutes(server)
+	ready := func() *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		return recorder
+	}
+	assert.Equal(t, http.StatusOK, ready().Code)
+
+	// the drain delay is cut short by the shutdown deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	hdl.Drain(ctx)
+
+	recorder := ready()
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(t, `{"code":503,"msg":"service is shutting down","data":{"status":"down","draining":true}}`,
+		recorder.Body.String())
+}
+
+type slowChecker struct {
+	name string
+}
+
+func (s slowChecker) Name() string {
+	return s.name
+}
+
+func (s slowChecker) Check(ctx context.Context) health.Report {
+	<-ctx.Done()
+	return health.Report{Status: health.StatusDown, Error: ctx.Err().Error()}
+}
+
+func TestHealthHandler_ReadyTimeout(t *testing.T) {
+	hdl := NewHealthHandler([]health.Checker{
+		slowChecker{name: "mysql"},
+		slowChecker{name: "db_shard_1"},
+		stubChecker{name: "redis", report: health.Report{Status: health.StatusUp}},
+	}, 50*time.Millisecond, 0)
+	server := gin.Default()
+	hdl.RegisterRoutes(server)
+
+	start := time.Now()
+	req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+
+	// the checks run concurrently, under a single timeout
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	var res struct {
+		Data HealthVo `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&res))
+	assert.Equal(t, health.StatusDown, res.Data.Components["mysql"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), res.Data.Components["db_shard_1"].Error)
+	assert.GreaterOrEqual(t, res.Data.Components["mysql"].LatencyMs, int64(50))
+	assert.Equal(t, health.StatusUp, res.Data.Components["redis"].Status)
+}
@@ -0,0 +1,10 @@
+package web
+
+import "github.com/miles0wu/meme-coin-api/pkg/health"
+
+type HealthVo struct {
+	Status health.Status `json:"status"`
+	// Draining is set once the service is shutting down.
+	Draining   bool                     `json:"draining,omitempty"`
+	Components map[string]health.Report `json:"components,omitempty"`
+}
@@ -0,0 +1,146 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"net/http"
+	"time"
+)
+
+// defaultScoreBuckets is the number of buckets returned when from is not set.
+var defaultScoreBuckets = map[domain.ScoreInterval]int{
+	domain.ScoreIntervalMinute: 60,
+	domain.ScoreIntervalHour:   24,
+	domain.ScoreIntervalDay:    30,
+}
+
+type ScoreHistoryHandler struct {
+	svc service.ScoreHistoryService
+	l   logger.Logger
+	now func() time.Time
+}
+
+func NewScoreHistoryHandler(svc service.ScoreHistoryService, l logger.Logger) *ScoreHistoryHandler {
+	return &ScoreHistoryHandler{
+		svc: svc,
+		l:   l,
+		now: time.Now,
+	}
+}
+
+func (h *ScoreHistoryHandler) RegisterRoutes(server *gin.Engine) {
+	cg := server.Group("/api/v1/meme-coins")
+	// GET /meme-coins/{id}/score-history
+	cg.GET("/:id/score-history", h.History)
+}
+
+// History is used to chart the pokes of a meme coin over time
+// @Summary Get meme coin score history
+// @Description Count the pokes of a meme coin per interval, one bucket per interval including the empty ones.
+// @Description Minute buckets are kept for a few days and hour buckets for a few months, older buckets are empty.
+// @Tags Coins
+// @Accept json
+// @Produce json
+// @Param id path string true "Coin public id (ULID)"
+// @Param interval query string false "Bucket width" Enums(minute, hour, day) default(hour)
+// @Param from query string false "RFC 3339 timestamp, defaults to 60 minutes, 24 hours or 30 days before to"
+// @Param to query string false "RFC 3339 timestamp, defaults to now"
+// @Success 200 {object} Result{data=ScoreHistoryVo}
+// @Failure 400 {object} Result
+// @Failure 404 {object} Result
+// @Failure 500 {object} Result
+// @Router /api/v1/meme-coins/{id}/score-history [get]
+func (h *ScoreHistoryHandler) History(ctx *gin.Context) {
+	id, ok := publicIdParam(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Msg:  "invalid id param",
+			Code: 400,
+		})
+		logger.FromContext(ctx, h.l).Error("failed to get score history, invalid id",
+			logger.String("id", ctx.Param("id")))
+		return
+	}
+	interval := domain.ScoreInterval(ctx.DefaultQuery("interval", string(domain.ScoreIntervalHour)))
+	if interval.Duration() == 0 {
+		ctx.JSON(http.StatusBadRequest, Result{
+			Msg:  "invalid interval param",
+			Code: 400,
+		})
+		return
+	}
+	to := h.now()
+	if s := ctx.Query("to"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, Result{
+				Msg:  "invalid to param",
+				Code: 400,
+			})
+			return
+		}
+		to = t
+	}
+	from := to.Add(-time.Duration(defaultScoreBuckets[interval]) * interval.Duration())
+	if s := ctx.Query("from"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, Result{
+				Msg:  "invalid from param",
+				Code: 400,
+			})
+			return
+		}
+		from = t
+	}
+
+	buckets, err := h.svc.History(ctx, id, interval, from, to)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, Result{
+			Code: 404,
+			Msg:  "coin not found",
+		})
+		return
+	case errors.Is(err, service.ErrInvalidRange):
+		ctx.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "from must be before to",
+		})
+		return
+	case errors.Is(err, service.ErrTooManyBuckets):
+		ctx.JSON(http.StatusBadRequest, Result{
+			Code: 400,
+			Msg:  "too many buckets, narrow the range or widen the interval",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusInternalServerError, Result{
+			Code: 500,
+			Msg:  "internal server error",
+		})
+		logger.FromContext(ctx, h.l).Error("failed to get score history",
+			logger.Error(err),
+			logger.String("id", id))
+		return
+	}
+
+	vo := ScoreHistoryVo{
+		Interval: string(interval),
+		Buckets:  make([]ScoreBucketVo, 0, len(buckets)),
+	}
+	for _, b := range buckets {
+		vo.Buckets = append(vo.Buckets, ScoreBucketVo{
+			Start: b.Start.UTC().Format(time.RFC3339),
+			Pokes: b.Pokes,
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Data: vo,
+	})
+}
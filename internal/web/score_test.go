@@ -0,0 +1,173 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/internal/domain"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	svcmocks "github.com/miles0wu/meme-coin-api/internal/service/mocks"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestScoreHistoryHandler_History(t *testing.T) {
+	now := time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.ScoreHistoryService
+
+		url string
+
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "defaults to the last 24 hours",
+			mock: func(ctrl *gomock.Controller) service.ScoreHistoryService {
+				svc := svcmocks.NewMockScoreHistoryService(ctrl)
+				svc.EXPECT().History(gomock.Any(), publicId, domain.ScoreIntervalHour, now.Add(-24*time.Hour), now).
+					Return([]domain.ScoreBucket{
+						{Start: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC), Pokes: 0},
+						{Start: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), Pokes: 3},
+					}, nil)
+				return svc
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/score-history",
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Data: ScoreHistoryVo{
+					Interval: "hour",
+					Buckets: []ScoreBucketVo{
+						{Start: "2024-06-01T09:00:00Z", Pokes: 0},
+						{Start: "2024-06-01T10:00:00Z", Pokes: 3},
+					},
+				},
+			},
+		},
+		{
+			name: "explicit range",
+			mock: func(ctrl *gomock.Controller) service.ScoreHistoryService {
+				svc := svcmocks.NewMockScoreHistoryService(ctrl)
+				svc.EXPECT().History(gomock.Any(), publicId, domain.ScoreIntervalDay,
+					time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)).
+					Return([]domain.ScoreBucket{
+						{Start: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Pokes: 7},
+					}, nil)
+				return svc
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/score-history?interval=day&from=2024-05-01T00:00:00Z&to=2024-05-02T00:00:00Z",
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 200,
+				Data: ScoreHistoryVo{
+					Interval: "day",
+					Buckets: []ScoreBucketVo{
+						{Start: "2024-05-01T00:00:00Z", Pokes: 7},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid interval",
+			mock: func(ctrl *gomock.Controller) service.ScoreHistoryService {
+				return svcmocks.NewMockScoreHistoryService(ctrl)
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/score-history?interval=week",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid interval param",
+			},
+		},
+		{
+			name: "invalid from",
+			mock: func(ctrl *gomock.Controller) service.ScoreHistoryService {
+				return svcmocks.NewMockScoreHistoryService(ctrl)
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/score-history?from=yesterday",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "invalid from param",
+			},
+		},
+		{
+			name: "too many buckets",
+			mock: func(ctrl *gomock.Controller) service.ScoreHistoryService {
+				svc := svcmocks.NewMockScoreHistoryService(ctrl)
+				svc.EXPECT().History(gomock.Any(), publicId, domain.ScoreIntervalMinute, gomock.Any(), now).
+					Return(nil, service.ErrTooManyBuckets)
+				return svc
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/score-history?interval=minute&from=2024-01-01T00:00:00Z",
+			wantCode: http.StatusBadRequest,
+			wantBody: Result{
+				Code: 400,
+				Msg:  "too many buckets, narrow the range or widen the interval",
+			},
+		},
+		{
+			name: "coin not found",
+			mock: func(ctrl *gomock.Controller) service.ScoreHistoryService {
+				svc := svcmocks.NewMockScoreHistoryService(ctrl)
+				svc.EXPECT().History(gomock.Any(), publicId, domain.ScoreIntervalHour, gomock.Any(), now).
+					Return(nil, service.ErrNotFound)
+				return svc
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/score-history",
+			wantCode: http.StatusNotFound,
+			wantBody: Result{
+				Code: 404,
+				Msg:  "coin not found",
+			},
+		},
+		{
+			name: "internal error",
+			mock: func(ctrl *gomock.Controller) service.ScoreHistoryService {
+				svc := svcmocks.NewMockScoreHistoryService(ctrl)
+				svc.EXPECT().History(gomock.Any(), publicId, domain.ScoreIntervalHour, gomock.Any(), now).
+					Return(nil, errors.New("mock error"))
+				return svc
+			},
+			url:      "/api/v1/meme-coins/" + publicId + "/score-history",
+			wantCode: http.StatusInternalServerError,
+			wantBody: Result{
+				Code: 500,
+				Msg:  "internal server error",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := NewScoreHistoryHandler(tc.mock(ctrl), logger.NewNopLogger())
+			hdl.now = func() time.Time {
+				return now
+			}
+
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			assert.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			bs, err := json.Marshal(tc.wantBody)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.wantCode, recorder.Code)
+			assert.Equal(t, string(bs), recorder.Body.String())
+		})
+	}
+}
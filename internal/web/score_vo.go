@@ -0,0 +1,12 @@
+package web
+
+type ScoreHistoryVo struct {
+	Interval string          `json:"interval"`
+	Buckets  []ScoreBucketVo `json:"buckets"`
+}
+
+type ScoreBucketVo struct {
+	// Start is the start of the bucket in RFC 3339, the bucket lasts one interval.
+	Start string `json:"start"`
+	Pokes int64  `json:"pokes"`
+}
@@ -0,0 +1,113 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/internal/repository/cache"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/miles0wu/meme-coin-api/pkg/breaker"
+	"github.com/miles0wu/meme-coin-api/pkg/hotreload"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+type cacheBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker.
+	FailureThreshold int `yaml:"failureThreshold"`
+	// CoolDown is how long redis is bypassed before a probe is let through.
+	CoolDown time.Duration `yaml:"coolDown"`
+}
+
+type cacheWarmupConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Async lets the server start accepting traffic while the cache is warming up.
+	Async     bool          `yaml:"async"`
+	TopN      int           `yaml:"topN"`
+	RecentN   int           `yaml:"recentN"`
+	BatchSize int           `yaml:"batchSize"`
+	Budget    time.Duration `yaml:"budget"`
+}
+
+type cacheConfig struct {
+	// TTL is the expiration of the coin cache entries, it is reloaded on change.
+	TTL     time.Duration      `yaml:"ttl"`
+	Breaker cacheBreakerConfig `yaml:"breaker"`
+	Warmup  cacheWarmupConfig  `yaml:"warmup"`
+}
+
+func defaultCacheConfig() cacheConfig {
+	return cacheConfig{
+		TTL: 15 * time.Minute,
+		Breaker: cacheBreakerConfig{
+			FailureThreshold: 5,
+			CoolDown:         30 * time.Second,
+		},
+		Warmup: cacheWarmupConfig{
+			TopN:      1000,
+			RecentN:   200,
+			BatchSize: 100,
+			Budget:    30 * time.Second,
+		},
+	}
+}
+
+func (c cacheConfig) validate() error {
+	if c.TTL <= 0 {
+		return errors.New("ttl must be positive")
+	}
+	if c.Breaker.FailureThreshold <= 0 || c.Breaker.CoolDown <= 0 {
+		return errors.New("breaker.failureThreshold and breaker.coolDown must be positive")
+	}
+	if c.Warmup.Enabled && c.Warmup.BatchSize <= 0 {
+		return errors.New("warmup.batchSize must be positive")
+	}
+	return nil
+}
+
+// InitRedisCoinCache returns the redis cache of the coins, its expiration follows cache.ttl.
+func InitRedisCoinCache(client redis.Cmdable, rc *hotreload.Reloader[RuntimeConfig],
+	reg prometheus.Registerer) *cache.RedisCoinCache {
+	redisCache := cache.NewRedisCoinCache(client, reg)
+	redisCache.SetExpiration(rc.Current().Cache.TTL)
+	rc.Subscribe(func(c RuntimeConfig) error {
+		redisCache.SetExpiration(c.Cache.TTL)
+		return nil
+	})
+	return redisCache
+}
+
+func InitCoinCache(redisCache *cache.RedisCoinCache, cfg *Config, l logger.Logger) *cache.BreakerCoinCache {
+	c := cfg.Cache.Breaker
+	b := breaker.NewBreaker(c.FailureThreshold, c.CoolDown,
+		breaker.WithOnStateChange(func(from, to breaker.State) {
+			l.Warn("coin cache circuit breaker state changed",
+				logger.String("from", from.String()),
+				logger.String("to", to.String()))
+		}))
+	return cache.NewBreakerCoinCache(redisCache, b, l)
+}
+
+// NewCacheWarmer returns the cache warmer without warming up, see InitCacheWarmer.
+func NewCacheWarmer(repo repository.CoinRepository, cfg *Config, l logger.Logger) service.CacheWarmer {
+	c := cfg.Cache.Warmup
+	return service.NewCacheWarmer(repo, service.WarmUpConfig{
+		TopN:      c.TopN,
+		RecentN:   c.RecentN,
+		BatchSize: c.BatchSize,
+		Budget:    c.Budget,
+	}, l)
+}
+
+// InitCacheWarmer returns the cache warmer and, unless disabled, warms the cache up before the server starts.
+// The asynchronous warm-up is a job instead, see InitJobs.
+func InitCacheWarmer(repo repository.CoinRepository, cfg *Config, l logger.Logger) service.CacheWarmer {
+	warmer := NewCacheWarmer(repo, cfg, l)
+	if c := cfg.Cache.Warmup; c.Enabled && !c.Async {
+		// a failed warm-up only costs cache misses, it must not prevent startup
+		_, _ = warmer.WarmUp(context.Background())
+	}
+	return warmer
+}
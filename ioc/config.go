@@ -0,0 +1,171 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/pkg/configx"
+	"github.com/miles0wu/meme-coin-api/pkg/hotreload"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+// EnvPrefix prefixes the environment variables overriding the config, e.g. MEMECOIN_DB_DSN for db.dsn.
+const EnvPrefix = "MEMECOIN"
+
+type reloadConfig struct {
+	// Watch applies the changes of the runtime settings, turn it off to only read them on startup.
+	Watch bool `yaml:"watch"`
+}
+
+// Config is the configuration of the app, read once on startup by LoadConfig.
+// The fields tagged redact:"true" are secrets, masked by config print --redact.
+type Config struct {
+	// File is the config file the settings were read from.
+	File string `yaml:"-"`
+
+	DB           dbConfig           `yaml:"db"`
+	IdGen        idgenConfig        `yaml:"idgen"`
+	Redis        redisConfig        `yaml:"redis"`
+	Cache        cacheConfig        `yaml:"cache"`
+	Server       serverConfig       `yaml:"server"`
+	Health       healthConfig       `yaml:"health"`
+	Admin        adminConfig        `yaml:"admin"`
+	Reactions    reactionsConfig    `yaml:"reactions"`
+	ScoreHistory scoreHistoryConfig `yaml:"scoreHistory"`
+	HotScore     hotScoreConfig     `yaml:"hotScore"`
+	Metrics      metricsConfig      `yaml:"metrics"`
+	Tracing      tracingConfig      `yaml:"tracing"`
+	Log          logConfig          `yaml:"log"`
+	Reload       reloadConfig       `yaml:"config"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		DB:           defaultDBConfig(),
+		Redis:        defaultRedisConfig(),
+		Cache:        defaultCacheConfig(),
+		Server:       defaultServerConfig(),
+		Health:       defaultHealthConfig(),
+		ScoreHistory: defaultScoreHistoryConfig(),
+		HotScore:     defaultHotScoreConfig(),
+		Metrics:      defaultMetricsConfig(),
+		Tracing:      defaultTracingConfig(),
+		Log:          defaultLogConfig(),
+		Reload:       reloadConfig{Watch: true},
+	}
+}
+
+// Validate reports the first invalid setting, named by its key.
+func (c *Config) Validate() error {
+	sections := []struct {
+		key      string
+		validate func() error
+	}{
+		{"db", c.DB.validate},
+		{"idgen", c.IdGen.validate},
+		{"redis", c.Redis.validate},
+		{"cache", c.Cache.validate},
+		{"server", func() error { return c.Server.validate(c.Cache.Warmup.Budget) }},
+		{"health", c.Health.validate},
+		{"scoreHistory", c.ScoreHistory.validate},
+		{"hotScore", c.HotScore.validate},
+		{"metrics", c.Metrics.validate},
+		{"tracing", c.Tracing.validate},
+		{"log", c.Log.validate},
+	}
+	for _, s := range sections {
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("invalid %s config: %w", s.key, err)
+		}
+	}
+	return nil
+}
+
+// LoadConfig reads the config file, overridden by the environment, see configx.BindEnv,
+// the settings missing from both keep their defaults.
+func LoadConfig(file string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	c := defaultConfig()
+	if err := configx.BindEnv(v, EnvPrefix, c); err != nil {
+		return nil, err
+	}
+	if err := configx.Unmarshal(v, &c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	c.File = file
+	return &c, nil
+}
+
+type runtimeLogConfig struct {
+	Level string `yaml:"level"`
+}
+
+type runtimeCacheConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// RuntimeConfig is the part of the config applied without a restart when the config file changes,
+// the other settings are only read on startup.
+type RuntimeConfig struct {
+	Log   runtimeLogConfig   `yaml:"log"`
+	Cache runtimeCacheConfig `yaml:"cache"`
+}
+
+func (c RuntimeConfig) Validate() error {
+	if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+		return fmt.Errorf("log.level: %w", err)
+	}
+	if c.Cache.TTL <= 0 {
+		return errors.New("cache.ttl must be positive")
+	}
+	return nil
+}
+
+// InitRuntimeConfig watches the config file, unless config.watch is off, see NewRuntimeConfig.
+func InitRuntimeConfig(level zap.AtomicLevel, cfg *Config, l logger.Logger) *hotreload.Reloader[RuntimeConfig] {
+	r := NewRuntimeConfig(level, cfg, l)
+	if cfg.Reload.Watch {
+		r.Watch()
+	}
+	return r
+}
+
+// NewRuntimeConfig returns the reloader of the runtime settings without watching the config file,
+// it applies the changes of the log level and the other components subscribe to it.
+func NewRuntimeConfig(level zap.AtomicLevel, cfg *Config, l logger.Logger) *hotreload.Reloader[RuntimeConfig] {
+	r, err := hotreload.New(cfg.File, RuntimeConfig{
+		Log:   runtimeLogConfig{Level: cfg.Log.Level},
+		Cache: runtimeCacheConfig{TTL: cfg.Cache.TTL},
+	}, l, hotreload.WithPrepare[RuntimeConfig](func(v *viper.Viper) error {
+		// the environment keeps overriding the file
+		return configx.BindEnv(v, EnvPrefix, RuntimeConfig{})
+	}))
+	if err != nil {
+		panic(fmt.Errorf("init runtime config failed %v", err))
+	}
+	// only a change of log.level is applied, a reload must not reset the level set through the admin api,
+	// the subscribers are called one at a time
+	applied := cfg.Log.Level
+	r.Subscribe(func(c RuntimeConfig) error {
+		if c.Log.Level == applied {
+			return nil
+		}
+		if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+			return err
+		}
+		applied = c.Log.Level
+		return nil
+	})
+	return r
+}
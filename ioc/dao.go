@@ -0,0 +1,86 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	"github.com/miles0wu/meme-coin-api/pkg/hotscore"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/miles0wu/meme-coin-api/pkg/snowflake"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	"hash/fnv"
+	"os"
+)
+
+// cliNodeId is the node of the admin commands, reserved so that they never generate
+// the ids of a server running beside them with the same config and hostname.
+const cliNodeId = snowflake.MaxNode
+
+type idgenConfig struct {
+	// NodeId defaults to a hash of the hostname, set it when the hashes of two instances may collide.
+	NodeId *int64 `yaml:"nodeId"`
+}
+
+func (c idgenConfig) validate() error {
+	if c.NodeId != nil && (*c.NodeId < 0 || *c.NodeId >= cliNodeId) {
+		return fmt.Errorf("nodeId must be between 0 and %d, %d is reserved for the admin commands",
+			cliNodeId-1, cliNodeId)
+	}
+	return nil
+}
+
+// InitIdGenerator returns the generator of the coin ids, its node must be unique
+// among the running instances.
+func InitIdGenerator(cfg *Config, l logger.Logger) *snowflake.Node {
+	c := cfg.IdGen
+	if c.NodeId == nil {
+		hostname, err := os.Hostname()
+		if err != nil {
+			panic(fmt.Errorf("init id generator failed %v", err))
+		}
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(hostname))
+		nodeId := int64(h.Sum32() % cliNodeId)
+		l.Warn("idgen.nodeId is not set, derived it from the hostname",
+			logger.String("hostname", hostname),
+			logger.Int64("node_id", nodeId))
+		c.NodeId = &nodeId
+	}
+	node, err := snowflake.NewNode(*c.NodeId)
+	if err != nil {
+		panic(fmt.Errorf("init id generator failed %v", err))
+	}
+	return node
+}
+
+// InitCLIIdGenerator returns the generator of the coin ids created by the admin commands, on the reserved node.
+// Two admin commands creating coins at once may still collide, they fail with dao.ErrDuplicateId.
+func InitCLIIdGenerator() *snowflake.Node {
+	node, err := snowflake.NewNode(cliNodeId)
+	if err != nil {
+		panic(fmt.Errorf("init id generator failed %v", err))
+	}
+	return node
+}
+
+func InitCoinDAO(shards []*gorm.DB, ids dao.IdGenerator, hot hotscore.Model,
+	reg prometheus.Registerer, l logger.Logger) dao.CoinDAO {
+	if len(shards) == 1 {
+		return dao.NewMetricsCoinDAO(dao.NewGormCoinDAO(shards[0], ids, hot, l), reg)
+	}
+	return dao.NewMetricsCoinDAO(dao.NewShardedCoinDAO(shards, ids, hot, l), reg)
+}
+
+func InitCoinAuditDAO(shards []*gorm.DB) dao.CoinAuditDAO {
+	if len(shards) == 1 {
+		return dao.NewGormCoinAuditDAO(shards[0])
+	}
+	return dao.NewShardedCoinAuditDAO(shards)
+}
+
+func InitOutboxDAO(shards []*gorm.DB) dao.OutboxDAO {
+	if len(shards) == 1 {
+		return dao.NewGormOutboxDAO(shards[0])
+	}
+	return dao.NewShardedOutboxDAO(shards)
+}
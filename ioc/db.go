@@ -1,39 +1,403 @@
 package ioc
 
 import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"expvar"
 	"fmt"
+	"github.com/glebarez/sqlite"
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao/migrations"
+	"github.com/miles0wu/meme-coin-api/pkg/health"
 	"github.com/miles0wu/meme-coin-api/pkg/logger"
-	"github.com/spf13/viper"
+	"github.com/miles0wu/meme-coin-api/pkg/migrator"
+	"github.com/miles0wu/meme-coin-api/pkg/readreplica"
+	"github.com/miles0wu/meme-coin-api/pkg/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	glogger "gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+	"gorm.io/plugin/opentelemetry/tracing"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func InitDB(l logger.Logger) *gorm.DB {
-	type Config struct {
-		DSN string `yaml:"dsn"`
+// InitDB opens the database and, unless disabled, applies pending migrations.
+func InitDB(c *Config, reg prometheus.Registerer, l logger.Logger) *gorm.DB {
+	db := OpenDB(c, l)
+	publishDBStats("db", db, reg)
+	migrateOnStartup(db, c, l)
+	return db
+}
+
+// InitShards opens the shards, see OpenShards, and applies their pending migrations unless disabled.
+func InitShards(db *gorm.DB, c *Config, reg prometheus.Registerer, l logger.Logger) []*gorm.DB {
+	shards := OpenShards(db, c, l)
+	for i, shard := range shards[1:] {
+		publishDBStats(fmt.Sprintf("db_shard_%d", i+1), shard, reg)
+		migrateOnStartup(shard, c, l)
+	}
+	return shards
+}
+
+func migrateOnStartup(db *gorm.DB, c *Config, l logger.Logger) {
+	if !c.DB.Migrate.OnStartup {
+		return
+	}
+	m := InitMigrator(db, c, l)
+	if _, err := m.Up(context.Background()); err != nil {
+		panic(fmt.Errorf("migrate db failed %v", err))
+	}
+}
+
+// backfillPublicIds is the migration step giving a public id to the coins created before public ids existed.
+func backfillPublicIds(l logger.Logger) migrator.Step {
+	return func(ctx context.Context, conn *gorm.DB) error {
+		n, err := dao.BackfillPublicIds(ctx, conn, func(createdAt time.Time) string {
+			return ulid.MustNewAt(createdAt, rand.Reader)
+		}, 100)
+		if err != nil {
+			return fmt.Errorf("backfill coin public ids: %w", err)
+		}
+		if n > 0 {
+			l.Info("backfilled coin public ids", logger.Int("count", n))
+		}
+		return nil
+	}
+}
+
+type dbPoolConfig struct {
+	MaxOpenConns int `yaml:"maxOpenConns"`
+	MaxIdleConns int `yaml:"maxIdleConns"`
+	// ConnMaxLifetime should stay below the idle timeout of any proxy in front of the database.
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"`
+}
+
+// dbTimeoutConfig is added to the DSN unless the DSN sets it already.
+// Postgres only supports the dial timeout, sqlite none of them.
+type dbTimeoutConfig struct {
+	Dial  time.Duration `yaml:"dial"`
+	Read  time.Duration `yaml:"read"`
+	Write time.Duration `yaml:"write"`
+}
+
+type dbShardConfig struct {
+	DSN string `yaml:"dsn" redact:"true"`
+}
+
+type dbReplicaConfig struct {
+	Name string `yaml:"name"`
+	DSN  string `yaml:"dsn" redact:"true"`
+}
+
+// dbReplicaCheckConfig configures the health and lag checks excluding replicas from reads.
+type dbReplicaCheckConfig struct {
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+	MaxLag   time.Duration `yaml:"maxLag"`
+}
+
+type dbMigrateConfig struct {
+	// OnStartup applies pending migrations before serving,
+	// disable it when migrations are run as a separate deploy step.
+	OnStartup bool `yaml:"onStartup"`
+	// LockTimeout is how long a replica waits for another one to finish migrating.
+	LockTimeout time.Duration `yaml:"lockTimeout"`
+}
+
+type dbConfig struct {
+	// Driver is one of mysql, postgres or sqlite, defaults to mysql.
+	Driver   string          `yaml:"driver"`
+	DSN      string          `yaml:"dsn" redact:"true"`
+	Pool     dbPoolConfig    `yaml:"pool"`
+	Timeouts dbTimeoutConfig `yaml:"timeouts"`
+	// Shards are the databases following the primary one, which is shard 0.
+	Shards       []dbShardConfig      `yaml:"shards"`
+	Replicas     []dbReplicaConfig    `yaml:"replicas"`
+	ReplicaCheck dbReplicaCheckConfig `yaml:"replicaCheck"`
+	Migrate      dbMigrateConfig      `yaml:"migrate"`
+}
+
+func defaultDBConfig() dbConfig {
+	return dbConfig{
+		Driver: "mysql",
+		Pool: dbPoolConfig{
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Timeouts: dbTimeoutConfig{
+			Dial:  5 * time.Second,
+			Read:  30 * time.Second,
+			Write: 30 * time.Second,
+		},
+		ReplicaCheck: dbReplicaCheckConfig{
+			Interval: 5 * time.Second,
+			Timeout:  time.Second,
+			MaxLag:   2 * time.Second,
+		},
+		Migrate: dbMigrateConfig{
+			OnStartup:   true,
+			LockTimeout: time.Minute,
+		},
+	}
+}
+
+func (c dbConfig) validate() error {
+	if _, ok := sqlDriverNames[c.Driver]; !ok {
+		return fmt.Errorf("unknown driver %q", c.Driver)
+	}
+	if c.DSN == "" {
+		return errors.New("dsn is required")
+	}
+	for i, shard := range c.Shards {
+		if shard.DSN == "" {
+			return fmt.Errorf("dsn of shard %d is required", i+1)
+		}
 	}
-	c := Config{}
-	err := viper.UnmarshalKey("db", &c)
+	for i, r := range c.Replicas {
+		if r.DSN == "" {
+			return fmt.Errorf("dsn of replica %d is required", i)
+		}
+	}
+	if len(c.Replicas) > 0 && (c.ReplicaCheck.Interval <= 0 || c.ReplicaCheck.Timeout <= 0) {
+		return errors.New("replicaCheck.interval and replicaCheck.timeout must be positive")
+	}
+	return nil
+}
+
+// OpenDB opens the database without touching its schema.
+func OpenDB(c *Config, l logger.Logger) *gorm.DB {
+	return openDB(c.DB, c.DB.DSN, l)
+}
+
+// OpenShards returns the databases the coins are sharded across, starting with db,
+// without touching their schemas. They all use the driver and settings of db.
+func OpenShards(db *gorm.DB, c *Config, l logger.Logger) []*gorm.DB {
+	shards := make([]*gorm.DB, 0, len(c.DB.Shards)+1)
+	shards = append(shards, db)
+	for _, shard := range c.DB.Shards {
+		shards = append(shards, openDB(c.DB, shard.DSN, l))
+	}
+	return shards
+}
+
+func openDB(c dbConfig, dsn string, l logger.Logger) *gorm.DB {
+	dsn, err := withDSNTimeouts(c.Driver, dsn, c.Timeouts)
 	if err != nil {
 		panic(fmt.Errorf("init db failed %v", err))
 	}
 
-	db, err := gorm.Open(mysql.Open(c.DSN), &gorm.Config{
+	var dialector gorm.Dialector
+	switch c.Driver {
+	case "mysql":
+		dialector = mysql.Open(dsn)
+	case "postgres":
+		dialector = postgres.Open(dsn)
+	case "sqlite":
+		dialector = sqlite.Open(dsn)
+	default:
+		panic(fmt.Errorf("init db failed, unknown driver %q", c.Driver))
+	}
+
+	db, err := gorm.Open(dialector, newGormConfig(l))
+	if err != nil {
+		panic(err)
+	}
+	// the query arguments, e.g. the reacting clients, are left out of the spans
+	err = db.Use(tracing.NewPlugin(tracing.WithoutMetrics(), tracing.WithoutQueryVariables()))
+	if err != nil {
+		panic(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+	configurePool(sqlDB, c.Pool)
+	return db
+}
+
+func configurePool(db *sql.DB, c dbPoolConfig) {
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+}
+
+// withDSNTimeouts adds the timeouts missing from dsn.
+func withDSNTimeouts(driver, dsn string, t dbTimeoutConfig) (string, error) {
+	switch driver {
+	case "mysql":
+		cfg, err := mysqlDriver.ParseDSN(dsn)
+		if err != nil {
+			return "", err
+		}
+		if cfg.Timeout == 0 {
+			cfg.Timeout = t.Dial
+		}
+		if cfg.ReadTimeout == 0 {
+			cfg.ReadTimeout = t.Read
+		}
+		if cfg.WriteTimeout == 0 {
+			cfg.WriteTimeout = t.Write
+		}
+		return cfg.FormatDSN(), nil
+	case "postgres":
+		if t.Dial <= 0 || strings.Contains(dsn, "connect_timeout") {
+			return dsn, nil
+		}
+		secs := strconv.Itoa(max(int(t.Dial.Seconds()), 1))
+		if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+			u, err := url.Parse(dsn)
+			if err != nil {
+				return "", err
+			}
+			q := u.Query()
+			q.Set("connect_timeout", secs)
+			u.RawQuery = q.Encode()
+			return u.String(), nil
+		}
+		return dsn + " connect_timeout=" + secs, nil
+	default:
+		return dsn, nil
+	}
+}
+
+// publishDBStats exposes the pool statistics of db in expvar and as the go_sql metrics labeled with name.
+func publishDBStats(name string, db *gorm.DB, reg prometheus.Registerer) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		return health.NewDBStats(sqlDB.Stats())
+	}))
+	reg.MustRegister(collectors.NewDBStatsCollector(sqlDB, name))
+}
+
+// InitReadReplicas routes the reads of db to the configured replicas,
+// it returns nil when there is none. The shards following db have no replicas.
+func InitReadReplicas(db *gorm.DB, cfg *Config, l logger.Logger) *readreplica.Policy {
+	c := cfg.DB
+	if len(c.Replicas) == 0 {
+		return nil
+	}
+
+	driver := db.Dialector.Name()
+	replicas := make([]readreplica.Replica, 0, len(c.Replicas))
+	dialectors := make([]gorm.Dialector, 0, len(c.Replicas)+1)
+	for i, r := range c.Replicas {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("replica-%d", i)
+		}
+		dsn, err := withDSNTimeouts(driver, r.DSN, c.Timeouts)
+		if err != nil {
+			panic(fmt.Errorf("open read replica %s failed %v", r.Name, err))
+		}
+		// sql.Open does not connect, an unreachable replica is excluded by the checks instead of failing the startup
+		sqlDB, err := sql.Open(sqlDriverNames[driver], dsn)
+		if err != nil {
+			panic(fmt.Errorf("open read replica %s failed %v", r.Name, err))
+		}
+		configurePool(sqlDB, c.Pool)
+		replicas = append(replicas, readreplica.Replica{Name: r.Name, DB: sqlDB})
+		dialectors = append(dialectors, newConnDialector(driver, sqlDB))
+	}
+	// the primary goes last, the policy falls back to it when no replica is healthy
+	primary, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+	dialectors = append(dialectors, newConnDialector(driver, primary))
+
+	var lag readreplica.LagFunc
+	switch driver {
+	case "mysql":
+		lag = readreplica.MySQLLag
+	case "postgres":
+		lag = readreplica.PostgresLag
+	}
+	policy := readreplica.NewPolicy(replicas, lag, readreplica.Config{
+		Interval: c.ReplicaCheck.Interval,
+		Timeout:  c.ReplicaCheck.Timeout,
+		MaxLag:   c.ReplicaCheck.MaxLag,
+	}, l)
+	// the checks following this first one run as a job, see InitJobs
+	policy.Refresh(context.Background())
+
+	// dbresolver opens the replicas with a copy of this config, do not ping them
+	db.Config.DisableAutomaticPing = true
+	err = db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   policy,
+	}))
+	if err != nil {
+		panic(fmt.Errorf("init read replicas failed %v", err))
+	}
+	return policy
+}
+
+// sqlDriverNames maps the gorm dialects to their database/sql drivers.
+var sqlDriverNames = map[string]string{
+	"mysql":    "mysql",
+	"postgres": "pgx",
+	"sqlite":   "sqlite",
+}
+
+// newConnDialector wraps an opened connection pool without querying it.
+func newConnDialector(driver string, conn gorm.ConnPool) gorm.Dialector {
+	switch driver {
+	case "mysql":
+		return mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true})
+	case "postgres":
+		return postgres.New(postgres.Config{Conn: conn})
+	default:
+		return &sqlite.Dialector{Conn: conn}
+	}
+}
+
+func newGormConfig(l logger.Logger) *gorm.Config {
+	return &gorm.Config{
 		Logger: glogger.New(gormLoggerFunc(l.Debug), glogger.Config{
 			SlowThreshold: 0,
 			LogLevel:      glogger.Info,
 		}),
-	})
+	}
+}
+
+// InitMigrator returns the migrator of db, its Up backfills the coin public ids after the migrations.
+func InitMigrator(db *gorm.DB, c *Config, l logger.Logger) *migrator.Migrator {
+	fsys, err := migrations.For(db.Dialector.Name())
 	if err != nil {
-		panic(err)
+		panic(fmt.Errorf("load migrations failed %v", err))
 	}
-	err = dao.InitTable(db)
+	ms, err := migrator.Load(fsys)
 	if err != nil {
-		panic(err)
+		panic(fmt.Errorf("load migrations failed %v", err))
 	}
-	return db
+	m := migrator.New(db, ms, l)
+	m.SetLockTimeout(c.DB.Migrate.LockTimeout)
+	m.AddStep(backfillPublicIds(l))
+	return m
+}
+
+// InitMigrators returns a migrator for each shard, in the order of the shards.
+func InitMigrators(shards []*gorm.DB, c *Config, l logger.Logger) []*migrator.Migrator {
+	res := make([]*migrator.Migrator, 0, len(shards))
+	for _, shard := range shards {
+		res = append(res, InitMigrator(shard, c, l))
+	}
+	return res
 }
 
 type gormLoggerFunc func(msg string, fields ...logger.Field)
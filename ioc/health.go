@@ -0,0 +1,71 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/repository/cache"
+	"github.com/miles0wu/meme-coin-api/internal/web"
+	"github.com/miles0wu/meme-coin-api/pkg/health"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/miles0wu/meme-coin-api/pkg/readreplica"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"time"
+)
+
+func InitHealthCheckers(shards []*gorm.DB, client redis.Cmdable, coinCache *cache.BreakerCoinCache,
+	replicas *readreplica.Policy, cfg *Config, l logger.Logger) []health.Checker {
+	checkers := make([]health.Checker, 0, 2*len(shards)+3)
+	for i, shard := range shards {
+		sqlDB, err := shard.DB()
+		if err != nil {
+			panic(err)
+		}
+		name, migrationsName := "db", "migrations"
+		if i > 0 {
+			name = fmt.Sprintf("db_shard_%d", i)
+			migrationsName = fmt.Sprintf("migrations_shard_%d", i)
+		}
+		checkers = append(checkers, health.NewDBChecker(name, sqlDB))
+		// a schema behind the code fails the queries, even with migrations run as a separate deploy step
+		checkers = append(checkers, health.NewPingChecker(migrationsName, health.StatusDown,
+			InitMigrator(shard, cfg, l).Verify))
+	}
+	// the reads fall back to the database without redis, it only degrades the service
+	checkers = append(checkers, health.NewPingChecker("redis_ping", health.StatusDegraded,
+		func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		}))
+	checkers = append(checkers, coinCache)
+	if replicas != nil {
+		checkers = append(checkers, replicas)
+	}
+	return checkers
+}
+
+type healthConfig struct {
+	// Timeout bounds the checks of a readiness probe.
+	Timeout time.Duration `yaml:"timeout"`
+	// DrainDelay is how long the server keeps serving after failing the readiness on shutdown,
+	// it should exceed the probe period of the load balancers.
+	DrainDelay time.Duration `yaml:"drainDelay"`
+}
+
+func defaultHealthConfig() healthConfig {
+	return healthConfig{
+		Timeout:    2 * time.Second,
+		DrainDelay: 5 * time.Second,
+	}
+}
+
+func (c healthConfig) validate() error {
+	if c.Timeout <= 0 || c.DrainDelay < 0 {
+		return errors.New("invalid timeout or drain delay")
+	}
+	return nil
+}
+
+func InitHealthHandler(checkers []health.Checker, cfg *Config) *web.HealthHandler {
+	return web.NewHealthHandler(checkers, cfg.Health.Timeout, cfg.Health.DrainDelay)
+}
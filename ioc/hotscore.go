@@ -0,0 +1,77 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/miles0wu/meme-coin-api/pkg/hotscore"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"time"
+)
+
+type hotScoreRefreshConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batchSize"`
+}
+
+type hotScoreConfig struct {
+	// Model is exponential or gravity, defaults to exponential.
+	Model string `yaml:"model"`
+	// HalfLife is the time a poke takes to count half, for the exponential model.
+	HalfLife time.Duration `yaml:"halfLife"`
+	// Gravity is the exponent of the age of the coin, for the gravity model.
+	Gravity float64               `yaml:"gravity"`
+	Refresh hotScoreRefreshConfig `yaml:"refresh"`
+}
+
+func defaultHotScoreConfig() hotScoreConfig {
+	return hotScoreConfig{
+		Model:    "exponential",
+		HalfLife: 24 * time.Hour,
+		Gravity:  1.8,
+		Refresh: hotScoreRefreshConfig{
+			Enabled:   true,
+			Interval:  time.Minute,
+			BatchSize: 500,
+		},
+	}
+}
+
+func (c hotScoreConfig) validate() error {
+	switch c.Model {
+	case "exponential":
+		if c.HalfLife <= 0 {
+			return fmt.Errorf("invalid half life %s", c.HalfLife)
+		}
+	case "gravity":
+		if c.Gravity <= 0 {
+			return fmt.Errorf("invalid gravity %v", c.Gravity)
+		}
+	default:
+		return fmt.Errorf("unknown model %s", c.Model)
+	}
+	if c.Refresh.Interval <= 0 || c.Refresh.BatchSize <= 0 {
+		return fmt.Errorf("invalid refresh interval %s or batch size %d", c.Refresh.Interval, c.Refresh.BatchSize)
+	}
+	return nil
+}
+
+// InitHotScoreModel returns the decay model of the hot score. Switching models takes effect
+// on the next poke or refresh of each coin.
+func InitHotScoreModel(cfg *Config) hotscore.Model {
+	c := cfg.HotScore
+	if c.Model == "gravity" {
+		return hotscore.NewGravity(c.Gravity)
+	}
+	return hotscore.NewExponential(c.HalfLife)
+}
+
+// InitHotScoreRefresher returns the refresher of the hot scores, it runs as a job unless disabled, see InitJobs.
+func InitHotScoreRefresher(repo repository.CoinRepository, cfg *Config, l logger.Logger) *service.HotScoreRefresher {
+	c := cfg.HotScore.Refresh
+	return service.NewHotScoreRefresher(repo, service.HotScoreRefresherConfig{
+		Interval:  c.Interval,
+		BatchSize: c.BatchSize,
+	}, l)
+}
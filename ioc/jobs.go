@@ -0,0 +1,36 @@
+package ioc
+
+import (
+	"context"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/miles0wu/meme-coin-api/pkg/readreplica"
+)
+
+// Job is work running in the background of the server, started once it serves
+// and stopped by cancelling ctx when it shuts down.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context)
+}
+
+// InitJobs returns the enabled background jobs, the providers of their services leave them unstarted.
+func InitJobs(warmer service.CacheWarmer, replicas *readreplica.Policy, compactor *service.ScoreCompactor,
+	refresher *service.HotScoreRefresher, reporter *service.PokeRateReporter, cfg *Config) []Job {
+	jobs := []Job{{Name: "poke rate reports", Run: reporter.Run}}
+	if cfg.HotScore.Refresh.Enabled {
+		jobs = append(jobs, Job{Name: "hot score refresh", Run: refresher.Run})
+	}
+	if cfg.ScoreHistory.Compaction.Enabled {
+		jobs = append(jobs, Job{Name: "score history compaction", Run: compactor.Run})
+	}
+	if replicas != nil {
+		jobs = append(jobs, Job{Name: "read replica checks", Run: replicas.Run})
+	}
+	if c := cfg.Cache.Warmup; c.Enabled && c.Async {
+		jobs = append(jobs, Job{Name: "cache warm-up", Run: func(ctx context.Context) {
+			// a failed warm-up only costs cache misses
+			_, _ = warmer.WarmUp(ctx)
+		}})
+	}
+	return jobs
+}
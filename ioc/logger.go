@@ -1,20 +1,122 @@
 package ioc
 
 import (
+	"fmt"
 	"github.com/miles0wu/meme-coin-api/pkg/logger"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"os"
+	"time"
 )
 
-func InitLogger() logger.Logger {
-	cfg := zap.NewDevelopmentConfig()
-	err := viper.UnmarshalKey("log", &cfg)
-	if err != nil {
-		panic(err)
+// accessLoggerName names the logger of the access log, which is not sampled.
+const accessLoggerName = "access"
+
+type logSamplingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Initial entries of the same level and message are logged every Tick, then one every Thereafter.
+	Tick       time.Duration `yaml:"tick"`
+	Initial    int           `yaml:"initial"`
+	Thereafter int           `yaml:"thereafter"`
+}
+
+type logFileConfig struct {
+	// Path is the file the entries are written to instead of stderr, when set.
+	Path       string `yaml:"path"`
+	MaxSizeMB  int    `yaml:"maxSizeMB"`
+	MaxBackups int    `yaml:"maxBackups"`
+	MaxAgeDays int    `yaml:"maxAgeDays"`
+	Compress   bool   `yaml:"compress"`
+}
+
+type logConfig struct {
+	// Mode is production, logging json, or development, logging colored console lines with stack traces from warnings.
+	Mode     string            `yaml:"mode"`
+	Level    string            `yaml:"level"`
+	Sampling logSamplingConfig `yaml:"sampling"`
+	File     logFileConfig     `yaml:"file"`
+}
+
+func defaultLogConfig() logConfig {
+	return logConfig{
+		Mode:  "production",
+		Level: "info",
+		Sampling: logSamplingConfig{
+			Enabled:    true,
+			Tick:       time.Second,
+			Initial:    100,
+			Thereafter: 100,
+		},
+		File: logFileConfig{
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+			MaxAgeDays: 30,
+			Compress:   true,
+		},
+	}
+}
+
+func (c logConfig) validate() error {
+	if c.Mode != "production" && c.Mode != "development" {
+		return fmt.Errorf("unknown mode %q", c.Mode)
 	}
-	l, err := cfg.Build()
+	if _, err := zapcore.ParseLevel(c.Level); err != nil {
+		return err
+	}
+	if c.Sampling.Enabled && (c.Sampling.Tick <= 0 || c.Sampling.Initial <= 0 || c.Sampling.Thereafter <= 0) {
+		return fmt.Errorf("invalid sampling %+v", c.Sampling)
+	}
+	return nil
+}
+
+// NewZapLogger builds the logger of the process from the log config, its level can be changed through the
+// returned one at runtime. Build it once, the loggers of the commands are derived from it.
+func NewZapLogger(cfg *Config) (*zap.Logger, zap.AtomicLevel) {
+	c := cfg.Log
+	level, err := zap.ParseAtomicLevel(c.Level)
 	if err != nil {
-		panic(err)
+		panic(fmt.Errorf("init logger failed %v", err))
+	}
+
+	var encoder zapcore.Encoder
+	opts := []zap.Option{zap.AddCaller()}
+	switch c.Mode {
+	case "production":
+		encCfg := zap.NewProductionEncoderConfig()
+		encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewJSONEncoder(encCfg)
+		opts = append(opts, zap.AddStacktrace(zapcore.ErrorLevel))
+	case "development":
+		encCfg := zap.NewDevelopmentEncoderConfig()
+		encCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encCfg)
+		opts = append(opts, zap.AddStacktrace(zapcore.WarnLevel), zap.Development())
+	default:
+		panic(fmt.Errorf("init logger failed, unknown mode %q", c.Mode))
 	}
-	return logger.NewZapLogger(l)
+
+	out := zapcore.Lock(os.Stderr)
+	if c.File.Path != "" {
+		// lumberjack rotates the file once it reaches MaxSizeMB
+		out = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   c.File.Path,
+			MaxSize:    c.File.MaxSizeMB,
+			MaxBackups: c.File.MaxBackups,
+			MaxAge:     c.File.MaxAgeDays,
+			Compress:   c.File.Compress,
+		})
+	}
+	core := zapcore.NewCore(encoder, out, level)
+	if c.Sampling.Enabled {
+		// a burst of the same failure, e.g. of the pokes while the database is down, does not flood the logs,
+		// the access log records every request
+		core = logger.NewSampler(core, c.Sampling.Tick, c.Sampling.Initial, c.Sampling.Thereafter, accessLoggerName)
+	}
+	return zap.New(core, opts...), level
+}
+
+func InitLogger(l *zap.Logger) logger.Logger {
+	// report the callers of the wrapper rather than the wrapper
+	return logger.NewZapLogger(l.WithOptions(zap.AddCallerSkip(1)))
 }
@@ -0,0 +1,78 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+// InitMetricsRegistry returns the registry of all the metrics, starting with the go runtime and process ones.
+func InitMetricsRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
+}
+
+type pokeRateConfig struct {
+	TopK     int           `yaml:"topK"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+type metricsConfig struct {
+	Enabled  bool           `yaml:"enabled"`
+	Addr     string         `yaml:"addr"`
+	PokeRate pokeRateConfig `yaml:"pokeRate"`
+}
+
+func defaultMetricsConfig() metricsConfig {
+	return metricsConfig{
+		Enabled: true,
+		Addr:    ":9090",
+		PokeRate: pokeRateConfig{
+			TopK:     20,
+			Interval: time.Minute,
+		},
+	}
+}
+
+func (c metricsConfig) validate() error {
+	if c.Enabled && c.Addr == "" {
+		return errors.New("addr is required")
+	}
+	if c.PokeRate.TopK <= 0 || c.PokeRate.Interval <= 0 {
+		return fmt.Errorf("invalid pokeRate top k %d or interval %s", c.PokeRate.TopK, c.PokeRate.Interval)
+	}
+	return nil
+}
+
+// InitMetricsServer returns the server of /metrics, or nil when metrics.enabled is false.
+// It listens apart from the api so that the metrics are not exposed with it.
+func InitMetricsServer(reg *prometheus.Registry, cfg *Config) *http.Server {
+	c := cfg.Metrics
+	if !c.Enabled {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+	return &http.Server{
+		Addr:    c.Addr,
+		Handler: mux,
+	}
+}
+
+// InitPokeRateReporter returns the reporter of the poke rates, its reports run as a job, see InitJobs.
+func InitPokeRateReporter(reg prometheus.Registerer, cfg *Config) *service.PokeRateReporter {
+	c := cfg.Metrics.PokeRate
+	return service.NewPokeRateReporter(service.PokeRateConfig{
+		TopK:     c.TopK,
+		Interval: c.Interval,
+	}, reg)
+}
@@ -1,22 +1,196 @@
 package ioc
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
-	"github.com/spf13/viper"
+	"os"
+	"time"
 )
 
-func InitRedis() redis.Cmdable {
-	type Config struct {
-		Addr string `yaml:"addr"`
+const (
+	redisModeStandalone = "standalone"
+	redisModeSentinel   = "sentinel"
+	redisModeCluster    = "cluster"
+)
+
+type redisTLSConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// CAFile is a PEM bundle used to verify the server certificate,
+	// the system pool is used when empty.
+	CAFile string `yaml:"caFile"`
+	// CertFile and KeyFile enable mutual TLS when both are set.
+	CertFile           string `yaml:"certFile"`
+	KeyFile            string `yaml:"keyFile"`
+	ServerName         string `yaml:"serverName"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+type redisConfig struct {
+	// Mode is one of standalone, sentinel or cluster, defaults to standalone.
+	Mode string `yaml:"mode"`
+	// Addr is the server address in standalone mode.
+	Addr string `yaml:"addr"`
+	// Addrs are the sentinel addresses in sentinel mode
+	// or the seed nodes in cluster mode.
+	Addrs            []string `yaml:"addrs"`
+	MasterName       string   `yaml:"masterName"`
+	SentinelUsername string   `yaml:"sentinelUsername"`
+	SentinelPassword string   `yaml:"sentinelPassword" redact:"true"`
+
+	Username string `yaml:"username"`
+	Password string `yaml:"password" redact:"true"`
+	DB       int    `yaml:"db"`
+
+	PoolSize     int           `yaml:"poolSize"`
+	DialTimeout  time.Duration `yaml:"dialTimeout"`
+	ReadTimeout  time.Duration `yaml:"readTimeout"`
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	PingTimeout  time.Duration `yaml:"pingTimeout"`
+
+	TLS redisTLSConfig `yaml:"tls"`
+}
+
+func defaultRedisConfig() redisConfig {
+	return redisConfig{
+		Mode:        redisModeStandalone,
+		PingTimeout: 5 * time.Second,
 	}
-	var c Config
-	err := viper.UnmarshalKey("redis", &c)
+}
+
+func (c redisConfig) validate() error {
+	switch c.Mode {
+	case "", redisModeStandalone:
+		if c.Addr == "" {
+			return errors.New("addr is required in standalone mode")
+		}
+	case redisModeSentinel:
+		if c.MasterName == "" || len(c.Addrs) == 0 {
+			return errors.New("masterName and addrs are required in sentinel mode")
+		}
+	case redisModeCluster:
+		if len(c.Addrs) == 0 {
+			return errors.New("addrs is required in cluster mode")
+		}
+		if c.DB != 0 {
+			return errors.New("db is not supported in cluster mode")
+		}
+	default:
+		return fmt.Errorf("unknown mode %q", c.Mode)
+	}
+	if c.TLS.Enabled && (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("tls.certFile and tls.keyFile must be set together")
+	}
+	return nil
+}
+
+func InitRedis(cfg *Config) redis.Cmdable {
+	c := cfg.Redis
+	client, err := newRedisClient(c)
 	if err != nil {
 		panic(fmt.Errorf("init redis failed %v", err))
 	}
 
-	return redis.NewClient(&redis.Options{
-		Addr: c.Addr,
-	})
+	// fail fast, a misconfigured cache should not surface as timeouts on the first requests
+	ctx, cancel := context.WithTimeout(context.Background(), c.PingTimeout)
+	defer cancel()
+	if err = client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		panic(fmt.Errorf("init redis failed, ping %s %v: %v", c.Mode, c.addrs(), err))
+	}
+	if err = redisotel.InstrumentTracing(client); err != nil {
+		panic(fmt.Errorf("init redis failed %v", err))
+	}
+	return client
+}
+
+func newRedisClient(c redisConfig) (redis.UniversalClient, error) {
+	tlsCfg, err := c.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Mode {
+	case "", redisModeStandalone:
+		return redis.NewClient(&redis.Options{
+			Addr:         c.Addr,
+			Username:     c.Username,
+			Password:     c.Password,
+			DB:           c.DB,
+			PoolSize:     c.PoolSize,
+			DialTimeout:  c.DialTimeout,
+			ReadTimeout:  c.ReadTimeout,
+			WriteTimeout: c.WriteTimeout,
+			TLSConfig:    tlsCfg,
+		}), nil
+	case redisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       c.MasterName,
+			SentinelAddrs:    c.Addrs,
+			SentinelUsername: c.SentinelUsername,
+			SentinelPassword: c.SentinelPassword,
+			Username:         c.Username,
+			Password:         c.Password,
+			DB:               c.DB,
+			PoolSize:         c.PoolSize,
+			DialTimeout:      c.DialTimeout,
+			ReadTimeout:      c.ReadTimeout,
+			WriteTimeout:     c.WriteTimeout,
+			TLSConfig:        tlsCfg,
+		}), nil
+	case redisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        c.Addrs,
+			Username:     c.Username,
+			Password:     c.Password,
+			PoolSize:     c.PoolSize,
+			DialTimeout:  c.DialTimeout,
+			ReadTimeout:  c.ReadTimeout,
+			WriteTimeout: c.WriteTimeout,
+			TLSConfig:    tlsCfg,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", c.Mode)
+	}
+}
+
+func (c redisConfig) addrs() []string {
+	if c.Mode == redisModeSentinel || c.Mode == redisModeCluster {
+		return c.Addrs
+	}
+	return []string{c.Addr}
+}
+
+func (c redisTLSConfig) build() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read redis ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in redis ca file %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load redis client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
 }
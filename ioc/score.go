@@ -0,0 +1,80 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"gorm.io/gorm"
+	"time"
+)
+
+func InitPokeRollupDAO(shards []*gorm.DB) dao.PokeRollupDAO {
+	if len(shards) == 1 {
+		return dao.NewGormPokeRollupDAO(shards[0])
+	}
+	return dao.NewShardedPokeRollupDAO(shards)
+}
+
+type scoreRetentionConfig struct {
+	Minute time.Duration `yaml:"minute"`
+	Hour   time.Duration `yaml:"hour"`
+	Day    time.Duration `yaml:"day"`
+}
+
+type scoreCompactionConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"`
+	// Lookback is how far back each compaction recomputes the hour and day buckets.
+	Lookback time.Duration `yaml:"lookback"`
+	// Retention of the buckets of each interval, 0 keeps them forever.
+	Retention scoreRetentionConfig `yaml:"retention"`
+}
+
+type scoreHistoryConfig struct {
+	Compaction scoreCompactionConfig `yaml:"compaction"`
+}
+
+func defaultScoreHistoryConfig() scoreHistoryConfig {
+	return scoreHistoryConfig{
+		Compaction: scoreCompactionConfig{
+			Enabled:  true,
+			Interval: time.Minute,
+			Lookback: 3 * time.Hour,
+			Retention: scoreRetentionConfig{
+				Minute: 48 * time.Hour,
+				Hour:   90 * 24 * time.Hour,
+			},
+		},
+	}
+}
+
+func (c scoreHistoryConfig) validate() error {
+	cc := c.Compaction
+	if cc.Interval <= 0 || cc.Lookback <= cc.Interval {
+		return fmt.Errorf("compaction.lookback %s must exceed the interval %s", cc.Lookback, cc.Interval)
+	}
+	// a compaction recomputes whole hours and days from the finer buckets, they must still be there
+	if cc.Retention.Minute > 0 && cc.Retention.Minute < cc.Lookback+time.Hour {
+		return fmt.Errorf("compaction.retention.minute %s must exceed lookback by an hour", cc.Retention.Minute)
+	}
+	if cc.Retention.Hour > 0 && cc.Retention.Hour < cc.Lookback+24*time.Hour {
+		return fmt.Errorf("compaction.retention.hour %s must exceed lookback by a day", cc.Retention.Hour)
+	}
+	return nil
+}
+
+// InitScoreCompactor returns the compactor of the score history, it runs as a job unless disabled, see InitJobs.
+func InitScoreCompactor(repo repository.ScoreHistoryRepository, cfg *Config, l logger.Logger) *service.ScoreCompactor {
+	c := cfg.ScoreHistory.Compaction
+	return service.NewScoreCompactor(repo, service.ScoreCompactorConfig{
+		Interval: c.Interval,
+		Lookback: c.Lookback,
+		Retention: service.ScoreRetention{
+			Minute: c.Retention.Minute,
+			Hour:   c.Retention.Hour,
+			Day:    c.Retention.Day,
+		},
+	}, l)
+}
@@ -0,0 +1,91 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/pkg/tlsx"
+	"net/http"
+	"time"
+)
+
+type serverTLSConfig struct {
+	// CertFile and KeyFile enable TLS when both are set, the pair is reloaded on SIGHUP.
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+type serverConfig struct {
+	Addr string `yaml:"addr"`
+	// ReadHeaderTimeout bounds the slow clients holding a connection before sending a request.
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	MaxHeaderBytes    int           `yaml:"maxHeaderBytes"`
+	// ShutdownTimeout is how long the requests in flight may take to complete on shutdown.
+	ShutdownTimeout time.Duration   `yaml:"shutdownTimeout"`
+	TLS             serverTLSConfig `yaml:"tls"`
+}
+
+func defaultServerConfig() serverConfig {
+	return serverConfig{
+		Addr:              ":8080",
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+		ShutdownTimeout:   5 * time.Second,
+	}
+}
+
+// validate checks the settings, warmupBudget is the longest a cache warm-up of the admin api may take to answer.
+func (c serverConfig) validate(warmupBudget time.Duration) error {
+	if c.Addr == "" {
+		return errors.New("addr is required")
+	}
+	if c.ReadHeaderTimeout < 0 || c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 ||
+		c.MaxHeaderBytes <= 0 || c.ShutdownTimeout <= 0 {
+		return errors.New("invalid timeouts or max header bytes")
+	}
+	// the response of a warm-up written past the deadline would be dropped along with the connection
+	if c.WriteTimeout > 0 && (warmupBudget <= 0 || c.WriteTimeout <= warmupBudget) {
+		return fmt.Errorf("writeTimeout %s must exceed cache.warmup.budget %s, or be 0", c.WriteTimeout, warmupBudget)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("tls.certFile and tls.keyFile must be set together")
+	}
+	return nil
+}
+
+// InitCertReloader returns the certificate of the server, or nil when TLS is disabled.
+func InitCertReloader(cfg *Config) *tlsx.CertReloader {
+	c := cfg.Server
+	if c.TLS.CertFile == "" {
+		return nil
+	}
+	certs, err := tlsx.NewCertReloader(c.TLS.CertFile, c.TLS.KeyFile)
+	if err != nil {
+		panic(fmt.Errorf("init server failed %v", err))
+	}
+	return certs
+}
+
+// InitServer returns the api server, serving TLS when certs is not nil.
+func InitServer(engine *gin.Engine, certs *tlsx.CertReloader, cfg *Config) *http.Server {
+	c := cfg.Server
+	srv := &http.Server{
+		Addr:              c.Addr,
+		Handler:           engine,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		ReadTimeout:       c.ReadTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		MaxHeaderBytes:    c.MaxHeaderBytes,
+	}
+	if certs != nil {
+		srv.TLSConfig = certs.TLSConfig()
+	}
+	return srv
+}
@@ -0,0 +1,12 @@
+package ioc
+
+import (
+	"github.com/miles0wu/meme-coin-api/internal/repository"
+	"github.com/miles0wu/meme-coin-api/internal/service"
+)
+
+func InitCoinService(repo repository.CoinRepository, audits repository.CoinAuditRepository,
+	outbox repository.OutboxRepository, tx repository.Transactor, pokes *service.PokeRateReporter) service.CoinService {
+	svc := service.NewTracedCoinService(service.NewCoinService(repo, audits, outbox, tx))
+	return service.NewPokeRateCoinService(svc, pokes)
+}
@@ -0,0 +1,99 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// serviceName names the spans of the api and the service of its traces.
+const serviceName = "meme-coin-api"
+
+type otlpConfig struct {
+	// Endpoint is the host and port of the OTLP/HTTP collector.
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+}
+
+type tracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Exporter is otlp, or stdout for local use.
+	Exporter string `yaml:"exporter"`
+	// SampleRatio is the share of the traces started here that are recorded,
+	// the traces started by a caller follow its decision.
+	SampleRatio float64    `yaml:"sampleRatio"`
+	OTLP        otlpConfig `yaml:"otlp"`
+}
+
+func defaultTracingConfig() tracingConfig {
+	return tracingConfig{
+		Exporter:    "otlp",
+		SampleRatio: 1,
+		OTLP: otlpConfig{
+			Endpoint: "localhost:4318",
+		},
+	}
+}
+
+func (c tracingConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("invalid sample ratio %v", c.SampleRatio)
+	}
+	if c.Exporter != "otlp" && c.Exporter != "stdout" {
+		return fmt.Errorf("unknown exporter %q", c.Exporter)
+	}
+	return nil
+}
+
+// InitTracerProvider sets the global tracer provider, used by the gin, gorm and redis instrumentations,
+// and the W3C trace context propagator. Spans are only recorded when tracing.enabled is true,
+// shut the provider down on exit to flush them.
+func InitTracerProvider(cfg *Config) *sdktrace.TracerProvider {
+	c := cfg.Tracing
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	if !c.Enabled {
+		// the global provider stays a no-op, the trace context of the callers is still propagated
+		return sdktrace.NewTracerProvider()
+	}
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch c.Exporter {
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(c.OTLP.Endpoint)}
+		if c.OTLP.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		err = fmt.Errorf("unknown exporter %q", c.Exporter)
+	}
+	if err != nil {
+		panic(fmt.Errorf("init tracer provider failed %v", err))
+	}
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		panic(fmt.Errorf("init tracer provider failed %v", err))
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp
+}
@@ -4,31 +4,77 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	_ "github.com/miles0wu/meme-coin-api/api/docs"
+	"github.com/miles0wu/meme-coin-api/internal/service"
 	"github.com/miles0wu/meme-coin-api/internal/web"
+	"github.com/miles0wu/meme-coin-api/pkg/ginx"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/miles0wu/meme-coin-api/pkg/readreplica"
+	"github.com/prometheus/client_golang/prometheus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.uber.org/zap"
 	"strings"
 	"time"
 )
 
-func InitWebServer(mdls []gin.HandlerFunc, coinHdl *web.CoinHandler) *gin.Engine {
-	server := gin.Default()
+func InitWebServer(mdls []gin.HandlerFunc, coinHdl *web.CoinHandler, scoreHdl *web.ScoreHistoryHandler,
+	healthHdl *web.HealthHandler, adminHdl *web.AdminHandler) *gin.Engine {
+	// the access log of InitGinMiddlewares replaces the plain text logger of gin.Default
+	server := gin.New()
+	// let the request context, carrying the read replica session, back gin.Context.Value
+	server.ContextWithFallback = true
 	server.Use(mdls...)
 
 	coinHdl.RegisterRoutes(server)
+	scoreHdl.RegisterRoutes(server)
+	healthHdl.RegisterRoutes(server)
+	adminHdl.RegisterRoutes(server)
 	server.GET("/api/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	return server
 }
 
-func InitGinMiddlewares() []gin.HandlerFunc {
+type reactionsConfig struct {
+	// ClientIdSecret signs the client ids trusted to identify who reacts, see web.SignClientId.
+	// The clients are fingerprinted when empty.
+	ClientIdSecret string `yaml:"clientIdSecret" redact:"true"`
+}
+
+func InitCoinHandler(svc service.CoinService, cfg *Config, l logger.Logger) *web.CoinHandler {
+	return web.NewCoinHandler(svc, l, web.WithClientIdSecret(cfg.Reactions.ClientIdSecret))
+}
+
+type adminConfig struct {
+	// Token is the bearer token of the admin api, the api is disabled when empty.
+	Token string `yaml:"token" redact:"true"`
+}
+
+func InitAdminHandler(warmer service.CacheWarmer, level zap.AtomicLevel, cfg *Config, l logger.Logger) *web.AdminHandler {
+	return web.NewAdminHandler(warmer, &level, cfg.Admin.Token, l)
+}
+
+func InitGinMiddlewares(reg prometheus.Registerer, l *zap.Logger) []gin.HandlerFunc {
 	return []gin.HandlerFunc{
+		// the spans of the request, continuing the trace of the caller if any, cover the other middlewares
+		otelgin.Middleware(serviceName),
+		ginx.RequestId(),
+		ginx.AccessLog(InitLogger(l.Named(accessLoggerName))),
+		ginx.Metrics(reg, "meme_coin"),
+		// recover inside the access log and the metrics so that a panic is logged and counted as a 500
+		gin.Recovery(),
 		cors.New(cors.Config{
 			AllowCredentials: true,
-			AllowHeaders:     []string{"Content-Type"},
+			AllowHeaders:     []string{"Content-Type", web.ClientIdHeader, ginx.RequestIdHeader},
+			ExposeHeaders:    []string{ginx.RequestIdHeader},
 			AllowOriginFunc: func(origin string) bool {
 				return strings.HasPrefix(origin, "http://localhost")
 			},
 			MaxAge: 12 * time.Hour,
 		}),
+		func(ctx *gin.Context) {
+			// reads following a write of the same request go to the primary
+			ctx.Request = ctx.Request.WithContext(readreplica.NewSession(ctx.Request.Context()))
+			ctx.Next()
+		},
 	}
 }
@@ -3,15 +3,20 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/internal/web"
+	"github.com/miles0wu/meme-coin-api/ioc"
+	"github.com/miles0wu/meme-coin-api/pkg/tlsx"
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
-	"time"
 )
 
 // App
@@ -26,60 +31,194 @@ import (
 // @license.url https://spdx.org/licenses/GPL-3.0-only.html
 //
 // @BasePath /
+//
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 type App struct {
+	cfg    *ioc.Config
 	server *gin.Engine
+	// certs is the certificate of the server reloaded on SIGHUP, it is nil without TLS
+	certs *tlsx.CertReloader
+	// health fails the readiness while the server drains on shutdown
+	health *web.HealthHandler
+	// metricsServer serves /metrics on its own address, it is nil when disabled
+	metricsServer *http.Server
+	// tracerProvider is shut down on exit to flush the spans
+	tracerProvider *sdktrace.TracerProvider
+	// jobs run in the background while the server serves
+	jobs []ioc.Job
 }
 
 func main() {
-	initViper()
-	initLogger()
-	app := InitApp()
-	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: app.server,
+	cfg := initConfig()
+	level := initLogger(cfg)
+	// the admin commands connect once their arguments are parsed
+	build := func() *CLI {
+		return InitCLI(zap.L(), level, cfg)
+	}
+	args := pflag.Args()
+	if len(args) == 0 {
+		// serving is the default for the deployments started without a command
+		args = []string{"serve"}
 	}
+	var err error
+	switch args[0] {
+	case "serve":
+		app := InitApp(zap.L(), level, cfg)
+		os.Exit(run(app, ioc.InitServer(app.server, app.certs, cfg)))
+	case "migrate":
+		err = runMigrate(cfg, args[1:])
+	case "config":
+		err = runConfig(cfg, args[1:])
+	case "coins":
+		err = runCoins(build, args[1:])
+	case "cache":
+		err = runCache(build, args[1:])
+	case "score":
+		err = runScore(build, args[1:])
+	default:
+		zap.L().Fatal("unknown command", zap.String("command", args[0]),
+			zap.String("usage", "serve | migrate | config | coins | cache | score"))
+	}
+	if err != nil {
+		zap.L().Fatal(args[0]+" failed", zap.Error(err))
+	}
+}
 
-	// start server
+// run serves until SIGINT or SIGTERM and returns the exit code, non-zero when a server failed.
+func run(app *App, srv *http.Server) int {
+	// listen before logging the start, so that a bind failure is reported as a startup failure
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		zap.L().Error("Server failed to start", zap.String("addr", srv.Addr), zap.Error(err))
+		return 1
+	}
+	errs := make(chan error, 2)
 	go func() {
-		zap.L().Info("Server starting", zap.String("addr", srv.Addr))
-		if err := srv.ListenAndServe(); err != nil && errors.Is(err, http.ErrServerClosed) {
-			zap.L().Fatal("listen: ", zap.Error(err))
+		zap.L().Info("Server starting",
+			zap.String("addr", srv.Addr),
+			zap.Bool("tls", srv.TLSConfig != nil))
+		var err error
+		if srv.TLSConfig != nil {
+			// the certificate is served by the TLS config
+			err = srv.ServeTLS(ln, "", "")
+		} else {
+			err = srv.Serve(ln)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("server: %w", err)
 		}
 	}()
+	if app.metricsServer != nil {
+		go func() {
+			zap.L().Info("Metrics server starting", zap.String("addr", app.metricsServer.Addr))
+			if err := app.metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("metrics server: %w", err)
+			}
+		}()
+	}
 
-	// listening signal for graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	zap.L().Info("Shutting down server...")
+	stopJobs := startJobs(app.jobs)
+
+	// listening signal for graceful shutdown, and SIGHUP to reload the certificate
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	code := 0
+wait:
+	for {
+		select {
+		case sig := <-signals:
+			if sig != syscall.SIGHUP {
+				break wait
+			}
+			if app.certs == nil {
+				continue
+			}
+			if err := app.certs.Reload(); err != nil {
+				zap.L().Error("failed to reload the certificate, still serving the previous one", zap.Error(err))
+				continue
+			}
+			zap.L().Info("Reloaded the certificate")
+		case err := <-errs:
+			zap.L().Error("Server failed", zap.Error(err))
+			code = 1
+			break wait
+		}
+	}
 
-	// shutdown timeout 5 secs
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if code == 0 {
+		zap.L().Info("Draining server...")
+		app.health.Drain(context.Background())
+	}
+	zap.L().Info("Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), app.cfg.Server.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		zap.L().Fatal("Server forced to shutdown", zap.Error(err))
+		zap.L().Error("Server forced to shutdown", zap.Error(err))
+		code = 1
+	}
+	if app.metricsServer != nil {
+		_ = app.metricsServer.Shutdown(ctx)
+	}
+	// the jobs stop once no request is left, they may be writing what the requests read
+	if err := stopJobs(ctx); err != nil {
+		zap.L().Error("background jobs did not stop in time", zap.Error(err))
+	}
+	if err := app.tracerProvider.Shutdown(ctx); err != nil {
+		zap.L().Error("failed to flush spans", zap.Error(err))
 	}
 
 	zap.L().Info("Server exiting")
+	_ = zap.L().Sync()
+	return code
+}
+
+// startJobs runs the jobs in the background, the returned function stops them
+// and waits for them until ctx is done.
+func startJobs(jobs []ioc.Job) func(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			zap.L().Info("Background job starting", zap.String("job", job.Name))
+			job.Run(ctx)
+		}()
+	}
+	return func(waitCtx context.Context) error {
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-waitCtx.Done():
+			return waitCtx.Err()
+		}
+	}
 }
 
-func initViper() {
+// initConfig loads the config file named by --config, the flags following the command are left to it.
+func initConfig() *ioc.Config {
 	cfile := pflag.String("config", "config/config.yaml", "config file")
+	pflag.CommandLine.SetInterspersed(false)
 	pflag.Parse()
 
-	viper.SetConfigType("yaml")
-	viper.SetConfigFile(*cfile)
-
-	err := viper.ReadInConfig()
+	cfg, err := ioc.LoadConfig(*cfile)
 	if err != nil {
 		panic(err)
 	}
+	return cfg
 }
 
-func initLogger() {
-	logger, err := zap.NewDevelopment()
-	if err != nil {
-		panic(err)
-	}
-	zap.ReplaceGlobals(logger)
+// initLogger builds the logger from the log config, see ioc.NewZapLogger, and returns its level.
+func initLogger(cfg *ioc.Config) zap.AtomicLevel {
+	l, level := ioc.NewZapLogger(cfg)
+	zap.ReplaceGlobals(l)
+	return level
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/ioc"
+	"github.com/miles0wu/meme-coin-api/pkg/migrator"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate implements the migrate subcommand, it runs on every shard in turn.
+func runMigrate(cfg *ioc.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	ms := InitMigrators(zap.L(), cfg)
+	for i, m := range ms {
+		if len(ms) > 1 {
+			fmt.Printf("shard %d:\n", i)
+		}
+		if err := migrate(m, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrate(m *migrator.Migrator, args []string) error {
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mig := range done {
+			fmt.Printf("applied %d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid steps %q, %s", args[1], migrateUsage)
+			}
+			steps = n
+		}
+		done, err := m.Down(ctx, steps)
+		for _, mig := range done {
+			fmt.Printf("rolled back %d_%s\n", mig.Version, mig.Name)
+		}
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			status, appliedAt := "pending", ""
+			switch {
+			case s.Dirty:
+				status = "dirty"
+			case s.Applied:
+				status, appliedAt = "applied", s.AppliedAt.Format(time.DateTime)
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q, %s", args[0], migrateUsage)
+	}
+}
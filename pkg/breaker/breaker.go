@@ -0,0 +1,155 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Option func(b *Breaker)
+
+// WithOnStateChange registers a callback invoked after every state transition,
+// it is called outside the breaker lock.
+func WithOnStateChange(fn func(from, to State)) Option {
+	return func(b *Breaker) {
+		b.onStateChange = fn
+	}
+}
+
+// WithClock replaces time.Now, used by tests.
+func WithClock(now func() time.Time) Option {
+	return func(b *Breaker) {
+		b.now = now
+	}
+}
+
+// Breaker is a consecutive-failure circuit breaker.
+//
+// It opens after threshold consecutive failures and rejects calls with ErrOpen
+// for coolDown, then lets a single probe through (half-open). A successful probe
+// closes the breaker, a failed one opens it for another coolDown.
+type Breaker struct {
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	probeFrom time.Time
+
+	threshold     int
+	coolDown      time.Duration
+	onStateChange func(from, to State)
+	now           func() time.Time
+}
+
+func NewBreaker(threshold int, coolDown time.Duration, opts ...Option) *Breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	b := &Breaker{
+		threshold: threshold,
+		coolDown:  coolDown,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by exactly one Done.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	from := b.state
+	now := b.now()
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.openedAt) < b.coolDown {
+			b.mu.Unlock()
+			return ErrOpen
+		}
+		b.state = StateHalfOpen
+		b.probing, b.probeFrom = true, now
+	case StateHalfOpen:
+		// a probe that never reported back must not wedge the breaker
+		if b.probing && now.Sub(b.probeFrom) < b.coolDown {
+			b.mu.Unlock()
+			return ErrOpen
+		}
+		b.probing, b.probeFrom = true, now
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return nil
+}
+
+// Done records the outcome of a call admitted by Allow.
+func (b *Breaker) Done(success bool) {
+	b.mu.Lock()
+	from := b.state
+	// calls admitted before the breaker opened may still be finishing,
+	// their outcome must not close or re-arm an open breaker
+	switch {
+	case b.state == StateOpen:
+	case success:
+		b.failures = 0
+		b.probing = false
+		b.state = StateClosed
+	case b.state == StateHalfOpen:
+		b.failures++
+		b.probing = false
+		b.state, b.openedAt = StateOpen, b.now()
+	default:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.state, b.openedAt = StateOpen, b.now()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Failures returns the number of consecutive failures.
+func (b *Breaker) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.onStateChange != nil {
+		b.onStateChange(from, to)
+	}
+}
@@ -0,0 +1,111 @@
+package breaker
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestBreaker(t *testing.T) {
+	testCases := []struct {
+		name string
+		// steps drives the breaker, 's' success, 'f' failure, 'w' waits for the cool down
+		steps string
+
+		wantState State
+		wantErr   error
+	}{
+		{
+			name:      "stay closed below threshold",
+			steps:     "ff",
+			wantState: StateClosed,
+		},
+		{
+			name:      "success resets consecutive failures",
+			steps:     "ffsff",
+			wantState: StateClosed,
+		},
+		{
+			name:      "open after threshold",
+			steps:     "fff",
+			wantState: StateOpen,
+			wantErr:   ErrOpen,
+		},
+		{
+			name:      "half-open after cool down",
+			steps:     "fffw",
+			wantState: StateHalfOpen,
+		},
+		{
+			name:      "close after successful probe",
+			steps:     "fffws",
+			wantState: StateClosed,
+		},
+		{
+			name:      "reopen after failed probe",
+			steps:     "fffwf",
+			wantState: StateOpen,
+			wantErr:   ErrOpen,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Now()}
+			var transitions []State
+			b := NewBreaker(3, time.Minute, WithClock(clock.Now),
+				WithOnStateChange(func(from, to State) {
+					transitions = append(transitions, to)
+				}))
+
+			for _, step := range tc.steps {
+				switch step {
+				case 'w':
+					clock.now = clock.now.Add(time.Minute)
+					assert.NoError(t, b.Allow())
+				case 's', 'f':
+					if b.State() != StateHalfOpen {
+						assert.NoError(t, b.Allow())
+					}
+					b.Done(step == 's')
+				}
+			}
+
+			assert.Equal(t, tc.wantState, b.State())
+			if tc.wantState != StateClosed {
+				assert.NotEmpty(t, transitions)
+				assert.Equal(t, tc.wantState, transitions[len(transitions)-1])
+			}
+			if tc.wantState != StateHalfOpen {
+				assert.Equal(t, tc.wantErr, b.Allow())
+			}
+		})
+	}
+}
+
+func TestBreaker_SingleProbe(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	b := NewBreaker(1, time.Minute, WithClock(clock.Now))
+	assert.NoError(t, b.Allow())
+	b.Done(false)
+
+	clock.now = clock.now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, ErrOpen, b.Allow())
+
+	// the outcome of a call admitted before opening does not close it
+	b2 := NewBreaker(1, time.Minute, WithClock(clock.Now))
+	assert.NoError(t, b2.Allow())
+	assert.NoError(t, b2.Allow())
+	b2.Done(false)
+	b2.Done(true)
+	assert.Equal(t, StateOpen, b2.State())
+}
@@ -0,0 +1,240 @@
+// Package configx loads typed configs with viper, overridden by environment variables
+// and secret files, and prints them with the secrets redacted.
+package configx
+
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileSuffix marks the environment variables naming a file holding the value, e.g. a mounted secret.
+const FileSuffix = "_FILE"
+
+// EnvName returns the environment variable overriding key, prefix_KEY with the dots replaced by underscores.
+func EnvName(prefix, key string) string {
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// BindEnv lets the environment override every setting of cfg, see Keys, in v: the variable named by EnvName,
+// e.g. MEMECOIN_DB_DSN for db.dsn, or the content of the file named by the same variable suffixed by FileSuffix.
+// Setting both is an error.
+//
+// The settings of the elements of the lists of structs are overridden by variables naming their index,
+// e.g. MEMECOIN_DB_REPLICAS_0_DSN for the dsn of the first element of db.replicas. An index past the end
+// of the list appends elements to it.
+func BindEnv(v *viper.Viper, prefix string, cfg any) error {
+	for _, key := range Keys(cfg) {
+		name := EnvName(prefix, key)
+		if err := v.BindEnv(key, name); err != nil {
+			return err
+		}
+		value, ok, err := lookupFile(name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			v.Set(key, value)
+		}
+	}
+	var lists []list
+	structLists("", reflect.TypeOf(cfg), &lists)
+	for _, l := range lists {
+		if err := bindList(v, prefix, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupFile returns the content of the file named by the variable name suffixed by FileSuffix,
+// false when it is not set.
+func lookupFile(name string) (string, bool, error) {
+	file, ok := os.LookupEnv(name + FileSuffix)
+	if !ok {
+		return "", false, nil
+	}
+	if _, ok = os.LookupEnv(name); ok {
+		return "", false, fmt.Errorf("both %s and %s%s are set", name, name, FileSuffix)
+	}
+	bs, err := os.ReadFile(file)
+	if err != nil {
+		return "", false, fmt.Errorf("read %s%s: %w", name, FileSuffix, err)
+	}
+	// the editors and the secret stores often end the file with a newline
+	return strings.TrimRight(string(bs), "\r\n"), true, nil
+}
+
+// lookup returns the value of the variable name or the content of the file it names, see lookupFile.
+func lookup(name string) (string, bool, error) {
+	value, ok, err := lookupFile(name)
+	if err != nil || ok {
+		return value, ok, err
+	}
+	value, ok = os.LookupEnv(name)
+	return value, ok, nil
+}
+
+// list is a setting holding a list of structs, keys are the settings of its elements.
+type list struct {
+	key  string
+	keys []string
+}
+
+// bindList sets the elements of the list l overridden by the environment in v.
+func bindList(v *viper.Viper, prefix string, l list) error {
+	name := EnvName(prefix, l.key) + "_"
+	n := -1
+	for _, env := range os.Environ() {
+		env, _, _ = strings.Cut(env, "=")
+		rest, ok := strings.CutPrefix(env, name)
+		if !ok {
+			continue
+		}
+		index, _, _ := strings.Cut(rest, "_")
+		if i, err := strconv.Atoi(index); err == nil && i >= 0 && i > n {
+			n = i
+		}
+	}
+	if n < 0 {
+		return nil
+	}
+
+	var elems []any
+	if cur, ok := v.Get(l.key).([]any); ok {
+		elems = append(elems, cur...)
+	}
+	changed := false
+	for i := 0; i <= n; i++ {
+		for _, key := range l.keys {
+			value, ok, err := lookup(EnvName(prefix, fmt.Sprintf("%s.%d.%s", l.key, i, key)))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			for len(elems) <= i {
+				elems = append(elems, map[string]any{})
+			}
+			elem, _ := elems[i].(map[string]any)
+			elems[i] = setPath(elem, strings.Split(key, "."), value)
+			changed = true
+		}
+	}
+	if changed {
+		v.Set(l.key, elems)
+	}
+	return nil
+}
+
+// setPath returns a copy of m with the value at path set, the keys are matched regardless of their case.
+func setPath(m map[string]any, path []string, value any) map[string]any {
+	res := make(map[string]any, len(m)+1)
+	var cur any
+	for k, v := range m {
+		if strings.EqualFold(k, path[0]) {
+			cur = v
+			continue
+		}
+		res[k] = v
+	}
+	if len(path) == 1 {
+		res[path[0]] = value
+		return res
+	}
+	child, _ := cur.(map[string]any)
+	res[path[0]] = setPath(child, path[1:], value)
+	return res
+}
+
+// Unmarshal decodes v into cfg, matching the keys with the yaml tags of its fields.
+func Unmarshal(v *viper.Viper, cfg any) error {
+	return v.Unmarshal(cfg, func(c *mapstructure.DecoderConfig) {
+		c.TagName = "yaml"
+	})
+}
+
+// Keys returns the keys of the settings of cfg, the dotted yaml tags of its fields down to the values
+// that are not structs. The settings held in the elements of slices and in maps are not listed.
+func Keys(cfg any) []string {
+	var res []string
+	keys("", reflect.TypeOf(cfg), &res)
+	return res
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func keys(prefix string, t reflect.Type, res *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == durationType {
+		*res = append(*res, prefix)
+		return
+	}
+	fields(prefix, t, func(key string, t reflect.Type) {
+		keys(key, t, res)
+	})
+}
+
+// structLists appends the lists of structs of t to res, with the keys of the settings of their elements.
+func structLists(prefix string, t reflect.Type, res *[]list) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice {
+		elem := t.Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Struct && elem != durationType {
+			l := list{key: prefix}
+			keys("", elem, &l.keys)
+			*res = append(*res, l)
+		}
+		return
+	}
+	if t.Kind() != reflect.Struct || t == durationType {
+		return
+	}
+	fields(prefix, t, func(key string, t reflect.Type) {
+		structLists(key, t, res)
+	})
+}
+
+// fields calls fn with the key and the type of every setting among the fields of the struct t.
+func fields(prefix string, t reflect.Type, fn func(key string, t reflect.Type)) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		fn(name, f.Type)
+	}
+}
+
+// fieldName returns the key of the field f, false when it is not a setting.
+func fieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return strings.ToLower(f.Name), true
+	default:
+		return name, true
+	}
+}
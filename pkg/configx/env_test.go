@@ -0,0 +1,160 @@
+package configx
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDBConfig struct {
+	Driver string `yaml:"driver"`
+	DSN    string `yaml:"dsn" redact:"true"`
+	Pool   struct {
+		MaxOpenConns int `yaml:"maxOpenConns"`
+	} `yaml:"pool"`
+}
+
+type testConfig struct {
+	File     string              `yaml:"-"`
+	DB       testDBConfig        `yaml:"db"`
+	Replicas []testReplicaConfig `yaml:"replicas"`
+	Addrs    []string            `yaml:"addrs"`
+	NodeId   *int64              `yaml:"nodeId"`
+	Timeout  time.Duration       `yaml:"timeout"`
+	Enabled  bool
+}
+
+func TestKeys(t *testing.T) {
+	assert.Equal(t, []string{
+		"db.driver", "db.dsn", "db.pool.maxOpenConns", "replicas", "addrs", "nodeId", "timeout", "enabled",
+	}, Keys(testConfig{}))
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "MEMECOIN_DB_POOL_MAXOPENCONNS", EnvName("MEMECOIN", "db.pool.maxOpenConns"))
+}
+
+func TestBindEnv(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "dsn")
+	require.NoError(t, os.WriteFile(secret, []byte("root:secret@tcp(db:3306)/portto\n"), 0o600))
+
+	testCases := []struct {
+		name string
+		env  map[string]string
+
+		wantCfg testConfig
+		wantErr string
+	}{
+		{
+			name: "file only",
+			wantCfg: testConfig{
+				DB:       testDBConfig{Driver: "mysql", DSN: "root:root@tcp(localhost:13306)/portto"},
+				Replicas: []testReplicaConfig{{Name: "replica-1", DSN: "root:root@tcp(replica-1:3306)/portto"}},
+				Timeout:  time.Second,
+			},
+		},
+		{
+			name: "overridden",
+			env: map[string]string{
+				"TEST_DB_DSN":               "root:env@tcp(db:3306)/portto",
+				"TEST_DB_POOL_MAXOPENCONNS": "50",
+				"TEST_ADDRS":                "a:6379,b:6379",
+				"TEST_NODEID":               "7",
+				"TEST_TIMEOUT":              "5s",
+				"TEST_ENABLED":              "true",
+			},
+			wantCfg: func() testConfig {
+				nodeId := int64(7)
+				c := testConfig{
+					DB:       testDBConfig{Driver: "mysql", DSN: "root:env@tcp(db:3306)/portto"},
+					Replicas: []testReplicaConfig{{Name: "replica-1", DSN: "root:root@tcp(replica-1:3306)/portto"}},
+					Addrs:    []string{"a:6379", "b:6379"},
+					NodeId:   &nodeId,
+					Timeout:  5 * time.Second,
+					Enabled:  true,
+				}
+				c.DB.Pool.MaxOpenConns = 50
+				return c
+			}(),
+		},
+		{
+			name: "secret file",
+			env: map[string]string{
+				"TEST_DB_DSN_FILE": secret,
+			},
+			wantCfg: testConfig{
+				DB:       testDBConfig{Driver: "mysql", DSN: "root:secret@tcp(db:3306)/portto"},
+				Replicas: []testReplicaConfig{{Name: "replica-1", DSN: "root:root@tcp(replica-1:3306)/portto"}},
+				Timeout:  time.Second,
+			},
+		},
+		{
+			name: "indexed list elements",
+			env: map[string]string{
+				"TEST_REPLICAS_0_DSN_FILE": secret,
+				"TEST_REPLICAS_1_NAME":     "replica-2",
+				"TEST_REPLICAS_1_DSN":      "root:env@tcp(replica-2:3306)/portto",
+			},
+			wantCfg: testConfig{
+				DB: testDBConfig{Driver: "mysql", DSN: "root:root@tcp(localhost:13306)/portto"},
+				Replicas: []testReplicaConfig{
+					{Name: "replica-1", DSN: "root:secret@tcp(db:3306)/portto"},
+					{Name: "replica-2", DSN: "root:env@tcp(replica-2:3306)/portto"},
+				},
+				Timeout: time.Second,
+			},
+		},
+		{
+			name: "indexed value and file",
+			env: map[string]string{
+				"TEST_REPLICAS_0_DSN":      "root:env@tcp(replica-1:3306)/portto",
+				"TEST_REPLICAS_0_DSN_FILE": secret,
+			},
+			wantErr: "both TEST_REPLICAS_0_DSN and TEST_REPLICAS_0_DSN_FILE are set",
+		},
+		{
+			name: "missing secret file",
+			env: map[string]string{
+				"TEST_DB_DSN_FILE": filepath.Join(t.TempDir(), "missing"),
+			},
+			wantErr: "read TEST_DB_DSN_FILE",
+		},
+		{
+			name: "value and file",
+			env: map[string]string{
+				"TEST_DB_DSN":      "root:env@tcp(db:3306)/portto",
+				"TEST_DB_DSN_FILE": secret,
+			},
+			wantErr: "both TEST_DB_DSN and TEST_DB_DSN_FILE are set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			v := viper.New()
+			v.SetConfigType("yaml")
+			require.NoError(t, v.ReadConfig(strings.NewReader(
+				"db:\n  driver: mysql\n  dsn: root:root@tcp(localhost:13306)/portto\n"+
+					"replicas:\n  - name: replica-1\n    dsn: root:root@tcp(replica-1:3306)/portto\nunknown: 1\n")))
+
+			var c testConfig
+			c.Timeout = time.Second
+			err := BindEnv(v, "TEST", c)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, Unmarshal(v, &c))
+			assert.Equal(t, tc.wantCfg, c)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package configx
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Redacted replaces the values of the fields tagged redact:"true" when printing a config.
+const Redacted = "******"
+
+// Encode returns the yaml document of cfg in the order of its fields, with the durations as strings.
+// With redact, the non-empty values of the fields tagged redact:"true" are replaced by Redacted,
+// including in the elements of slices.
+func Encode(cfg any, redact bool) (*yaml.Node, error) {
+	return encode(reflect.ValueOf(cfg), redact)
+}
+
+func encode(v reflect.Value, redact bool) (*yaml.Node, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Type() == durationType:
+		return scalar(v.Interface().(time.Duration).String()), nil
+	case v.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, ok := fieldName(f)
+			if !ok {
+				continue
+			}
+			value, err := encode(v.Field(i), redact)
+			if err != nil {
+				return nil, err
+			}
+			if redact && f.Tag.Get("redact") == "true" && !v.Field(i).IsZero() {
+				value = scalar(Redacted)
+			}
+			node.Content = append(node.Content, scalar(name), value)
+		}
+		return node, nil
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := range v.Len() {
+			value, err := encode(v.Index(i), redact)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+	case v.Kind() == reflect.Map:
+		node := &yaml.Node{}
+		if err := node.Encode(v.Interface()); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case v.Kind() == reflect.String:
+		return scalar(v.String()), nil
+	case v.Kind() == reflect.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v.Bool())}, nil
+	case v.CanInt():
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(v.Int(), 10)}, nil
+	case v.CanUint():
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatUint(v.Uint(), 10)}, nil
+	case v.CanFloat():
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(v.Float(), 'g', -1, 64)}, nil
+	default:
+		return nil, fmt.Errorf("cannot encode %s", v.Type())
+	}
+}
+
+func scalar(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
@@ -0,0 +1,83 @@
+package configx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+)
+
+type testReplicaConfig struct {
+	Name string `yaml:"name"`
+	DSN  string `yaml:"dsn" redact:"true"`
+}
+
+type testPrintConfig struct {
+	File     string              `yaml:"-"`
+	DSN      string              `yaml:"dsn" redact:"true"`
+	Token    string              `yaml:"token" redact:"true"`
+	Replicas []testReplicaConfig `yaml:"replicas"`
+	Timeout  time.Duration       `yaml:"timeout"`
+	Ratio    float64             `yaml:"ratio"`
+	NodeId   *int64              `yaml:"nodeId"`
+	Enabled  bool                `yaml:"enabled"`
+}
+
+func TestEncode(t *testing.T) {
+	cfg := testPrintConfig{
+		File: "config.yaml",
+		DSN:  "root:root@tcp(localhost:13306)/portto",
+		Replicas: []testReplicaConfig{
+			{Name: "replica-0", DSN: "root:root@tcp(replica:3306)/portto"},
+		},
+		Timeout: 90 * time.Second,
+		Ratio:   0.5,
+		Enabled: true,
+	}
+	testCases := []struct {
+		name   string
+		redact bool
+
+		want string
+	}{
+		{
+			name: "plain",
+			want: `dsn: root:root@tcp(localhost:13306)/portto
+token: ""
+replicas:
+    - name: replica-0
+      dsn: root:root@tcp(replica:3306)/portto
+timeout: 1m30s
+ratio: 0.5
+nodeId: null
+enabled: true
+`,
+		},
+		{
+			name:   "redacted",
+			redact: true,
+			// the empty secrets are left empty, they show that the secret is missing
+			want: `dsn: '******'
+token: ""
+replicas:
+    - name: replica-0
+      dsn: '******'
+timeout: 1m30s
+ratio: 0.5
+nodeId: null
+enabled: true
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node, err := Encode(cfg, tc.redact)
+			require.NoError(t, err)
+			bs, err := yaml.Marshal(node)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(bs))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package ginx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// AccessLog binds l, with the request id and the trace id of the request, to the request context,
+// see logger.FromContext, and logs every request once served. Use it following RequestId.
+func AccessLog(l logger.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		rl := l.With(logger.String("request_id", RequestIdFromContext(ctx.Request.Context())))
+		if sc := trace.SpanContextFromContext(ctx.Request.Context()); sc.IsValid() {
+			rl = rl.With(logger.String("trace_id", sc.TraceID().String()))
+		}
+		ctx.Request = ctx.Request.WithContext(logger.WithContext(ctx.Request.Context(), rl))
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		rl.Info("access",
+			logger.String("method", ctx.Request.Method),
+			logger.String("route", route),
+			logger.String("path", ctx.Request.URL.Path),
+			logger.Int("status", ctx.Writer.Status()),
+			logger.Int64("latency_ms", time.Since(start).Milliseconds()),
+			logger.String("client_ip", ctx.ClientIP()))
+	}
+}
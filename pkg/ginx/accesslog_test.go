@@ -0,0 +1,76 @@
+package ginx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requestId string
+
+		wantRequestId func(t *testing.T, id string)
+	}{
+		{
+			name:      "caller request id",
+			requestId: "req-1",
+			wantRequestId: func(t *testing.T, id string) {
+				assert.Equal(t, "req-1", id)
+			},
+		},
+		{
+			name: "generated request id",
+			wantRequestId: func(t *testing.T, id string) {
+				assert.Len(t, id, 26)
+			},
+		},
+		{
+			name:      "invalid request id replaced",
+			requestId: "req 1\n",
+			wantRequestId: func(t *testing.T, id string) {
+				assert.Len(t, id, 26)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(zap.DebugLevel)
+			server := gin.New()
+			server.ContextWithFallback = true
+			server.Use(RequestId(), AccessLog(logger.NewZapLogger(zap.New(core))))
+			server.GET("/coins/:id", func(ctx *gin.Context) {
+				logger.FromContext(ctx, logger.NewNopLogger()).Debug("handled")
+				ctx.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/coins/1", nil)
+			if tc.requestId != "" {
+				req.Header.Set(RequestIdHeader, tc.requestId)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			id := recorder.Header().Get(RequestIdHeader)
+			tc.wantRequestId(t, id)
+			entries := logs.AllUntimed()
+			require.Len(t, entries, 2)
+			// the handler logs with the logger bound to the request
+			assert.Equal(t, "handled", entries[0].Message)
+			assert.Equal(t, id, entries[0].ContextMap()["request_id"])
+			access := entries[1].ContextMap()
+			assert.Equal(t, "access", entries[1].Message)
+			assert.Equal(t, id, access["request_id"])
+			assert.Equal(t, "GET", access["method"])
+			assert.Equal(t, "/coins/:id", access["route"])
+			assert.Equal(t, int64(http.StatusNoContent), access["status"])
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Package ginx holds the gin middlewares that are not specific to this api.
+package ginx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+// Metrics counts the requests and observes their latency by method, route and status.
+// The route is the pattern matched, e.g. /api/v1/meme-coins/:id, so that the ids do not
+// blow up the cardinality. Requests matching no route are reported as "unmatched".
+// Register it outside gin.Recovery, the panics unwind past the code following ctx.Next.
+func Metrics(reg prometheus.Registerer, namespace string) gin.HandlerFunc {
+	labels := []string{"method", "route", "status"}
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of http requests served.",
+	}, labels)
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the http requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, labels)
+	reg.MustRegister(requests, duration)
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+		requests.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		duration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
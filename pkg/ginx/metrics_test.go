@@ -0,0 +1,39 @@
+package ginx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	server := gin.New()
+	server.Use(Metrics(reg, "test"), gin.Recovery())
+	server.GET("/coins/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	server.GET("/panic", func(ctx *gin.Context) {
+		panic("mock panic")
+	})
+
+	for _, url := range []string{"/coins/1", "/coins/2", "/unknown", "/panic"} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		server.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := `
+# HELP test_http_requests_total Number of http requests served.
+# TYPE test_http_requests_total counter
+test_http_requests_total{method="GET",route="/coins/:id",status="200"} 2
+test_http_requests_total{method="GET",route="/panic",status="500"} 1
+test_http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(want), "test_http_requests_total"))
+	assert.Equal(t, 3, testutil.CollectAndCount(reg, "test_http_request_duration_seconds"))
+}
@@ -0,0 +1,36 @@
+package ginx
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/miles0wu/meme-coin-api/pkg/ulid"
+	"regexp"
+)
+
+// RequestIdHeader carries the id of a request, from the caller when it has one, and back in the response.
+const RequestIdHeader = "X-Request-ID"
+
+// requestIdPattern keeps the ids of the callers short and safe to log.
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+type requestIdKey struct{}
+
+// RequestId keeps the request id of the caller, or generates one when it is missing or invalid,
+// returns it in the response and binds it to the request context, see RequestIdFromContext.
+func RequestId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIdHeader)
+		if !requestIdPattern.MatchString(id) {
+			id = ulid.New()
+		}
+		ctx.Header(RequestIdHeader, id)
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), requestIdKey{}, id))
+		ctx.Next()
+	}
+}
+
+// RequestIdFromContext returns the request id bound by RequestId, or "" outside a request.
+func RequestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
@@ -0,0 +1,45 @@
+package health
+
+import "context"
+
+type Status string
+
+const (
+	StatusUp Status = "up"
+	// StatusDegraded means the dependency is impaired but the service can still serve traffic.
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Worse returns the more severe of two statuses.
+func Worse(a, b Status) Status {
+	if a.severity() >= b.severity() {
+		return a
+	}
+	return b
+}
+
+func (s Status) severity() int {
+	switch s {
+	case StatusUp:
+		return 0
+	case StatusDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Report is the outcome of checking a single dependency.
+type Report struct {
+	Status Status `json:"status"`
+	Detail any    `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// LatencyMs is how long the check took, it is set by the caller running the check.
+	LatencyMs int64 `json:"latencyMs"`
+}
+
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) Report
+}
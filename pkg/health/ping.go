@@ -0,0 +1,34 @@
+package health
+
+import "context"
+
+// PingChecker reports a dependency as up when ping succeeds, and with its failure status otherwise.
+type PingChecker struct {
+	name    string
+	failure Status
+	ping    func(ctx context.Context) error
+}
+
+// NewPingChecker returns a checker reporting failure when ping fails, StatusDegraded
+// for a dependency the service can do without, StatusDown otherwise.
+func NewPingChecker(name string, failure Status, ping func(ctx context.Context) error) *PingChecker {
+	return &PingChecker{
+		name:    name,
+		failure: failure,
+		ping:    ping,
+	}
+}
+
+func (c *PingChecker) Name() string {
+	return c.name
+}
+
+func (c *PingChecker) Check(ctx context.Context) Report {
+	if err := c.ping(ctx); err != nil {
+		return Report{
+			Status: c.failure,
+			Error:  err.Error(),
+		}
+	}
+	return Report{Status: StatusUp}
+}
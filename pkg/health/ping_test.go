@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPingChecker_Check(t *testing.T) {
+	testCases := []struct {
+		name    string
+		failure Status
+		ping    func(ctx context.Context) error
+
+		wantReport Report
+	}{
+		{
+			name:    "up",
+			failure: StatusDown,
+			ping: func(ctx context.Context) error {
+				return nil
+			},
+			wantReport: Report{Status: StatusUp},
+		},
+		{
+			name:    "down",
+			failure: StatusDown,
+			ping: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			},
+			wantReport: Report{Status: StatusDown, Error: "connection refused"},
+		},
+		{
+			name:    "degraded",
+			failure: StatusDegraded,
+			ping: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			},
+			wantReport: Report{Status: StatusDegraded, Error: "connection refused"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewPingChecker("redis_ping", tc.failure, tc.ping)
+			assert.Equal(t, "redis_ping", c.Name())
+			assert.Equal(t, tc.wantReport, c.Check(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+// DBStats is the json view of sql.DBStats.
+type DBStats struct {
+	MaxOpenConnections int   `json:"maxOpenConnections"`
+	OpenConnections    int   `json:"openConnections"`
+	InUse              int   `json:"inUse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitCount"`
+	WaitDurationMs     int64 `json:"waitDurationMs"`
+	MaxIdleClosed      int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64 `json:"maxLifetimeClosed"`
+}
+
+func NewDBStats(s sql.DBStats) DBStats {
+	return DBStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationMs:     s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+}
+
+// DBChecker pings a database and reports its pool statistics.
+type DBChecker struct {
+	name string
+	db   *sql.DB
+	// lastWaitCount is the wait count seen by the previous check
+	lastWaitCount atomic.Int64
+}
+
+func NewDBChecker(name string, db *sql.DB) *DBChecker {
+	return &DBChecker{
+		name: name,
+		db:   db,
+	}
+}
+
+func (c *DBChecker) Name() string {
+	return c.name
+}
+
+// Check reports degraded when the pool is exhausted, that is every connection is in use and callers waited
+// for one since the previous check, and down when the ping fails. An exhausted pool is not pinged,
+// the ping would wait for a connection as well.
+func (c *DBChecker) Check(ctx context.Context) Report {
+	stats := c.db.Stats()
+	report := Report{
+		Status: StatusUp,
+		Detail: NewDBStats(stats),
+	}
+	waited := c.lastWaitCount.Swap(stats.WaitCount) < stats.WaitCount
+	if stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections && waited {
+		report.Status = StatusDegraded
+		report.Error = "connection pool exhausted"
+		return report
+	}
+	if err := c.db.PingContext(ctx); err != nil {
+		report.Status = StatusDown
+		report.Error = err.Error()
+	}
+	return report
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDBChecker_Check(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+
+		wantStatus Status
+		wantErr    string
+	}{
+		{
+			name: "up",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+				require.NoError(t, err)
+				mock.ExpectPing()
+				return db
+			},
+			wantStatus: StatusUp,
+		},
+		{
+			name: "ping failed",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+				require.NoError(t, err)
+				mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+				return db
+			},
+			wantStatus: StatusDown,
+			wantErr:    "connection refused",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewDBChecker("db", tc.sqlmock(t))
+			report := c.Check(context.Background())
+			assert.Equal(t, tc.wantStatus, report.Status)
+			assert.Equal(t, tc.wantErr, report.Error)
+			assert.IsType(t, DBStats{}, report.Detail)
+		})
+	}
+}
+
+func TestDBChecker_CheckExhausted(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	// a caller gives up waiting for the only connection
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = db.Conn(waitCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	c := NewDBChecker("db", db)
+	report := c.Check(ctx)
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Equal(t, "connection pool exhausted", report.Error)
+	assert.Equal(t, int64(1), report.Detail.(DBStats).WaitCount)
+
+	// nobody waited since the previous check
+	require.NoError(t, conn.Close())
+	mock.ExpectPing()
+	report = c.Check(ctx)
+	assert.Equal(t, StatusUp, report.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewDBStats(t *testing.T) {
+	assert.Equal(t, DBStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    4,
+		InUse:              3,
+		Idle:               1,
+		WaitCount:          2,
+		WaitDurationMs:     1500,
+	}, NewDBStats(sql.DBStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    4,
+		InUse:              3,
+		Idle:               1,
+		WaitCount:          2,
+		WaitDuration:       1500 * time.Millisecond,
+	}))
+}
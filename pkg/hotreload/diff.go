@@ -0,0 +1,54 @@
+package hotreload
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is a setting whose value changed, Key is its dotted path.
+type Change struct {
+	Key string
+	Old any
+	New any
+}
+
+// Diff returns the fields of the structs old and new with different values,
+// named by their yaml tags.
+func Diff[T any](old, new T) []Change {
+	var res []Change
+	diff("", reflect.ValueOf(old), reflect.ValueOf(new), &res)
+	return res
+}
+
+func diff(prefix string, old, new reflect.Value, res *[]Change) {
+	if old.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			*res = append(*res, Change{Key: prefix, Old: display(old), New: display(new)})
+		}
+		return
+	}
+	t := old.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		diff(name, old.Field(i), new.Field(i), res)
+	}
+}
+
+// display returns the value to log, the durations and the like read better as strings.
+func display(v reflect.Value) any {
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	return v.Interface()
+}
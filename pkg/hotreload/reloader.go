@@ -0,0 +1,150 @@
+// Package hotreload applies the changes of a config file to the running components.
+package hotreload
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/miles0wu/meme-coin-api/pkg/configx"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/spf13/viper"
+	"sync"
+)
+
+var ErrInvalid = errors.New("invalid config")
+
+// Validator is implemented by the configs checking their values, an invalid config is not applied.
+type Validator interface {
+	Validate() error
+}
+
+// Subscriber applies a config to a component, it fails when the component rejects it.
+type Subscriber[T any] func(c T) error
+
+// Reloader holds the config T decoded from a config file, matching the keys with the yaml tags
+// of its fields. Only the keys matching the fields of T are read, T is the whitelist of the settings that may change at runtime.
+//
+// A change of the file is validated, then applied to every subscriber. When one fails,
+// the previous config is applied back to the others, so that they never disagree.
+type Reloader[T any] struct {
+	// v reads the file apart from the global viper, whose settings are only read on startup
+	v        *viper.Viper
+	defaults T
+	l        logger.Logger
+	prepare  func(v *viper.Viper) error
+
+	mu      sync.Mutex
+	current T
+	subs    []Subscriber[T]
+}
+
+type Option[T any] func(r *Reloader[T])
+
+// WithPrepare runs fn on the viper reading the file before each decoding, e.g. to bind the environment.
+func WithPrepare[T any](fn func(v *viper.Viper) error) Option[T] {
+	return func(r *Reloader[T]) {
+		r.prepare = fn
+	}
+}
+
+// New reads file, the settings missing from it keep their value in defaults.
+func New[T any](file string, defaults T, l logger.Logger, opts ...Option[T]) (*Reloader[T], error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(file)
+	r := &Reloader[T]{
+		v:        v,
+		defaults: defaults,
+		l:        l,
+		prepare: func(v *viper.Viper) error {
+			return nil
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	c, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.current = c
+	return r, nil
+}
+
+// Current returns the config applied last.
+func (r *Reloader[T]) Current() T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Subscribe registers fn to be called with the config on every change,
+// call Current to configure the component on startup. fn must not call the methods of r.
+func (r *Reloader[T]) Subscribe(fn Subscriber[T]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, fn)
+}
+
+// Watch reloads the config whenever the file is written or replaced.
+func (r *Reloader[T]) Watch() {
+	r.v.OnConfigChange(func(fsnotify.Event) {
+		if err := r.Reload(); err != nil {
+			r.l.Error("config reload rejected, keeping the current config", logger.Error(err))
+		}
+	})
+	r.v.WatchConfig()
+}
+
+// Reload reads the file and applies the config when it changed.
+func (r *Reloader[T]) Reload() error {
+	next, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changes := Diff(r.current, next)
+	if len(changes) == 0 {
+		return nil
+	}
+	for i, sub := range r.subs {
+		if err = sub(next); err == nil {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if rerr := r.subs[j](r.current); rerr != nil {
+				r.l.Error("config rollback failed", logger.Error(rerr))
+			}
+		}
+		return fmt.Errorf("apply config: %w", err)
+	}
+	r.current = next
+	for _, c := range changes {
+		r.l.Info("config changed",
+			logger.String("key", c.Key),
+			logger.Any("old", c.Old),
+			logger.Any("new", c.New))
+	}
+	return nil
+}
+
+func (r *Reloader[T]) load() (T, error) {
+	c := r.defaults
+	if err := r.v.ReadInConfig(); err != nil {
+		return c, err
+	}
+	if err := r.prepare(r.v); err != nil {
+		return c, err
+	}
+	if err := configx.Unmarshal(r.v, &c); err != nil {
+		return c, err
+	}
+	if v, ok := any(c).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return c, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,162 @@
+package hotreload
+
+import (
+	"errors"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Log struct {
+		Level string `yaml:"level"`
+	} `yaml:"log"`
+	TTL time.Duration `yaml:"ttl"`
+}
+
+func (c testConfig) Validate() error {
+	if c.TTL <= 0 {
+		return errors.New("ttl must be positive")
+	}
+	return nil
+}
+
+func writeConfig(t *testing.T, file, content string) {
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+}
+
+func newTestReloader(t *testing.T, content string) (*Reloader[testConfig], string, *logger.TestLogger) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, file, content)
+	l := logger.NewTestLogger()
+	defaults := testConfig{TTL: time.Minute}
+	defaults.Log.Level = "info"
+	r, err := New(file, defaults, l)
+	require.NoError(t, err)
+	return r, file, l
+}
+
+func TestReloader_Reload(t *testing.T) {
+	r, file, l := newTestReloader(t, "db:\n  dsn: a\nlog:\n  level: warn\n")
+	assert.Equal(t, "warn", r.Current().Log.Level)
+	assert.Equal(t, time.Minute, r.Current().TTL)
+
+	var got []testConfig
+	r.Subscribe(func(c testConfig) error {
+		got = append(got, c)
+		return nil
+	})
+
+	// the keys outside of the whitelist are not watched
+	writeConfig(t, file, "db:\n  dsn: b\nlog:\n  level: warn\n")
+	require.NoError(t, r.Reload())
+	assert.Empty(t, got)
+
+	writeConfig(t, file, "log:\n  level: debug\nttl: 5m\n")
+	require.NoError(t, r.Reload())
+	require.Len(t, got, 1)
+	assert.Equal(t, "debug", got[0].Log.Level)
+	assert.Equal(t, 5*time.Minute, r.Current().TTL)
+	assert.Equal(t, []logger.Entry{
+		{Level: logger.InfoLevel, Msg: "config changed", Fields: []logger.Field{
+			logger.String("key", "log.level"), logger.Any("old", "warn"), logger.Any("new", "debug")}},
+		{Level: logger.InfoLevel, Msg: "config changed", Fields: []logger.Field{
+			logger.String("key", "ttl"), logger.Any("old", "1m0s"), logger.Any("new", "5m0s")}},
+	}, l.Entries())
+}
+
+func TestReloader_Rejected(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+
+		wantErr error
+	}{
+		{
+			name:    "invalid",
+			content: "log:\n  level: debug\nttl: -1s\n",
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "malformed",
+			content: "log: [debug\n",
+		},
+		{
+			name:    "wrong type",
+			content: "ttl: soon\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, file, _ := newTestReloader(t, "log:\n  level: warn\n")
+			called := false
+			r.Subscribe(func(c testConfig) error {
+				called = true
+				return nil
+			})
+
+			writeConfig(t, file, tc.content)
+			err := r.Reload()
+			assert.Error(t, err)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+			assert.False(t, called)
+			assert.Equal(t, "warn", r.Current().Log.Level)
+		})
+	}
+}
+
+func TestReloader_Rollback(t *testing.T) {
+	r, file, _ := newTestReloader(t, "log:\n  level: warn\n")
+	var applied []string
+	r.Subscribe(func(c testConfig) error {
+		applied = append(applied, c.Log.Level)
+		return nil
+	})
+	r.Subscribe(func(c testConfig) error {
+		return errors.New("rejected")
+	})
+
+	writeConfig(t, file, "log:\n  level: debug\n")
+	assert.Error(t, r.Reload())
+	// the first subscriber is given the current config back
+	assert.Equal(t, []string{"debug", "warn"}, applied)
+	assert.Equal(t, "warn", r.Current().Log.Level)
+}
+
+func TestReloader_Watch(t *testing.T) {
+	r, file, _ := newTestReloader(t, "log:\n  level: warn\n")
+	levels := make(chan string, 10)
+	r.Subscribe(func(c testConfig) error {
+		levels <- c.Log.Level
+		return nil
+	})
+	r.Watch()
+
+	writeConfig(t, file, "log:\n  level: error\n")
+	select {
+	case level := <-levels:
+		assert.Equal(t, "error", level)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not picked up")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	var old, next testConfig
+	old.Log.Level, next.Log.Level = "info", "info"
+	assert.Empty(t, Diff(old, next))
+
+	next.Log.Level = "debug"
+	next.TTL = time.Second
+	assert.Equal(t, []Change{
+		{Key: "log.level", Old: "info", New: "debug"},
+		{Key: "ttl", Old: "0s", New: "1s"},
+	}, Diff(old, next))
+}
@@ -0,0 +1,98 @@
+// Package hotscore ranks items by their recent activity, so that a burst of pokes
+// outranks a pile of old ones.
+package hotscore
+
+import (
+	"math"
+	"time"
+)
+
+// Item is the state a Model scores an item from.
+type Item struct {
+	// Score is the score of the item at ScoredAt.
+	Score    float64
+	ScoredAt time.Time
+	// CreatedAt and Pokes, the pokes of the item so far, feed the models computing the score from scratch.
+	CreatedAt time.Time
+	Pokes     int64
+}
+
+type Model interface {
+	// Decay returns the score of the item at now.
+	Decay(item Item, now time.Time) float64
+	// Poke returns the score of the item at now after one more poke at now.
+	Poke(item Item, now time.Time) float64
+	// Unpoke returns the score of the item at now once the poke made at pokedAt is withdrawn.
+	Unpoke(item Item, pokedAt, now time.Time) float64
+}
+
+// minScore is the smallest score kept by Exponential, below it an idle item drops to zero
+// and is no longer rewritten.
+const minScore = 1e-6
+
+// Exponential counts every poke as 1, halved every HalfLife since the poke.
+type Exponential struct {
+	HalfLife time.Duration
+}
+
+func NewExponential(halfLife time.Duration) *Exponential {
+	return &Exponential{
+		HalfLife: halfLife,
+	}
+}
+
+func (m *Exponential) Decay(item Item, now time.Time) float64 {
+	score := item.Score * m.factor(item.ScoredAt, now)
+	if score < minScore {
+		return 0
+	}
+	return score
+}
+
+func (m *Exponential) Poke(item Item, now time.Time) float64 {
+	return m.Decay(item, now) + 1
+}
+
+func (m *Exponential) Unpoke(item Item, pokedAt, now time.Time) float64 {
+	score := m.Decay(item, now) - m.factor(pokedAt, now)
+	if score < minScore {
+		return 0
+	}
+	return score
+}
+
+// factor is what a score at since is worth at now.
+func (m *Exponential) factor(since, now time.Time) float64 {
+	// the clocks of the instances may disagree, a score is never increased by the decay
+	elapsed := max(now.Sub(since), 0)
+	return math.Exp2(-float64(elapsed) / float64(m.HalfLife))
+}
+
+// Gravity is the Hacker News ranking, pokes / (age in hours + 2) ^ Gravity.
+// It only depends on the age of the item, not on when it was poked.
+type Gravity struct {
+	Gravity float64
+}
+
+func NewGravity(gravity float64) *Gravity {
+	return &Gravity{
+		Gravity: gravity,
+	}
+}
+
+func (m *Gravity) Decay(item Item, now time.Time) float64 {
+	return m.score(item.Pokes, item.CreatedAt, now)
+}
+
+func (m *Gravity) Poke(item Item, now time.Time) float64 {
+	return m.score(item.Pokes+1, item.CreatedAt, now)
+}
+
+func (m *Gravity) Unpoke(item Item, _, now time.Time) float64 {
+	return m.score(max(item.Pokes-1, 0), item.CreatedAt, now)
+}
+
+func (m *Gravity) score(pokes int64, createdAt, now time.Time) float64 {
+	age := max(now.Sub(createdAt), 0).Hours()
+	return float64(pokes) / math.Pow(age+2, m.Gravity)
+}
@@ -0,0 +1,116 @@
+package hotscore
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	testCases := []struct {
+		name string
+		item Item
+		now  time.Time
+
+		wantDecay  float64
+		wantPoke   float64
+		wantUnpoke float64
+	}{
+		{
+			name:       "one half life",
+			item:       Item{Score: 8, ScoredAt: now.Add(-24 * time.Hour)},
+			now:        now,
+			wantDecay:  4,
+			wantPoke:   5,
+			wantUnpoke: 3.5,
+		},
+		{
+			name:       "three half lives",
+			item:       Item{Score: 8, ScoredAt: now.Add(-72 * time.Hour)},
+			now:        now,
+			wantDecay:  1,
+			wantPoke:   2,
+			wantUnpoke: 0.5,
+		},
+		{
+			name:       "scored in the future",
+			item:       Item{Score: 8, ScoredAt: now.Add(time.Minute)},
+			now:        now,
+			wantDecay:  8,
+			wantPoke:   9,
+			wantUnpoke: 7.5,
+		},
+		{
+			name:       "never scored",
+			item:       Item{},
+			now:        now,
+			wantDecay:  0,
+			wantPoke:   1,
+			wantUnpoke: 0,
+		},
+		{
+			name:       "decayed to zero",
+			item:       Item{Score: 1, ScoredAt: now.Add(-24 * 30 * time.Hour)},
+			now:        now,
+			wantDecay:  0,
+			wantPoke:   1,
+			wantUnpoke: 0,
+		},
+	}
+	m := NewExponential(24 * time.Hour)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.wantDecay, m.Decay(tc.item, tc.now), 1e-9)
+			assert.InDelta(t, tc.wantPoke, m.Poke(tc.item, tc.now), 1e-9)
+			assert.InDelta(t, tc.wantUnpoke, m.Unpoke(tc.item, tc.now.Add(-24*time.Hour), tc.now), 1e-9)
+		})
+	}
+}
+
+func TestGravity(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	testCases := []struct {
+		name string
+		item Item
+		now  time.Time
+
+		wantDecay  float64
+		wantPoke   float64
+		wantUnpoke float64
+	}{
+		{
+			name:       "new item",
+			item:       Item{Pokes: 3, CreatedAt: now},
+			now:        now,
+			wantDecay:  3 / math.Pow(2, 1.8),
+			wantPoke:   4 / math.Pow(2, 1.8),
+			wantUnpoke: 2 / math.Pow(2, 1.8),
+		},
+		{
+			name:       "day old item",
+			item:       Item{Pokes: 3, CreatedAt: now.Add(-24 * time.Hour)},
+			now:        now,
+			wantDecay:  3 / math.Pow(26, 1.8),
+			wantPoke:   4 / math.Pow(26, 1.8),
+			wantUnpoke: 2 / math.Pow(26, 1.8),
+		},
+		{
+			name:       "ignores the previous score",
+			item:       Item{Score: 100, ScoredAt: now, CreatedAt: now},
+			now:        now,
+			wantDecay:  0,
+			wantPoke:   1 / math.Pow(2, 1.8),
+			wantUnpoke: 0,
+		},
+	}
+	m := NewGravity(1.8)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.wantDecay, m.Decay(tc.item, tc.now), 1e-9)
+			assert.InDelta(t, tc.wantPoke, m.Poke(tc.item, tc.now), 1e-9)
+			assert.InDelta(t, tc.wantUnpoke, m.Unpoke(tc.item, tc.now.Add(-24*time.Hour), tc.now), 1e-9)
+		})
+	}
+}
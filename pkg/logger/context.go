@@ -0,0 +1,18 @@
+package logger
+
+import "context"
+
+type ctxKey struct{}
+
+// WithContext binds l to ctx, typically a logger carrying the request id of the request of ctx.
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger bound to ctx, or def when there is none.
+func FromContext(ctx context.Context, def Logger) Logger {
+	if l, ok := ctx.Value(ctxKey{}).(Logger); ok {
+		return l
+	}
+	return def
+}
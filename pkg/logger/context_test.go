@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	def := NewZapLogger(zap.New(core))
+	bound := def.With(String("request_id", "req-1"))
+
+	ctx := WithContext(context.Background(), bound)
+	FromContext(ctx, def).Debug("bound", Int("n", 1))
+	FromContext(context.Background(), def).Debug("default")
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, map[string]any{"request_id": "req-1", "n": int64(1)}, entries[0].ContextMap())
+	assert.Empty(t, entries[1].ContextMap())
+}
@@ -1,5 +1,10 @@
 package logger
 
+import (
+	"fmt"
+	"time"
+)
+
 func Error(err error) Field {
 	return Field{Key: "error", Value: err}
 }
@@ -16,10 +21,31 @@ func Int(key string, value int) Field {
 	return Field{Key: key, Value: value}
 }
 
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
 func String(key string, value string) Field {
 	return Field{Key: key, Value: value}
 }
 
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Stringer calls value.String() only when the entry is written.
+func Stringer(key string, value fmt.Stringer) Field {
+	return Field{Key: key, Value: value}
+}
+
 func Any(key string, value any) Field {
 	return Field{Key: key, Value: value}
 }
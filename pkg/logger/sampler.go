@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+// NewSampler samples the entries of core as zapcore.NewSamplerWithOptions does, except those of the loggers
+// named unsampled. An access log, whose entries all share a message but each record a request, is one of them.
+func NewSampler(core zapcore.Core, tick time.Duration, first, thereafter int, unsampled ...string) zapcore.Core {
+	names := make(map[string]struct{}, len(unsampled))
+	for _, name := range unsampled {
+		names[name] = struct{}{}
+	}
+	return &samplerCore{
+		Core:      core,
+		sampled:   zapcore.NewSamplerWithOptions(core, tick, first, thereafter),
+		unsampled: names,
+	}
+}
+
+// samplerCore routes the entries of the unsampled loggers to the embedded core, the others to sampled.
+type samplerCore struct {
+	zapcore.Core
+	sampled   zapcore.Core
+	unsampled map[string]struct{}
+}
+
+func (c *samplerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplerCore{
+		Core:      c.Core.With(fields),
+		sampled:   c.sampled.With(fields),
+		unsampled: c.unsampled,
+	}
+}
+
+func (c *samplerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if _, ok := c.unsampled[ent.LoggerName]; ok {
+		return c.Core.Check(ent, ce)
+	}
+	return c.sampled.Check(ent, ce)
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+func TestNewSampler(t *testing.T) {
+	testCases := []struct {
+		name string
+		// logger is the name of the logger, empty for the root one
+		logger string
+
+		wantCount int
+	}{
+		{
+			name:      "sampled",
+			wantCount: 2,
+		},
+		{
+			name:      "other logger sampled",
+			logger:    "repository",
+			wantCount: 2,
+		},
+		{
+			name:      "unsampled logger",
+			logger:    "access",
+			wantCount: 10,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			l := zap.New(NewSampler(core, time.Minute, 2, 100, "access")).With(zap.String("app", "test"))
+			if tc.logger != "" {
+				l = l.Named(tc.logger)
+			}
+			for range 10 {
+				l.Info("same message")
+			}
+			assert.Equal(t, tc.wantCount, logs.Len())
+			assert.Equal(t, "test", logs.All()[0].ContextMap()["app"])
+		})
+	}
+}
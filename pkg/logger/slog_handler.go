@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogHandler writes the slog records through a Logger, so that the code logging with slog
+// shares the output, the level and the fields of the Logger.
+type slogHandler struct {
+	l Logger
+	// prefix is the groups opened by WithGroup, joined with dots, they prefix the keys.
+	prefix string
+}
+
+// NewSlogHandler returns a slog.Handler writing through l, e.g. slog.New(logger.NewSlogHandler(l)).
+// The levels below info are debug and the ones above error are error. The handler drops the levels
+// l drops when l is a LevelEnabler, and passes the source of the records on when l is a CallerLogger.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{
+		l: l,
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if e, ok := h.l.(LevelEnabler); ok {
+		return e.Enabled(levelOf(level))
+	}
+	return true
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make([]Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogAttr(fields, h.prefix, a)
+		return true
+	})
+	level := levelOf(r.Level)
+	if c, ok := h.l.(CallerLogger); ok {
+		c.LogAt(level, r.PC, r.Message, fields...)
+		return nil
+	}
+	switch level {
+	case ErrorLevel:
+		h.l.Error(r.Message, fields...)
+	case WarnLevel:
+		h.l.Warn(r.Message, fields...)
+	case InfoLevel:
+		h.l.Info(r.Message, fields...)
+	default:
+		h.l.Debug(r.Message, fields...)
+	}
+	return nil
+}
+
+func levelOf(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, h.prefix, a)
+	}
+	return &slogHandler{
+		l:      h.l.With(fields...),
+		prefix: h.prefix,
+	}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{
+		l:      h.l,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+// appendSlogAttr flattens a, following the rules of slog: empty attributes and groups are dropped,
+// the attributes of a group without a key are inlined.
+func appendSlogAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendSlogAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
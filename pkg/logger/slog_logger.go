@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// SlogLogger writes through a slog.Logger, the source of its records is the caller of its methods.
+type SlogLogger struct {
+	l *slog.Logger
+}
+
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &SlogLogger{
+		l: l,
+	}
+}
+
+func (s *SlogLogger) Debug(msg string, args ...Field) {
+	s.log(slog.LevelDebug, msg, args)
+}
+
+func (s *SlogLogger) Info(msg string, args ...Field) {
+	s.log(slog.LevelInfo, msg, args)
+}
+
+func (s *SlogLogger) Warn(msg string, args ...Field) {
+	s.log(slog.LevelWarn, msg, args)
+}
+
+func (s *SlogLogger) Error(msg string, args ...Field) {
+	s.log(slog.LevelError, msg, args)
+}
+
+func (s *SlogLogger) With(args ...Field) Logger {
+	attrs := make([]any, 0, len(args))
+	for _, arg := range args {
+		attrs = append(attrs, slogAttr(arg))
+	}
+	return NewSlogLogger(s.l.With(attrs...))
+}
+
+func (s *SlogLogger) Enabled(level Level) bool {
+	return s.l.Enabled(context.Background(), slogLevel(level))
+}
+
+func (s *SlogLogger) LogAt(level Level, pc uintptr, msg string, args ...Field) {
+	s.logAt(slogLevel(level), pc, msg, args)
+}
+
+func (s *SlogLogger) log(level slog.Level, msg string, args []Field) {
+	var pcs [1]uintptr
+	// skip runtime.Callers, log and the method calling it
+	runtime.Callers(3, pcs[:])
+	s.logAt(level, pcs[0], msg, args)
+}
+
+func (s *SlogLogger) logAt(level slog.Level, pc uintptr, msg string, args []Field) {
+	ctx := context.Background()
+	if !s.l.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), level, msg, pc)
+	for _, arg := range args {
+		r.AddAttrs(slogAttr(arg))
+	}
+	_ = s.l.Handler().Handle(ctx, r)
+}
+
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+func slogAttr(f Field) slog.Attr {
+	switch v := f.Value.(type) {
+	case time.Duration, time.Time, error:
+		// slog formats them, before they are taken for stringers
+		return slog.Any(f.Key, v)
+	case fmt.Stringer:
+		return slog.Any(f.Key, stringerValue{v})
+	default:
+		return slog.Any(f.Key, v)
+	}
+}
+
+// stringerValue defers the call of String until a handler writes the record.
+type stringerValue struct {
+	s fmt.Stringer
+}
+
+func (v stringerValue) LogValue() slog.Value {
+	return slog.StringValue(v.s.String())
+}
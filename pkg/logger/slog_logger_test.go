@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     slog.LevelInfo,
+	}))).With(String("request_id", "req-1"))
+
+	l.Debug("dropped")
+	l.Warn("slow query",
+		Duration("latency", 1500*time.Millisecond),
+		Bool("primary", true),
+		Int64("rows", 3),
+		Stringer("addr", netip.MustParseAddr("10.0.0.1")),
+		Error(errors.New("mock db error")))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "slow query", entry["msg"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, float64(1500*time.Millisecond), entry["latency"])
+	assert.Equal(t, true, entry["primary"])
+	assert.Equal(t, float64(3), entry["rows"])
+	assert.Equal(t, "10.0.0.1", entry["addr"])
+	assert.Equal(t, "mock db error", entry["error"])
+	// the source is the caller of the logger, not the logger
+	source := entry["source"].(map[string]any)
+	assert.True(t, strings.HasSuffix(source["file"].(string), "slog_logger_test.go"), source["file"])
+}
+
+func TestSlogHandler(t *testing.T) {
+	l := NewTestLogger()
+	sl := slog.New(NewSlogHandler(l)).With("component", "lib")
+
+	sl.Debug("debug")
+	sl.WithGroup("db").Info("query", "rows", 3, slog.Group("pool", "open", 2))
+	sl.Warn("warn", slog.Group("empty"))
+	sl.Log(context.Background(), slog.LevelError+4, "fatal")
+
+	assert.Equal(t, []Entry{
+		{Level: DebugLevel, Msg: "debug", Fields: []Field{String("component", "lib")}},
+		{Level: InfoLevel, Msg: "query", Fields: []Field{
+			String("component", "lib"),
+			Int64("db.rows", 3),
+			Int64("db.pool.open", 2),
+		}},
+		{Level: WarnLevel, Msg: "warn", Fields: []Field{String("component", "lib")}},
+		{Level: ErrorLevel, Msg: "fatal", Fields: []Field{String("component", "lib")}},
+	}, l.Entries())
+}
+
+func TestSlogHandler_Zap(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	sl := slog.New(NewSlogHandler(NewZapLogger(zap.New(core, zap.AddCaller()))))
+
+	assert.False(t, sl.Enabled(context.Background(), slog.LevelDebug))
+	sl.Debug("dropped")
+	sl.Info("query", "rows", 3)
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "query", entries[0].Message)
+	assert.Equal(t, map[string]any{"rows": int64(3)}, entries[0].ContextMap())
+	// the caller is the code logging with slog, not the handler
+	assert.True(t, strings.HasSuffix(entries[0].Caller.File, "slog_logger_test.go"), entries[0].Caller.File)
+}
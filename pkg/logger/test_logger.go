@@ -0,0 +1,70 @@
+package logger
+
+import "sync"
+
+type Entry struct {
+	Level Level
+	Msg   string
+	// Fields are the fields of With followed by the ones of the entry.
+	Fields []Field
+}
+
+// TestLogger keeps the entries logged for the tests to assert on,
+// the loggers returned by With add to the entries of the logger they derive from.
+type TestLogger struct {
+	entries *testEntries
+	fields  []Field
+}
+
+type testEntries struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewTestLogger() *TestLogger {
+	return &TestLogger{
+		entries: &testEntries{},
+	}
+}
+
+func (t *TestLogger) Debug(msg string, args ...Field) {
+	t.log(DebugLevel, msg, args)
+}
+
+func (t *TestLogger) Info(msg string, args ...Field) {
+	t.log(InfoLevel, msg, args)
+}
+
+func (t *TestLogger) Warn(msg string, args ...Field) {
+	t.log(WarnLevel, msg, args)
+}
+
+func (t *TestLogger) Error(msg string, args ...Field) {
+	t.log(ErrorLevel, msg, args)
+}
+
+func (t *TestLogger) With(args ...Field) Logger {
+	return &TestLogger{
+		entries: t.entries,
+		fields:  append(t.fields[:len(t.fields):len(t.fields)], args...),
+	}
+}
+
+// Entries returns the entries logged so far, in order.
+func (t *TestLogger) Entries() []Entry {
+	t.entries.mu.Lock()
+	defer t.entries.mu.Unlock()
+	return append([]Entry(nil), t.entries.entries...)
+}
+
+func (t *TestLogger) log(level Level, msg string, args []Field) {
+	fields := make([]Field, 0, len(t.fields)+len(args))
+	fields = append(append(fields, t.fields...), args...)
+	t.entries.mu.Lock()
+	defer t.entries.mu.Unlock()
+	t.entries.entries = append(t.entries.entries, Entry{
+		Level:  level,
+		Msg:    msg,
+		Fields: fields,
+	})
+}
@@ -5,6 +5,19 @@ type Logger interface {
 	Info(msg string, args ...Field)
 	Warn(msg string, args ...Field)
 	Error(msg string, args ...Field)
+	// With returns a logger adding args to every entry.
+	With(args ...Field) Logger
+}
+
+// LevelEnabler is implemented by the loggers dropping the entries below a level.
+type LevelEnabler interface {
+	Enabled(level Level) bool
+}
+
+// CallerLogger is implemented by the loggers reporting the caller of their entries,
+// LogAt logs an entry made at the program counter pc, e.g. by a slog record.
+type CallerLogger interface {
+	LogAt(level Level, pc uintptr, msg string, args ...Field)
 }
 
 type Field struct {
@@ -1,6 +1,12 @@
 package logger
 
-import "go.uber.org/zap"
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"runtime"
+	"time"
+)
 
 type ZapLogger struct {
 	l *zap.Logger
@@ -17,21 +23,84 @@ func (z *ZapLogger) Debug(msg string, args ...Field) {
 }
 
 func (z *ZapLogger) Info(msg string, args ...Field) {
-	z.l.Debug(msg, z.toArgs(args)...)
+	z.l.Info(msg, z.toArgs(args)...)
 }
 
 func (z *ZapLogger) Warn(msg string, args ...Field) {
-	z.l.Debug(msg, z.toArgs(args)...)
+	z.l.Warn(msg, z.toArgs(args)...)
 }
 
 func (z *ZapLogger) Error(msg string, args ...Field) {
-	z.l.Debug(msg, z.toArgs(args)...)
+	z.l.Error(msg, z.toArgs(args)...)
+}
+
+func (z *ZapLogger) With(args ...Field) Logger {
+	return NewZapLogger(z.l.With(z.toArgs(args)...))
+}
+
+func (z *ZapLogger) Enabled(level Level) bool {
+	return z.l.Core().Enabled(zapLevel(level))
+}
+
+// LogAt reports pc as the caller of the entry, when the logger adds the callers.
+func (z *ZapLogger) LogAt(level Level, pc uintptr, msg string, args ...Field) {
+	ce := z.l.Check(zapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+	if ce.Caller.Defined && pc != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	ce.Write(z.toArgs(args)...)
+}
+
+func zapLevel(level Level) zapcore.Level {
+	switch level {
+	case DebugLevel:
+		return zapcore.DebugLevel
+	case InfoLevel:
+		return zapcore.InfoLevel
+	case WarnLevel:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
 }
 
 func (z *ZapLogger) toArgs(args []Field) []zap.Field {
 	fields := make([]zap.Field, 0, len(args))
 	for _, arg := range args {
-		fields = append(fields, zap.Any(arg.Key, arg.Value))
+		fields = append(fields, zapField(arg))
 	}
 	return fields
 }
+
+// zapField maps the values of the typed field helpers to their zap fields,
+// sparing them the long type switch of zap.Any.
+func zapField(f Field) zap.Field {
+	switch v := f.Value.(type) {
+	case string:
+		return zap.String(f.Key, v)
+	case int64:
+		return zap.Int64(f.Key, v)
+	case int:
+		return zap.Int(f.Key, v)
+	case int32:
+		return zap.Int32(f.Key, v)
+	case float64:
+		return zap.Float64(f.Key, v)
+	case bool:
+		return zap.Bool(f.Key, v)
+	case time.Duration:
+		return zap.Duration(f.Key, v)
+	case time.Time:
+		return zap.Time(f.Key, v)
+	case error:
+		return zap.NamedError(f.Key, v)
+	case fmt.Stringer:
+		return zap.Stringer(f.Key, v)
+	default:
+		return zap.Any(f.Key, v)
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestZapLogger_Levels(t *testing.T) {
+	testCases := []struct {
+		name  string
+		level zapcore.Level
+
+		wantLevels []zapcore.Level
+	}{
+		{
+			name:       "debug",
+			level:      zapcore.DebugLevel,
+			wantLevels: []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel},
+		},
+		{
+			name:       "info drops debug",
+			level:      zapcore.InfoLevel,
+			wantLevels: []zapcore.Level{zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel},
+		},
+		{
+			name:       "error",
+			level:      zapcore.ErrorLevel,
+			wantLevels: []zapcore.Level{zapcore.ErrorLevel},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(tc.level)
+			l := NewZapLogger(zap.New(core))
+			l.Debug("debug")
+			l.Info("info")
+			l.Warn("warn")
+			l.Error("error", Error(assert.AnError))
+
+			var levels []zapcore.Level
+			for _, e := range logs.AllUntimed() {
+				levels = append(levels, e.Level)
+			}
+			assert.Equal(t, tc.wantLevels, levels)
+		})
+	}
+}
+
+func TestZapLogger_Fields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewZapLogger(zap.New(core))
+	at := time.UnixMilli(1717236000000)
+	l.Info("typed",
+		Duration("latency", time.Second),
+		Time("at", at),
+		Bool("primary", true),
+		Float64("score", 1.5),
+		Stringer("addr", netip.MustParseAddr("10.0.0.1")),
+		Any("ids", []int64{1, 2}))
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 1)
+	types := make(map[string]zapcore.FieldType)
+	for _, f := range entries[0].Context {
+		types[f.Key] = f.Type
+	}
+	assert.Equal(t, map[string]zapcore.FieldType{
+		"latency": zapcore.DurationType,
+		"at":      zapcore.TimeType,
+		"primary": zapcore.BoolType,
+		"score":   zapcore.Float64Type,
+		"addr":    zapcore.StringerType,
+		"ids":     zapcore.ArrayMarshalerType,
+	}, types)
+	assert.Equal(t, map[string]any{
+		"latency": time.Second,
+		"at":      at,
+		"primary": true,
+		"score":   1.5,
+		"addr":    "10.0.0.1",
+		"ids":     []any{int64(1), int64(2)},
+	}, entries[0].ContextMap())
+}
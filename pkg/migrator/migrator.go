@@ -0,0 +1,423 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"gorm.io/gorm"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDirty       = errors.New("database is dirty")
+	ErrLockTimeout = errors.New("timed out acquiring the migration lock")
+	ErrPending     = errors.New("migrations are pending")
+)
+
+const schemaTable = "schema_migrations"
+
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version int64
+	Name    string
+	Applied bool
+	// Dirty means the migration failed half way and needs a manual fix.
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+// Load reads migrations named <version>_<name>.up.sql and <version>_<name>.down.sql
+// from the root of fsys, sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		parts := fileNameRegexp.FindStringSubmatch(e.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+		version, _ := strconv.ParseInt(parts[1], 10, 64)
+		bs, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		}
+		if m.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, m.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			m.Up = string(bs)
+		} else {
+			m.Down = string(bs)
+		}
+	}
+
+	res := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Version < res[j].Version
+	})
+	return res, nil
+}
+
+// Migrator applies versioned SQL migrations and records them in schema_migrations.
+// Every operation holds a database level lock so that replicas booting
+// together do not race each other.
+type Migrator struct {
+	db          *gorm.DB
+	migrations  []Migration
+	steps       []Step
+	l           logger.Logger
+	lockName    string
+	lockTimeout time.Duration
+}
+
+// Step completes the migrations with what plain SQL cannot do, e.g. backfilling generated values.
+// It runs on every Up, so it must be idempotent.
+type Step func(ctx context.Context, conn *gorm.DB) error
+
+func New(db *gorm.DB, migrations []Migration, l logger.Logger) *Migrator {
+	return &Migrator{
+		db:          db,
+		migrations:  migrations,
+		l:           l,
+		lockName:    schemaTable,
+		lockTimeout: time.Minute,
+	}
+}
+
+func (m *Migrator) SetLockTimeout(d time.Duration) {
+	m.lockTimeout = d
+}
+
+// AddStep appends step to the steps Up runs after the migrations, holding the lock.
+func (m *Migrator) AddStep(step Step) {
+	m.steps = append(m.steps, step)
+}
+
+// Up applies every pending migration in version order, then runs the steps, and returns the migrations applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err = m.up(conn, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		for _, step := range m.steps {
+			if err = step(ctx, conn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the last steps applied migrations and returns them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err = m.down(conn, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var res []Status
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		rows, err := m.rows(conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			s := Status{Version: mig.Version, Name: mig.Name}
+			if r, ok := rows[mig.Version]; ok {
+				s.Applied = !r.Dirty
+				s.Dirty = r.Dirty
+				s.AppliedAt = time.UnixMilli(r.AppliedAt)
+			}
+			res = append(res, s)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Pending reports the number of migrations not applied yet.
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cnt := 0
+	for _, s := range statuses {
+		if !s.Applied {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+// Verify fails with ErrPending when a migration is not applied yet, and with ErrDirty
+// when one failed half way. It reads schema_migrations without taking the migration lock,
+// so that it is cheap enough for a readiness probe.
+func (m *Migrator) Verify(ctx context.Context) error {
+	rows, err := m.rows(m.db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, mig := range m.migrations {
+		r, ok := rows[mig.Version]
+		if ok && r.Dirty {
+			return fmt.Errorf("%w at version %d", ErrDirty, mig.Version)
+		}
+		if !ok {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w, %d of %d", ErrPending, pending, len(m.migrations))
+	}
+	return nil
+}
+
+func (m *Migrator) up(conn *gorm.DB, mig Migration) error {
+	m.l.Info("applying migration",
+		logger.Int64("version", mig.Version),
+		logger.String("name", mig.Name))
+	err := conn.Exec("INSERT INTO "+schemaTable+" (version, name, dirty, applied_at) VALUES (?, ?, ?, ?)",
+		mig.Version, mig.Name, true, time.Now().UnixMilli()).Error
+	if err != nil {
+		return err
+	}
+	if err = m.exec(conn, mig.Up); err != nil {
+		return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+	}
+	return conn.Exec("UPDATE "+schemaTable+" SET dirty = ?, applied_at = ? WHERE version = ?",
+		false, time.Now().UnixMilli(), mig.Version).Error
+}
+
+func (m *Migrator) down(conn *gorm.DB, mig Migration) error {
+	if mig.Down == "" {
+		return fmt.Errorf("migration %d_%s is irreversible", mig.Version, mig.Name)
+	}
+	m.l.Info("rolling back migration",
+		logger.Int64("version", mig.Version),
+		logger.String("name", mig.Name))
+	err := conn.Exec("UPDATE "+schemaTable+" SET dirty = ? WHERE version = ?", true, mig.Version).Error
+	if err != nil {
+		return err
+	}
+	if err = m.exec(conn, mig.Down); err != nil {
+		return fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+	}
+	return conn.Exec("DELETE FROM "+schemaTable+" WHERE version = ?", mig.Version).Error
+}
+
+func (m *Migrator) exec(conn *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := conn.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type row struct {
+	Version   int64
+	Dirty     bool
+	AppliedAt int64
+}
+
+func (m *Migrator) rows(conn *gorm.DB) (map[int64]row, error) {
+	var rows []row
+	err := conn.Raw("SELECT version, dirty, applied_at FROM " + schemaTable).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]row, len(rows))
+	for _, r := range rows {
+		res[r.Version] = r
+	}
+	return res, nil
+}
+
+// applied returns the applied versions, it fails if any of them is dirty.
+func (m *Migrator) applied(conn *gorm.DB) (map[int64]row, error) {
+	rows, err := m.rows(conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		if r.Dirty {
+			return nil, fmt.Errorf("%w at version %d, fix the schema manually and delete the row from %s",
+				ErrDirty, r.Version, schemaTable)
+		}
+	}
+	return rows, nil
+}
+
+// withLock runs fn on a single connection holding the migration lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := m.lock(conn); err != nil {
+			return err
+		}
+		defer func() {
+			if err := m.unlock(conn); err != nil {
+				m.l.Error("failed to release migration lock", logger.Error(err))
+			}
+		}()
+
+		err := conn.Exec("CREATE TABLE IF NOT EXISTS " + schemaTable + " (" +
+			"version BIGINT NOT NULL PRIMARY KEY, " +
+			"name VARCHAR(255) NOT NULL, " +
+			"dirty BOOLEAN NOT NULL, " +
+			"applied_at BIGINT NOT NULL)").Error
+		if err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) lock(conn *gorm.DB) error {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		var ok sql.NullInt64
+		err := conn.Raw("SELECT GET_LOCK(?, ?)", m.lockName, int(m.lockTimeout.Seconds())).Row().Scan(&ok)
+		if err != nil {
+			return err
+		}
+		if !ok.Valid || ok.Int64 != 1 {
+			return ErrLockTimeout
+		}
+		return nil
+	case "postgres":
+		// pg_advisory_lock cannot time out, poll the non-blocking variant instead
+		deadline := time.Now().Add(m.lockTimeout)
+		for {
+			var ok bool
+			err := conn.Raw("SELECT pg_try_advisory_lock(?)", m.lockKey()).Row().Scan(&ok)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return ErrLockTimeout
+			}
+			select {
+			case <-conn.Statement.Context.Done():
+				return conn.Statement.Context.Err()
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+	case "sqlite":
+		// sqlite serialises writers on the database file, there is no replica to race with
+		return nil
+	default:
+		return fmt.Errorf("migrations are not supported on %s", conn.Dialector.Name())
+	}
+}
+
+func (m *Migrator) unlock(conn *gorm.DB) error {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		return conn.Exec("SELECT RELEASE_LOCK(?)", m.lockName).Error
+	case "postgres":
+		return conn.Exec("SELECT pg_advisory_unlock(?)", m.lockKey()).Error
+	default:
+		return nil
+	}
+}
+
+// lockKey derives the postgres advisory lock key from the lock name.
+func (m *Migrator) lockKey() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(m.lockName))
+	return int64(h.Sum64())
+}
+
+// splitStatements splits a script on semicolons terminating a line
+// and drops comment lines.
+func splitStatements(script string) []string {
+	var (
+		res []string
+		sb  strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			res = append(res, strings.TrimSuffix(strings.TrimSpace(sb.String()), ";"))
+			sb.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(sb.String()); rest != "" {
+		res = append(res, rest)
+	}
+	return res
+}
@@ -0,0 +1,305 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name string
+		fsys fstest.MapFS
+
+		wantRet []Migration
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"0002_add_index.up.sql":      {Data: []byte("up 2")},
+				"0002_add_index.down.sql":    {Data: []byte("down 2")},
+				"0001_create_coins.up.sql":   {Data: []byte("up 1")},
+				"0001_create_coins.down.sql": {Data: []byte("down 1")},
+				"migrations.go":              {Data: []byte("package migrations")},
+			},
+			wantRet: []Migration{
+				{Version: 1, Name: "create_coins", Up: "up 1", Down: "down 1"},
+				{Version: 2, Name: "add_index", Up: "up 2", Down: "down 2"},
+			},
+		},
+		{
+			name: "invalid name",
+			fsys: fstest.MapFS{
+				"create_coins.up.sql": {Data: []byte("up")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing up",
+			fsys: fstest.MapFS{
+				"0001_create_coins.down.sql": {Data: []byte("down")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"0001_create_coins.up.sql": {Data: []byte("up")},
+				"0001_create_token.up.sql": {Data: []byte("up")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := Load(tc.fsys)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantRet, ret)
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment
+CREATE TABLE a (
+    id BIGINT
+);
+
+ALTER TABLE a ADD COLUMN b INT;
+DROP TABLE c`
+	assert.Equal(t, []string{
+		"CREATE TABLE a (\n    id BIGINT\n)",
+		"ALTER TABLE a ADD COLUMN b INT",
+		"DROP TABLE c",
+	}, splitStatements(script))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "create_coins", Up: "CREATE TABLE coins (id BIGINT);"},
+		{Version: 2, Name: "add_index", Up: "CREATE INDEX idx_a ON coins (id);\nCREATE INDEX idx_b ON coins (id);"},
+	}
+	testCases := []struct {
+		name    string
+		sqlmock func(t *testing.T) *sql.DB
+		step    Step
+
+		wantApplied []int64
+		wantErr     error
+	}{
+		{
+			name: "apply pending",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+					WithArgs("schema_migrations", 60).
+					WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(1))
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty, applied_at FROM schema_migrations")).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty", "applied_at"}).AddRow(1, false, 1))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, ?, ?)")).
+					WithArgs(2, "add_index", true, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_a ON coins (id)")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_b ON coins (id)")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE schema_migrations SET dirty = ?, applied_at = ? WHERE version = ?")).
+					WithArgs(false, sqlmock.AnyArg(), 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE coins SET id = id")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			step: func(ctx context.Context, conn *gorm.DB) error {
+				return conn.Exec("UPDATE coins SET id = id").Error
+			},
+			wantApplied: []int64{2},
+		},
+		{
+			name: "step failed",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+					WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(1))
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty, applied_at FROM schema_migrations")).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty", "applied_at"}).
+						AddRow(1, false, 1).AddRow(2, false, 1))
+				mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			step: func(ctx context.Context, conn *gorm.DB) error {
+				return errors.New("mock step error")
+			},
+			wantErr: errors.New("mock step error"),
+		},
+		{
+			name: "dirty database",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+					WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(1))
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty, applied_at FROM schema_migrations")).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty", "applied_at"}).AddRow(1, true, 1))
+				mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			wantErr: ErrDirty,
+		},
+		{
+			name: "lock timeout",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+					WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(0))
+				return db
+			},
+			wantErr: ErrLockTimeout,
+		},
+		{
+			name: "migration failed",
+			sqlmock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+					WillReturnRows(sqlmock.NewRows([]string{"ok"}).AddRow(1))
+				mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty, applied_at FROM schema_migrations")).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty", "applied_at"}))
+				mock.ExpectExec("INSERT INTO schema_migrations .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE coins (id BIGINT)")).
+					WillReturnError(errors.New("mock db error"))
+				mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return db
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlmock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+
+			m := New(db, migrations, logger.NewNopLogger())
+			if tc.step != nil {
+				m.AddStep(tc.step)
+			}
+			done, err := m.Up(context.Background())
+			if tc.wantErr != nil {
+				assert.ErrorContains(t, err, tc.wantErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			versions := make([]int64, 0, len(done))
+			for _, mig := range done {
+				versions = append(versions, mig.Version)
+			}
+			assert.Equal(t, tc.wantApplied, versions)
+		})
+	}
+}
+
+func TestMigrator_Verify(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "create_coins", Up: "CREATE TABLE coins (id BIGINT)"},
+		{Version: 2, Name: "add_index", Up: "CREATE INDEX idx_b ON coins (id)"},
+	}
+	testCases := []struct {
+		name string
+		rows *sqlmock.Rows
+		err  error
+
+		wantErr error
+	}{
+		{
+			name: "up to date",
+			rows: sqlmock.NewRows([]string{"version", "dirty", "applied_at"}).
+				AddRow(1, false, 1).AddRow(2, false, 1),
+		},
+		{
+			name: "pending",
+			rows: sqlmock.NewRows([]string{"version", "dirty", "applied_at"}).
+				AddRow(1, false, 1),
+			wantErr: ErrPending,
+		},
+		{
+			name: "dirty",
+			rows: sqlmock.NewRows([]string{"version", "dirty", "applied_at"}).
+				AddRow(1, false, 1).AddRow(2, true, 1),
+			wantErr: ErrDirty,
+		},
+		{
+			name:    "never migrated",
+			err:     errors.New("table schema_migrations doesn't exist"),
+			wantErr: errors.New("table schema_migrations doesn't exist"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			// no lock is taken
+			query := mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty, applied_at FROM schema_migrations"))
+			if tc.err != nil {
+				query.WillReturnError(tc.err)
+			} else {
+				query.WillReturnRows(tc.rows)
+			}
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+
+			err = New(db, migrations, logger.NewNopLogger()).Verify(context.Background())
+			if tc.wantErr != nil {
+				assert.ErrorContains(t, err, tc.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package readreplica
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// LagFunc reports how far a replica is behind its primary.
+type LagFunc func(ctx context.Context, db *sql.DB) (time.Duration, error)
+
+// MySQLLag reads Seconds_Behind_Source from SHOW REPLICA STATUS.
+// A server which is not replicating reports no lag.
+func MySQLLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	vals := make([]sql.RawBytes, len(cols))
+	dest := make([]any, len(cols))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	if err = rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+	for i, col := range cols {
+		if col != "Seconds_Behind_Source" && col != "Seconds_Behind_Master" {
+			continue
+		}
+		if vals[i] == nil {
+			return 0, errors.New("replication is not running")
+		}
+		var secs sql.NullInt64
+		if err = secs.Scan(string(vals[i])); err != nil {
+			return 0, err
+		}
+		return time.Duration(secs.Int64) * time.Second, nil
+	}
+	return 0, errors.New("replica status has no lag column")
+}
+
+// PostgresLag reports the age of the last replayed transaction,
+// zero when everything received has been replayed or the server is not a standby.
+func PostgresLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var secs float64
+	err := db.QueryRowContext(ctx, "SELECT CASE "+
+		"WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 "+
+		"ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END").Scan(&secs)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
@@ -0,0 +1,66 @@
+package readreplica
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMySQLLag(t *testing.T) {
+	testCases := []struct {
+		name string
+		rows *sqlmock.Rows
+
+		wantLag time.Duration
+		wantErr bool
+	}{
+		{
+			name: "replicating",
+			rows: sqlmock.NewRows([]string{"Replica_IO_State", "Seconds_Behind_Source"}).
+				AddRow("Waiting for source to send event", "3"),
+			wantLag: 3 * time.Second,
+		},
+		{
+			name:    "not a replica",
+			rows:    sqlmock.NewRows([]string{"Replica_IO_State", "Seconds_Behind_Source"}),
+			wantLag: 0,
+		},
+		{
+			name: "replication stopped",
+			rows: sqlmock.NewRows([]string{"Replica_IO_State", "Seconds_Behind_Source"}).
+				AddRow("", nil),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			mock.ExpectQuery(regexp.QuoteMeta("SHOW REPLICA STATUS")).WillReturnRows(tc.rows)
+
+			lag, err := MySQLLag(context.Background(), db)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantLag, lag)
+		})
+	}
+}
+
+func TestPostgresLag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectQuery("SELECT CASE .*pg_last_xact_replay_timestamp.*").
+		WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(1.5))
+
+	lag, err := PostgresLag(context.Background(), db)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, lag)
+}
@@ -0,0 +1,182 @@
+package readreplica
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/miles0wu/meme-coin-api/pkg/health"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"gorm.io/gorm"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Replica struct {
+	Name string
+	DB   *sql.DB
+}
+
+type Config struct {
+	// Interval is the period of the replica health checks.
+	Interval time.Duration
+	// Timeout bounds the check of a single replica.
+	Timeout time.Duration
+	// MaxLag excludes replicas lagging further behind the primary, zero disables the lag check.
+	MaxLag time.Duration
+}
+
+type replicaState struct {
+	Replica
+	healthy bool
+	lag     time.Duration
+	err     error
+}
+
+// Policy is a dbresolver policy routing reads to the healthy replicas in turn.
+// The primary must be registered as the last replica so that reads fall back
+// to it when no replica is healthy, this also keeps dbresolver from bypassing
+// the policy when a single replica is configured.
+//
+// Replicas start excluded until the first Refresh.
+type Policy struct {
+	cfg Config
+	lag LagFunc
+	l   logger.Logger
+
+	mu       sync.RWMutex
+	replicas []replicaState
+	next     atomic.Uint64
+}
+
+// NewPolicy creates a policy over replicas, in the order they are registered in dbresolver.
+// lag may be nil when the driver has no replication lag to measure.
+func NewPolicy(replicas []Replica, lag LagFunc, cfg Config, l logger.Logger) *Policy {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+	states := make([]replicaState, 0, len(replicas))
+	for _, r := range replicas {
+		states = append(states, replicaState{Replica: r})
+	}
+	return &Policy{
+		cfg:      cfg,
+		lag:      lag,
+		l:        l,
+		replicas: states,
+	}
+}
+
+func (p *Policy) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	primary := pools[len(pools)-1]
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(pools) != len(p.replicas)+1 {
+		// registered differently from the policy, do not guess
+		return primary
+	}
+	healthy := make([]int, 0, len(p.replicas))
+	for i, r := range p.replicas {
+		if r.healthy {
+			healthy = append(healthy, i)
+		}
+	}
+	if len(healthy) == 0 {
+		return primary
+	}
+	return pools[healthy[p.next.Add(1)%uint64(len(healthy))]]
+}
+
+// Run refreshes the replica states every interval until ctx is done.
+func (p *Policy) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh checks every replica and excludes the unreachable or lagging ones.
+func (p *Policy) Refresh(ctx context.Context) {
+	p.mu.RLock()
+	replicas := make([]Replica, 0, len(p.replicas))
+	for _, r := range p.replicas {
+		replicas = append(replicas, r.Replica)
+	}
+	p.mu.RUnlock()
+
+	for i, r := range replicas {
+		lag, err := p.check(ctx, r.DB)
+
+		p.mu.Lock()
+		state := &p.replicas[i]
+		wasHealthy := state.healthy
+		state.healthy, state.lag, state.err = err == nil, lag, err
+		p.mu.Unlock()
+
+		switch {
+		case wasHealthy && err != nil:
+			p.l.Warn("read replica excluded",
+				logger.String("replica", r.Name),
+				logger.Error(err))
+		case !wasHealthy && err == nil:
+			p.l.Info("read replica included",
+				logger.String("replica", r.Name),
+				logger.Int64("lag_ms", lag.Milliseconds()))
+		}
+	}
+}
+
+func (p *Policy) check(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return 0, err
+	}
+	if p.lag == nil || p.cfg.MaxLag <= 0 {
+		return 0, nil
+	}
+	lag, err := p.lag(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	if lag > p.cfg.MaxLag {
+		return lag, fmt.Errorf("replication lag %s exceeds %s", lag, p.cfg.MaxLag)
+	}
+	return lag, nil
+}
+
+func (p *Policy) Name() string {
+	return "db_replicas"
+}
+
+// Check reports degraded when a replica is excluded, reads still succeed on the primary.
+func (p *Policy) Check(ctx context.Context) health.Report {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	report := health.Report{Status: health.StatusUp}
+	detail := make(map[string]any, len(p.replicas))
+	for _, r := range p.replicas {
+		d := map[string]any{
+			"healthy": r.healthy,
+			"lagMs":   r.lag.Milliseconds(),
+		}
+		if r.err != nil {
+			d["error"] = r.err.Error()
+		}
+		if !r.healthy {
+			report.Status = health.StatusDegraded
+		}
+		detail[r.Name] = d
+	}
+	report.Detail = detail
+	return report
+}
@@ -0,0 +1,99 @@
+package readreplica
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/miles0wu/meme-coin-api/pkg/health"
+	"github.com/miles0wu/meme-coin-api/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestPolicy_Resolve(t *testing.T) {
+	testCases := []struct {
+		name string
+		// pings of replica 1 and 2, nil means reachable
+		pings []error
+		lags  []time.Duration
+
+		wantPools  []int
+		wantStatus health.Status
+	}{
+		{
+			name:       "round robin over healthy replicas",
+			pings:      []error{nil, nil},
+			lags:       []time.Duration{0, time.Second},
+			wantPools:  []int{1, 0, 1},
+			wantStatus: health.StatusUp,
+		},
+		{
+			name:       "exclude unreachable replica",
+			pings:      []error{errors.New("connection refused"), nil},
+			lags:       []time.Duration{0},
+			wantPools:  []int{1, 1},
+			wantStatus: health.StatusDegraded,
+		},
+		{
+			name:       "exclude lagging replica",
+			pings:      []error{nil, nil},
+			lags:       []time.Duration{time.Minute, 0},
+			wantPools:  []int{1, 1},
+			wantStatus: health.StatusDegraded,
+		},
+		{
+			name:       "fall back to primary",
+			pings:      []error{errors.New("connection refused"), nil},
+			lags:       []time.Duration{time.Minute},
+			wantPools:  []int{2, 2},
+			wantStatus: health.StatusDegraded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				replicas []Replica
+				pools    []gorm.ConnPool
+			)
+			for i, ping := range tc.pings {
+				db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+				require.NoError(t, err)
+				mock.ExpectPing().WillReturnError(ping)
+				replicas = append(replicas, Replica{Name: string(rune('a' + i)), DB: db})
+				pools = append(pools, db)
+			}
+			primary, _, err := sqlmock.New()
+			require.NoError(t, err)
+			pools = append(pools, primary)
+
+			lags := tc.lags
+			lag := func(ctx context.Context, db *sql.DB) (time.Duration, error) {
+				res := lags[0]
+				lags = lags[1:]
+				return res, nil
+			}
+			p := NewPolicy(replicas, lag, Config{MaxLag: 5 * time.Second}, logger.NewNopLogger())
+			p.Refresh(context.Background())
+
+			for _, want := range tc.wantPools {
+				assert.Same(t, pools[want], p.Resolve(pools))
+			}
+			assert.Equal(t, tc.wantStatus, p.Check(context.Background()).Status)
+		})
+	}
+}
+
+func TestPolicy_ResolveBeforeRefresh(t *testing.T) {
+	replica, _, err := sqlmock.New()
+	require.NoError(t, err)
+	primary, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	p := NewPolicy([]Replica{{Name: "a", DB: replica}}, nil, Config{}, logger.NewNopLogger())
+	assert.Same(t, primary, p.Resolve([]gorm.ConnPool{replica, primary}))
+}
@@ -0,0 +1,31 @@
+package readreplica
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type sessionKey struct{}
+
+type session struct {
+	written atomic.Bool
+}
+
+// NewSession returns a context tracking the writes of a request,
+// once a write is recorded the following reads go to the primary.
+func NewSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// MarkWritten records a write on the session of ctx, if any.
+func MarkWritten(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.written.Store(true)
+	}
+}
+
+// UsePrimary reports whether reads of ctx must go to the primary to observe its own writes.
+func UsePrimary(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	return ok && s.written.Load()
+}
@@ -0,0 +1,19 @@
+package readreplica
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	ctx := NewSession(context.Background())
+	assert.False(t, UsePrimary(ctx))
+	MarkWritten(ctx)
+	assert.True(t, UsePrimary(ctx))
+
+	// a write without a session is not remembered
+	ctx = context.Background()
+	MarkWritten(ctx)
+	assert.False(t, UsePrimary(ctx))
+}
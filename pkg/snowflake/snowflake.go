@@ -0,0 +1,63 @@
+package snowflake
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+
+	MaxNode     = 1<<nodeBits - 1
+	maxSequence = 1<<sequenceBits - 1
+)
+
+// Epoch is the start of the timestamps of the ids, 2024-01-01 UTC.
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Node issues 63 bit ids ordered by time: 41 bits of milliseconds since Epoch,
+// 10 bits of node and 12 bits of sequence. Every process generating ids
+// concurrently must use a distinct node.
+type Node struct {
+	node int64
+	now  func() time.Time
+
+	mu       sync.Mutex
+	lastMs   int64
+	sequence int64
+}
+
+func NewNode(node int64) (*Node, error) {
+	if node < 0 || node > MaxNode {
+		return nil, fmt.Errorf("snowflake node %d out of range [0, %d]", node, MaxNode)
+	}
+	return &Node{
+		node: node,
+		now:  time.Now,
+	}, nil
+}
+
+// NextId never blocks: when the clock goes backwards or the sequence of a millisecond
+// is exhausted, it keeps counting on a logical clock slightly ahead of the wall clock.
+func (n *Node) NextId() int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ms := n.now().Sub(Epoch).Milliseconds()
+	switch {
+	case ms > n.lastMs:
+		n.lastMs, n.sequence = ms, 0
+	case n.sequence < maxSequence:
+		n.sequence++
+	default:
+		n.lastMs, n.sequence = n.lastMs+1, 0
+	}
+	return n.lastMs<<(nodeBits+sequenceBits) | n.node<<sequenceBits | n.sequence
+}
+
+// Time returns the time an id was generated at.
+func Time(id int64) time.Time {
+	return Epoch.Add(time.Duration(id>>(nodeBits+sequenceBits)) * time.Millisecond)
+}
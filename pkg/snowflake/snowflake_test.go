@@ -0,0 +1,110 @@
+package snowflake
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewNode(t *testing.T) {
+	_, err := NewNode(-1)
+	assert.Error(t, err)
+	_, err = NewNode(MaxNode + 1)
+	assert.Error(t, err)
+	_, err = NewNode(MaxNode)
+	assert.NoError(t, err)
+}
+
+func TestNode_NextId(t *testing.T) {
+	now := Epoch.Add(time.Hour)
+	testCases := []struct {
+		name string
+		// clock returns the time seen by each call
+		clock []time.Time
+
+		wantIds []int64
+	}{
+		{
+			name:  "new millisecond resets the sequence",
+			clock: []time.Time{now, now.Add(time.Millisecond)},
+			wantIds: []int64{
+				3600000<<22 | 7<<12,
+				3600001<<22 | 7<<12,
+			},
+		},
+		{
+			name:  "same millisecond increments the sequence",
+			clock: []time.Time{now, now},
+			wantIds: []int64{
+				3600000<<22 | 7<<12,
+				3600000<<22 | 7<<12 | 1,
+			},
+		},
+		{
+			name:  "clock going backwards keeps increasing",
+			clock: []time.Time{now, now.Add(-time.Second)},
+			wantIds: []int64{
+				3600000<<22 | 7<<12,
+				3600000<<22 | 7<<12 | 1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := NewNode(7)
+			require.NoError(t, err)
+			clock := tc.clock
+			n.now = func() time.Time {
+				res := clock[0]
+				clock = clock[1:]
+				return res
+			}
+			for _, want := range tc.wantIds {
+				assert.Equal(t, want, n.NextId())
+			}
+		})
+	}
+}
+
+func TestNode_SequenceExhausted(t *testing.T) {
+	now := Epoch.Add(time.Hour)
+	n, err := NewNode(1)
+	require.NoError(t, err)
+	n.now = func() time.Time {
+		return now
+	}
+	var last int64
+	for i := 0; i <= maxSequence+1; i++ {
+		id := n.NextId()
+		assert.Greater(t, id, last)
+		last = id
+	}
+	assert.Equal(t, now.Add(time.Millisecond), Time(last))
+}
+
+func TestNode_Concurrent(t *testing.T) {
+	n, err := NewNode(1)
+	require.NoError(t, err)
+	var (
+		mu   sync.Mutex
+		seen = make(map[int64]struct{})
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				id := n.NextId()
+				mu.Lock()
+				seen[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 8000)
+}
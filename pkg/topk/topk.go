@@ -0,0 +1,78 @@
+// Package topk tracks the most frequent keys of a stream in bounded memory.
+package topk
+
+import (
+	"sort"
+	"sync"
+)
+
+type Entry struct {
+	Key   string
+	Count int64
+}
+
+// Counter is the Space-Saving algorithm. It counts at most capacity keys, a new key replaces
+// the least counted one and inherits its count, so the count of a key may be overestimated by
+// at most the smallest count tracked. The keys counted more often than that are never missed.
+type Counter struct {
+	mu       sync.Mutex
+	capacity int
+	counts   map[string]int64
+}
+
+func NewCounter(capacity int) *Counter {
+	return &Counter{
+		capacity: capacity,
+		counts:   make(map[string]int64, capacity),
+	}
+}
+
+func (c *Counter) Add(key string, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.counts[key]; ok || len(c.counts) < c.capacity {
+		c.counts[key] += n
+		return
+	}
+	minKey, minCount := "", int64(0)
+	for k, cnt := range c.counts {
+		if minKey == "" || cnt < minCount {
+			minKey, minCount = k, cnt
+		}
+	}
+	delete(c.counts, minKey)
+	c.counts[key] = minCount + n
+}
+
+// Top returns the k most counted keys, the most counted first.
+func (c *Counter) Top(k int) []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.top(k)
+}
+
+// Flush returns the k most counted keys like Top, and starts counting over.
+func (c *Counter) Flush(k int) []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := c.top(k)
+	c.counts = make(map[string]int64, c.capacity)
+	return res
+}
+
+func (c *Counter) top(k int) []Entry {
+	res := make([]Entry, 0, len(c.counts))
+	for key, cnt := range c.counts {
+		res = append(res, Entry{Key: key, Count: cnt})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Key < res[j].Key
+	})
+	if len(res) > k {
+		res = res[:k]
+	}
+	return res
+}
@@ -0,0 +1,64 @@
+package topk
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		capacity int
+		keys     []string
+		k        int
+
+		want []Entry
+	}{
+		{
+			name:     "within capacity",
+			capacity: 3,
+			keys:     []string{"a", "b", "a", "c", "a", "b"},
+			k:        2,
+			want:     []Entry{{Key: "a", Count: 3}, {Key: "b", Count: 2}},
+		},
+		{
+			name:     "fewer keys than k",
+			capacity: 3,
+			keys:     []string{"a", "b", "b"},
+			k:        5,
+			want:     []Entry{{Key: "b", Count: 2}, {Key: "a", Count: 1}},
+		},
+		{
+			name:     "new key replaces the least counted",
+			capacity: 2,
+			keys:     []string{"a", "a", "a", "b", "c"},
+			k:        2,
+			// c inherits the count of b
+			want: []Entry{{Key: "a", Count: 3}, {Key: "c", Count: 2}},
+		},
+		{
+			name:     "frequent key survives the noise",
+			capacity: 2,
+			keys:     []string{"a", "x1", "a", "x2", "a", "x3", "a", "x4"},
+			k:        1,
+			want:     []Entry{{Key: "a", Count: 4}},
+		},
+		{
+			name:     "empty",
+			capacity: 2,
+			k:        2,
+			want:     []Entry{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewCounter(tc.capacity)
+			for _, key := range tc.keys {
+				c.Add(key, 1)
+			}
+			assert.Equal(t, tc.want, c.Top(tc.k))
+			assert.Equal(t, tc.want, c.Flush(tc.k))
+			assert.Empty(t, c.Top(tc.k))
+		})
+	}
+}
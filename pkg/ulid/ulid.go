@@ -0,0 +1,67 @@
+package ulid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Length is the length of an encoded ULID.
+const Length = 26
+
+// alphabet is the Crockford base32 alphabet, it sorts like the values it encodes.
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// New returns a ULID of the current time, see https://github.com/ulid/spec.
+// It is 48 bits of milliseconds since the Unix epoch followed by 80 random bits,
+// so ULIDs sort by creation time and cannot be guessed from each other.
+func New() string {
+	return MustNewAt(time.Now(), rand.Reader)
+}
+
+// MustNewAt returns a ULID of t with randomness read from entropy, it panics when entropy fails.
+func MustNewAt(t time.Time, entropy io.Reader) string {
+	var b [16]byte
+	binary.BigEndian.PutUint16(b[0:2], uint16(uint64(t.UnixMilli())>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(t.UnixMilli()))
+	if _, err := io.ReadFull(entropy, b[6:]); err != nil {
+		panic(err)
+	}
+	return encode(b)
+}
+
+// encode writes the 128 bits of b as 26 characters of 5 bits, with 2 leading zero bits.
+func encode(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[0:8])
+	lo := binary.BigEndian.Uint64(b[8:16])
+	res := make([]byte, Length)
+	for i := Length - 1; i >= 0; i-- {
+		res[i] = alphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(res)
+}
+
+// Valid reports whether s is an encoded ULID in upper case.
+func Valid(s string) bool {
+	if len(s) != Length || s[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isAlphabet(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphabet(c byte) bool {
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package ulid
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMustNewAt(t *testing.T) {
+	testCases := []struct {
+		name    string
+		t       time.Time
+		entropy []byte
+
+		want string
+	}{
+		{
+			name:    "zero",
+			t:       time.UnixMilli(0),
+			entropy: make([]byte, 10),
+			want:    "00000000000000000000000000",
+		},
+		{
+			name:    "max",
+			t:       time.UnixMilli(1<<48 - 1),
+			entropy: bytes.Repeat([]byte{0xff}, 10),
+			want:    "7ZZZZZZZZZZZZZZZZZZZZZZZZZ",
+		},
+		{
+			name:    "time and entropy",
+			t:       time.UnixMilli(1469918176385),
+			entropy: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			want:    "01ARYZ6S410000000000000001",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id := MustNewAt(tc.t, bytes.NewReader(tc.entropy))
+			assert.Equal(t, tc.want, id)
+			assert.True(t, Valid(id))
+		})
+	}
+}
+
+func TestMustNewAt_EntropyFailed(t *testing.T) {
+	assert.Panics(t, func() {
+		MustNewAt(time.Now(), bytes.NewReader(nil))
+	})
+}
+
+func TestNew_Sortable(t *testing.T) {
+	now := time.Now()
+	ids := []string{
+		MustNewAt(now.Add(2*time.Millisecond), bytes.NewReader(make([]byte, 10))),
+		MustNewAt(now, bytes.NewReader(bytes.Repeat([]byte{0xff}, 10))),
+		MustNewAt(now.Add(time.Millisecond), bytes.NewReader(make([]byte, 10))),
+	}
+	sort.Strings(ids)
+	assert.Equal(t, MustNewAt(now, bytes.NewReader(bytes.Repeat([]byte{0xff}, 10))), ids[0])
+	assert.NotEqual(t, New(), New())
+}
+
+func TestValid(t *testing.T) {
+	testCases := []struct {
+		name string
+		s    string
+
+		want bool
+	}{
+		{name: "valid", s: "01ARYZ6S410000000000000001", want: true},
+		{name: "too short", s: "01ARYZ6S41000000000000000"},
+		{name: "too long", s: "01ARYZ6S4100000000000000011"},
+		{name: "overflow", s: "81ARYZ6S410000000000000001"},
+		{name: "lower case", s: "01aryz6s410000000000000001"},
+		{name: "excluded letter", s: "01ARYZ6S41000000000000000U"},
+		{name: "sequential id", s: "1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Valid(tc.s))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/pflag"
+	"io"
+)
+
+const scoreUsage = "usage: score recompute"
+
+// runScore implements the score subcommand.
+func runScore(build func() *CLI, args []string) error {
+	if len(args) == 0 || args[0] != "recompute" {
+		return errors.New(scoreUsage)
+	}
+	flags := pflag.NewFlagSet("score recompute", pflag.ContinueOnError)
+	output := outputFlag(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w, %s", err, scoreUsage)
+	}
+
+	// the refresh of the server, run once, it decays the hot scores of every coin to now
+	n, err := build().refresher.Refresh(context.Background())
+	if err != nil {
+		return err
+	}
+	res := struct {
+		Updated int64 `json:"updated"`
+	}{n}
+	return printOutput(*output, res, func(w io.Writer) {
+		_, _ = fmt.Fprintf(w, "recomputed the hot scores of %d coins\n", n)
+	})
+}
@@ -12,25 +12,106 @@ import (
 	"github.com/miles0wu/meme-coin-api/internal/service"
 	"github.com/miles0wu/meme-coin-api/internal/web"
 	"github.com/miles0wu/meme-coin-api/ioc"
+	"github.com/miles0wu/meme-coin-api/pkg/migrator"
+	"github.com/miles0wu/meme-coin-api/pkg/snowflake"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 )
 
 var thirdPartySet = wire.NewSet(
 	ioc.InitLogger,
 	ioc.InitDB,
+	ioc.InitShards,
+	ioc.InitReadReplicas,
 	ioc.InitRedis,
+	ioc.InitIdGenerator,
+	wire.Bind(new(dao.IdGenerator), new(*snowflake.Node)),
+	ioc.InitMetricsRegistry,
+	ioc.InitTracerProvider,
+	ioc.InitRuntimeConfig,
+	wire.Bind(new(prometheus.Registerer), new(*prometheus.Registry)),
 )
 
-func InitApp() *App {
+func InitApp(l *zap.Logger, level zap.AtomicLevel, cfg *ioc.Config) *App {
 	wire.Build(
 		thirdPartySet,
-		dao.NewGormCoinDAO,
-		cache.NewRedisCoinCache,
+		ioc.InitCoinDAO,
+		ioc.InitCoinAuditDAO,
+		ioc.InitOutboxDAO,
+		ioc.InitPokeRollupDAO,
+		ioc.InitHotScoreModel,
+		dao.NewGormTransactor,
+		wire.Bind(new(repository.Transactor), new(dao.Transactor)),
+		ioc.InitRedisCoinCache,
+		ioc.InitCoinCache,
+		wire.Bind(new(cache.CoinCache), new(*cache.BreakerCoinCache)),
 		repository.NewCachedCoinRepository,
-		service.NewCoinService,
-		web.NewCoinHandler,
+		wire.Bind(new(repository.CoinRepository), new(*repository.CachedCoinRepository)),
+		repository.NewCoinAuditRepository,
+		repository.NewOutboxRepository,
+		repository.NewScoreHistoryRepository,
+		ioc.InitPokeRateReporter,
+		ioc.InitCoinService,
+		service.NewScoreHistoryService,
+		ioc.InitScoreCompactor,
+		ioc.InitHotScoreRefresher,
+		ioc.InitCacheWarmer,
+		ioc.InitCoinHandler,
+		web.NewScoreHistoryHandler,
+		ioc.InitHealthCheckers,
+		ioc.InitHealthHandler,
+		ioc.InitAdminHandler,
 		ioc.InitGinMiddlewares,
 		ioc.InitWebServer,
+		ioc.InitCertReloader,
+		ioc.InitMetricsServer,
+		ioc.InitJobs,
 		wire.Struct(new(App), "*"),
 	)
 	return &App{}
 }
+
+// InitCLI builds the services of the admin commands on the primary databases, without migrating them
+// or starting the background jobs.
+func InitCLI(l *zap.Logger, level zap.AtomicLevel, cfg *ioc.Config) *CLI {
+	wire.Build(
+		ioc.InitLogger,
+		ioc.OpenDB,
+		ioc.OpenShards,
+		ioc.InitRedis,
+		ioc.InitCLIIdGenerator,
+		wire.Bind(new(dao.IdGenerator), new(*snowflake.Node)),
+		ioc.InitMetricsRegistry,
+		wire.Bind(new(prometheus.Registerer), new(*prometheus.Registry)),
+		ioc.NewRuntimeConfig,
+		ioc.InitCoinDAO,
+		ioc.InitCoinAuditDAO,
+		ioc.InitOutboxDAO,
+		ioc.InitHotScoreModel,
+		dao.NewGormTransactor,
+		wire.Bind(new(repository.Transactor), new(dao.Transactor)),
+		ioc.InitRedisCoinCache,
+		ioc.InitCoinCache,
+		wire.Bind(new(cache.CoinCache), new(*cache.BreakerCoinCache)),
+		repository.NewCachedCoinRepository,
+		wire.Bind(new(repository.CoinRepository), new(*repository.CachedCoinRepository)),
+		repository.NewCoinAuditRepository,
+		repository.NewOutboxRepository,
+		ioc.InitPokeRateReporter,
+		ioc.InitCoinService,
+		ioc.NewCacheWarmer,
+		ioc.InitHotScoreRefresher,
+		wire.Struct(new(CLI), "*"),
+	)
+	return &CLI{}
+}
+
+func InitMigrators(l *zap.Logger, cfg *ioc.Config) []*migrator.Migrator {
+	wire.Build(
+		ioc.InitLogger,
+		ioc.OpenDB,
+		ioc.OpenShards,
+		ioc.InitMigrators,
+	)
+	return nil
+}
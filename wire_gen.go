@@ -9,32 +9,110 @@ package main
 import (
 	"github.com/google/wire"
 	"github.com/miles0wu/meme-coin-api/internal/repository"
-	"github.com/miles0wu/meme-coin-api/internal/repository/cache"
 	"github.com/miles0wu/meme-coin-api/internal/repository/dao"
 	"github.com/miles0wu/meme-coin-api/internal/service"
 	"github.com/miles0wu/meme-coin-api/internal/web"
 	"github.com/miles0wu/meme-coin-api/ioc"
+	"github.com/miles0wu/meme-coin-api/pkg/migrator"
+	"github.com/miles0wu/meme-coin-api/pkg/snowflake"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 )
 
 // Injectors from wire.go:
 
-func InitApp() *App {
-	v := ioc.InitGinMiddlewares()
-	logger := ioc.InitLogger()
-	db := ioc.InitDB(logger)
-	coinDAO := dao.NewGormCoinDAO(db, logger)
-	cmdable := ioc.InitRedis()
-	coinCache := cache.NewRedisCoinCache(cmdable)
-	coinRepository := repository.NewCachedCoinRepository(coinDAO, coinCache, logger)
-	coinService := service.NewCoinService(coinRepository)
-	coinHandler := web.NewCoinHandler(coinService, logger)
-	engine := ioc.InitWebServer(v, coinHandler)
+func InitApp(l *zap.Logger, level zap.AtomicLevel, cfg *ioc.Config) *App {
+	registry := ioc.InitMetricsRegistry()
+	v := ioc.InitGinMiddlewares(registry, l)
+	logger := ioc.InitLogger(l)
+	db := ioc.InitDB(cfg, registry, logger)
+	v2 := ioc.InitShards(db, cfg, registry, logger)
+	node := ioc.InitIdGenerator(cfg, logger)
+	model := ioc.InitHotScoreModel(cfg)
+	coinDAO := ioc.InitCoinDAO(v2, node, model, registry, logger)
+	cmdable := ioc.InitRedis(cfg)
+	reloader := ioc.InitRuntimeConfig(level, cfg, logger)
+	redisCoinCache := ioc.InitRedisCoinCache(cmdable, reloader, registry)
+	breakerCoinCache := ioc.InitCoinCache(redisCoinCache, cfg, logger)
+	cachedCoinRepository := repository.NewCachedCoinRepository(coinDAO, breakerCoinCache, logger)
+	coinAuditDAO := ioc.InitCoinAuditDAO(v2)
+	coinAuditRepository := repository.NewCoinAuditRepository(coinAuditDAO)
+	outboxDAO := ioc.InitOutboxDAO(v2)
+	outboxRepository := repository.NewOutboxRepository(outboxDAO)
+	transactor := dao.NewGormTransactor()
+	pokeRateReporter := ioc.InitPokeRateReporter(registry, cfg)
+	coinService := ioc.InitCoinService(cachedCoinRepository, coinAuditRepository, outboxRepository, transactor, pokeRateReporter)
+	coinHandler := ioc.InitCoinHandler(coinService, cfg, logger)
+	pokeRollupDAO := ioc.InitPokeRollupDAO(v2)
+	scoreHistoryRepository := repository.NewScoreHistoryRepository(pokeRollupDAO)
+	scoreHistoryService := service.NewScoreHistoryService(cachedCoinRepository, scoreHistoryRepository)
+	scoreHistoryHandler := web.NewScoreHistoryHandler(scoreHistoryService, logger)
+	policy := ioc.InitReadReplicas(db, cfg, logger)
+	v3 := ioc.InitHealthCheckers(v2, cmdable, breakerCoinCache, policy, cfg, logger)
+	healthHandler := ioc.InitHealthHandler(v3, cfg)
+	cacheWarmer := ioc.InitCacheWarmer(cachedCoinRepository, cfg, logger)
+	adminHandler := ioc.InitAdminHandler(cacheWarmer, level, cfg, logger)
+	engine := ioc.InitWebServer(v, coinHandler, scoreHistoryHandler, healthHandler, adminHandler)
+	certReloader := ioc.InitCertReloader(cfg)
+	server := ioc.InitMetricsServer(registry, cfg)
+	tracerProvider := ioc.InitTracerProvider(cfg)
+	scoreCompactor := ioc.InitScoreCompactor(scoreHistoryRepository, cfg, logger)
+	hotScoreRefresher := ioc.InitHotScoreRefresher(cachedCoinRepository, cfg, logger)
+	v4 := ioc.InitJobs(cacheWarmer, policy, scoreCompactor, hotScoreRefresher, pokeRateReporter, cfg)
 	app := &App{
-		server: engine,
+		cfg:            cfg,
+		server:         engine,
+		certs:          certReloader,
+		health:         healthHandler,
+		metricsServer:  server,
+		tracerProvider: tracerProvider,
+		jobs:           v4,
 	}
 	return app
 }
 
+// InitCLI builds the services of the admin commands on the primary databases, without migrating them
+// or starting the background jobs.
+func InitCLI(l *zap.Logger, level zap.AtomicLevel, cfg *ioc.Config) *CLI {
+	logger := ioc.InitLogger(l)
+	db := ioc.OpenDB(cfg, logger)
+	v := ioc.OpenShards(db, cfg, logger)
+	node := ioc.InitCLIIdGenerator()
+	model := ioc.InitHotScoreModel(cfg)
+	registry := ioc.InitMetricsRegistry()
+	coinDAO := ioc.InitCoinDAO(v, node, model, registry, logger)
+	cmdable := ioc.InitRedis(cfg)
+	reloader := ioc.NewRuntimeConfig(level, cfg, logger)
+	redisCoinCache := ioc.InitRedisCoinCache(cmdable, reloader, registry)
+	breakerCoinCache := ioc.InitCoinCache(redisCoinCache, cfg, logger)
+	cachedCoinRepository := repository.NewCachedCoinRepository(coinDAO, breakerCoinCache, logger)
+	coinAuditDAO := ioc.InitCoinAuditDAO(v)
+	coinAuditRepository := repository.NewCoinAuditRepository(coinAuditDAO)
+	outboxDAO := ioc.InitOutboxDAO(v)
+	outboxRepository := repository.NewOutboxRepository(outboxDAO)
+	transactor := dao.NewGormTransactor()
+	pokeRateReporter := ioc.InitPokeRateReporter(registry, cfg)
+	coinService := ioc.InitCoinService(cachedCoinRepository, coinAuditRepository, outboxRepository, transactor, pokeRateReporter)
+	cacheWarmer := ioc.NewCacheWarmer(cachedCoinRepository, cfg, logger)
+	hotScoreRefresher := ioc.InitHotScoreRefresher(cachedCoinRepository, cfg, logger)
+	cli := &CLI{
+		coins:     coinService,
+		repo:      cachedCoinRepository,
+		cache:     redisCoinCache,
+		warmer:    cacheWarmer,
+		refresher: hotScoreRefresher,
+	}
+	return cli
+}
+
+func InitMigrators(l *zap.Logger, cfg *ioc.Config) []*migrator.Migrator {
+	logger := ioc.InitLogger(l)
+	db := ioc.OpenDB(cfg, logger)
+	v := ioc.OpenShards(db, cfg, logger)
+	v2 := ioc.InitMigrators(v, cfg, logger)
+	return v2
+}
+
 // wire.go:
 
-var thirdPartySet = wire.NewSet(ioc.InitLogger, ioc.InitDB, ioc.InitRedis)
+var thirdPartySet = wire.NewSet(ioc.InitLogger, ioc.InitDB, ioc.InitShards, ioc.InitReadReplicas, ioc.InitRedis, ioc.InitIdGenerator, wire.Bind(new(dao.IdGenerator), new(*snowflake.Node)), ioc.InitMetricsRegistry, ioc.InitTracerProvider, ioc.InitRuntimeConfig, wire.Bind(new(prometheus.Registerer), new(*prometheus.Registry)))